@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"task/internal/domain/account/entity"
+	"time"
+)
+
+const accountsUsage = `usage: task accounts get [flags] <id>... [config flags]
+       task accounts list [flags] [config flags]`
+
+// accountView is an account with, when looked up by id, the amount its
+// pending transactions would add to the balance.
+type accountView struct {
+	*entity.Account
+	Pending *float64 `json:"pending,omitempty"`
+}
+
+// accountsCommand runs `task accounts`.
+func accountsCommand(w io.Writer, args []string) error {
+	command, args, err := subcommand(args, accountsUsage)
+	if err != nil {
+		return err
+	}
+
+	var opts adminOptions
+	fs := newFlagSet(accountsUsage, &opts, false)
+
+	positional, config, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	var ids []uint64
+	switch {
+	case command == "get" && len(positional) > 0:
+		if ids, err = parseIDs(positional); err != nil {
+			return err
+		}
+	case command == "list" && len(positional) == 0:
+	default:
+		return usageError(accountsUsage)
+	}
+
+	if err := checkOutput(opts.output, outputTable, outputJSON); err != nil {
+		return err
+	}
+
+	a, err := connect(w, opts, config)
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	ctx := context.Background()
+
+	var views []accountView
+
+	if command == "list" {
+		accounts, err := a.accounts.ListAccounts(ctx)
+		if err != nil {
+			return err
+		}
+		for _, account := range accounts {
+			views = append(views, accountView{Account: account})
+		}
+		return printAccounts(w, a.output, views)
+	}
+
+	for _, id := range ids {
+		account, err := a.accounts.GetAccount(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		frozen, err := a.transactions.GetFrozenBalanceByAccountID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		views = append(views, accountView{Account: account, Pending: &frozen.Balance})
+	}
+
+	return printAccounts(w, a.output, views)
+}
+
+func printAccounts(w io.Writer, output string, views []accountView) error {
+	return render(w, output, orEmpty(views), func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tCURRENCY\tBALANCE\tPENDING\tEMAIL\tVERIFIED AT")
+		for _, view := range views {
+			pending := "-"
+			if view.Pending != nil {
+				pending = strconv.FormatFloat(*view.Pending, 'f', 2, 64)
+			}
+			verified := "-"
+			if view.EmailVerifiedAt != nil {
+				verified = view.EmailVerifiedAt.UTC().Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%.2f\t%s\t%s\t%s\n", view.ID, view.Currency, view.Balance, pending, view.Email, verified)
+		}
+	})
+}
+
+func parseIDs(args []string) ([]uint64, error) {
+	ids := make([]uint64, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil || id == 0 {
+			return nil, usageError(fmt.Sprintf("invalid id %q", arg))
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
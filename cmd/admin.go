@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"task/common"
+	account "task/internal/domain/account/entity"
+	accService "task/internal/domain/account/service"
+	"task/internal/domain/account_dto/dto"
+	authService "task/internal/domain/auth/service"
+	"task/internal/domain/mail/mailer"
+	"task/internal/domain/transaction/entity"
+	transService "task/internal/domain/transaction/service"
+	"text/tabwriter"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+)
+
+// admin is what the operator commands share. They go through the service
+// layer, so that settling or reversing from the command line books and
+// announces the change the way the API does.
+type admin struct {
+	w            io.Writer
+	output       string
+	dryRun       bool
+	transactor   dryRunner
+	accounts     accountService
+	transactions transactionService
+	close        func()
+}
+
+// The parts of the transactor and the services the commands use.
+type dryRunner interface {
+	DryRun(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type accountService interface {
+	GetAccount(ctx context.Context, id uint64) (*account.Account, error)
+	ListAccounts(ctx context.Context) ([]*account.Account, error)
+}
+
+type transactionService interface {
+	GetTransactionByID(ctx context.Context, id uint64) (*entity.Transaction, error)
+	GetFrozenBalanceByAccountID(ctx context.Context, accountID uint64) (*dto.RegistrationCommand, error)
+	ListTransactions(ctx context.Context, status string, accountID uint64) ([]*entity.Transaction, error)
+	UpdateTransactionStatus(ctx context.Context, id uint64) error
+	ReverseTransaction(ctx context.Context, id uint64) (*entity.Transaction, error)
+	Reconcile(ctx context.Context) ([]*entity.Discrepancy, error)
+}
+
+// connect opens the services of the operator commands; tests replace it.
+var connect = newAdmin
+
+// adminOptions are the flags every operator command accepts; dryRun only
+// for those that change something.
+type adminOptions struct {
+	output string
+	dryRun bool
+}
+
+func newFlagSet(usage string, opts *adminOptions, mutating bool) *flag.FlagSet {
+	fs := flag.NewFlagSet(usage, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+
+	fs.StringVar(&opts.output, "o", outputTable, "output format, table or json")
+	if mutating {
+		fs.BoolVar(&opts.dryRun, "dry-run", false, "run the change in a transaction that is rolled back")
+	}
+
+	return fs
+}
+
+// subcommand splits the subcommand off args.
+func subcommand(args []string, usage string) (string, []string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return "", nil, usageError(usage)
+	}
+
+	return args[0], args[1:], nil
+}
+
+// parseArgs parses the flags of a command, then takes its arguments up to
+// the next flag. The flags after them, or from the first flag the command
+// does not define, configure the application.
+func parseArgs(fs *flag.FlagSet, args []string) (positional []string, config []string, err error) {
+	own := args
+	for i, arg := range args {
+		if name, ok := flagName(arg); ok && fs.Lookup(name) == nil && name != "h" && name != "help" {
+			own, config = args[:i], args[i:]
+			break
+		}
+	}
+
+	if err := fs.Parse(own); err != nil {
+		return nil, nil, err
+	}
+
+	rest := fs.Args()
+
+	i := 0
+	for i < len(rest) && !strings.HasPrefix(rest[i], "-") {
+		i++
+	}
+
+	return rest[:i], append(rest[i:], config...), nil
+}
+
+// flagName is the name of the flag arg sets, if it is one.
+func flagName(arg string) (string, bool) {
+	if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(strings.TrimPrefix(arg[1:], "-"), "=")
+
+	return name, name != ""
+}
+
+func newAdmin(w io.Writer, opts adminOptions, config []string) (*admin, error) {
+	cfg, err := common.Load(config)
+	if err != nil {
+		return nil, err
+	}
+
+	di, err := common.NewDIContainer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create dependency injection container: %w", err)
+	}
+
+	mail, err := mailer.New(cfg.Mail)
+	if err != nil {
+		di.Pool.Close()
+		return nil, fmt.Errorf("cannot create mailer: %w", err)
+	}
+
+	authenticationService := authService.NewService(di, mail)
+
+	return &admin{
+		w:            w,
+		output:       opts.output,
+		dryRun:       opts.dryRun,
+		transactor:   common.NewTransactor(di.Pool),
+		accounts:     accService.NewService(di, authenticationService),
+		transactions: transService.NewService(di, authenticationService),
+		close: func() {
+			mail.Close() //nolint:errcheck
+			di.Pool.Close()
+		},
+	}, nil
+}
+
+// mutate runs fn, or with -dry-run runs it in a transaction that is rolled
+// back, so that what fn reads afterwards shows the change that would be made.
+func (a *admin) mutate(ctx context.Context, fn func(ctx context.Context) error) error {
+	if a.dryRun {
+		return a.transactor.DryRun(ctx, fn)
+	}
+
+	return fn(ctx)
+}
+
+func checkOutput(output string, formats ...string) error {
+	for _, format := range formats {
+		if output == format {
+			return nil
+		}
+	}
+
+	return usageError(fmt.Sprintf("unknown output format %q, want %s", output, strings.Join(formats, " or ")))
+}
+
+// render writes v as indented JSON, or the table written by table.
+func render(w io.Writer, output string, v any, table func(w io.Writer)) error {
+	if output == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	table(tw)
+
+	return tw.Flush()
+}
+
+// orEmpty keeps empty results a JSON array rather than null.
+func orEmpty[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+
+	return s
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"task/internal/api/response"
+	account "task/internal/domain/account/entity"
+	"task/internal/domain/account_dto/dto"
+	"task/internal/domain/transaction/entity"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore stands in for the services and the transactor of the operator
+// commands. Settling moves the amount between the balances, and DryRun
+// puts everything back the way a rolled back transaction would.
+type fakeStore struct {
+	accounts     map[uint64]*account.Account
+	transactions map[uint64]*entity.Transaction
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		accounts: map[uint64]*account.Account{
+			1: {ID: 1, Currency: "USD", Balance: 100, Email: "one@example.com"},
+			2: {ID: 2, Currency: "USD", Balance: 0, Email: "two@example.com"},
+		},
+		transactions: map[uint64]*entity.Transaction{
+			1: {ID: 1, Status: response.StatusCreated, AccountID: 1, Amount: 50, Currency: "USD"},
+			2: {ID: 2, Status: response.StatusCreated, AccountID: 1, Amount: 70, Currency: "USD", ToAccount: 2},
+			3: {ID: 3, Status: response.StatusSuccess, AccountID: 2, Amount: 10, Currency: "USD"},
+			4: {ID: 4, Status: response.StatusCreated, AccountID: 2, Amount: 500, Currency: "USD", ToAccount: 1},
+		},
+	}
+}
+
+// use makes the commands connect to s.
+func (s *fakeStore) use(t *testing.T) {
+	t.Helper()
+
+	connect = func(w io.Writer, opts adminOptions, config []string) (*admin, error) {
+		return &admin{
+			w:            w,
+			output:       opts.output,
+			dryRun:       opts.dryRun,
+			transactor:   s,
+			accounts:     s,
+			transactions: s,
+			close:        func() {},
+		}, nil
+	}
+	t.Cleanup(func() { connect = newAdmin })
+}
+
+func (s *fakeStore) DryRun(ctx context.Context, fn func(ctx context.Context) error) error {
+	accounts := make(map[uint64]*account.Account, len(s.accounts))
+	for id, a := range s.accounts {
+		copied := *a
+		accounts[id] = &copied
+	}
+	transactions := make(map[uint64]*entity.Transaction, len(s.transactions))
+	for id, t := range s.transactions {
+		copied := *t
+		transactions[id] = &copied
+	}
+	defer func() {
+		s.accounts, s.transactions = accounts, transactions
+	}()
+
+	return fn(ctx)
+}
+
+func (s *fakeStore) GetAccount(_ context.Context, id uint64) (*account.Account, error) {
+	a, ok := s.accounts[id]
+	if !ok {
+		return nil, errors.New("account not found")
+	}
+
+	return a, nil
+}
+
+func (s *fakeStore) ListAccounts(context.Context) ([]*account.Account, error) {
+	var accounts []*account.Account
+	for id := uint64(1); id <= uint64(len(s.accounts)); id++ {
+		accounts = append(accounts, s.accounts[id])
+	}
+
+	return accounts, nil
+}
+
+func (s *fakeStore) GetTransactionByID(_ context.Context, id uint64) (*entity.Transaction, error) {
+	t, ok := s.transactions[id]
+	if !ok {
+		return nil, errors.New("transaction not found")
+	}
+
+	return t, nil
+}
+
+func (s *fakeStore) GetFrozenBalanceByAccountID(_ context.Context, accountID uint64) (*dto.RegistrationCommand, error) {
+	frozen := &dto.RegistrationCommand{ID: accountID}
+	for _, t := range s.transactions {
+		if t.AccountID == accountID && t.Status == response.StatusCreated {
+			frozen.Balance += t.Amount
+		}
+	}
+
+	return frozen, nil
+}
+
+func (s *fakeStore) ListTransactions(_ context.Context, status string, accountID uint64) ([]*entity.Transaction, error) {
+	var transactions []*entity.Transaction
+	for id := uint64(1); id <= uint64(len(s.transactions)); id++ {
+		t := s.transactions[id]
+		if (status == "" || t.Status == status) && (accountID == 0 || t.AccountID == accountID) {
+			transactions = append(transactions, t)
+		}
+	}
+
+	return transactions, nil
+}
+
+func (s *fakeStore) UpdateTransactionStatus(ctx context.Context, id uint64) error {
+	t, err := s.GetTransactionByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if t.Status != response.StatusCreated {
+		return errors.New("transaction is not pending")
+	}
+
+	from := s.accounts[t.AccountID]
+	if t.Kind() == entity.KindDeposit {
+		from.Balance += t.Amount
+		t.Status = response.StatusSuccess
+		return nil
+	}
+
+	if from.Balance < t.Amount {
+		t.Status = response.StatusError
+		return errors.New("insufficient funds")
+	}
+	from.Balance -= t.Amount
+	s.accounts[t.ToAccount].Balance += t.Amount
+	t.Status = response.StatusSuccess
+
+	return nil
+}
+
+func (s *fakeStore) ReverseTransaction(ctx context.Context, id uint64) (*entity.Transaction, error) {
+	t, err := s.GetTransactionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.Status != response.StatusSuccess {
+		return nil, errors.New("transaction is not settled")
+	}
+
+	s.accounts[t.AccountID].Balance -= t.Amount
+	t.Status = response.StatusReversed
+
+	return t, nil
+}
+
+func (s *fakeStore) Reconcile(context.Context) ([]*entity.Discrepancy, error) {
+	return nil, nil
+}
+
+func TestCommands_RejectUsage(t *testing.T) {
+	connect = func(io.Writer, adminOptions, []string) (*admin, error) {
+		return nil, errors.New("connected despite the usage error")
+	}
+	t.Cleanup(func() { connect = newAdmin })
+
+	cases := []struct {
+		name    string
+		command func(w io.Writer, args []string) error
+		args    []string
+	}{
+		{name: "Accounts without subcommand", command: accountsCommand},
+		{name: "Accounts flag for subcommand", command: accountsCommand, args: []string{"-o", "json"}},
+		{name: "Accounts unknown subcommand", command: accountsCommand, args: []string{"delete", "1"}},
+		{name: "Accounts get without id", command: accountsCommand, args: []string{"get"}},
+		{name: "Accounts get zero id", command: accountsCommand, args: []string{"get", "0"}},
+		{name: "Accounts get bad id", command: accountsCommand, args: []string{"get", "-o", "json", "1", "x"}},
+		{name: "Accounts list with id", command: accountsCommand, args: []string{"list", "1"}},
+		{name: "Accounts csv output", command: accountsCommand, args: []string{"list", "-o", "csv"}},
+		{name: "Transactions unknown subcommand", command: transactionsCommand, args: []string{"delete", "1"}},
+		{name: "Transactions list with id", command: transactionsCommand, args: []string{"list", "1"}},
+		{name: "Transactions settle nothing", command: transactionsCommand, args: []string{"settle"}},
+		{name: "Transactions settle all and ids", command: transactionsCommand, args: []string{"settle", "-all", "1"}},
+		{name: "Transactions reverse all", command: transactionsCommand, args: []string{"reverse", "-all"}},
+		{name: "Transactions reverse bad id", command: transactionsCommand, args: []string{"reverse", "1", "two"}},
+		{name: "Transactions yaml output", command: transactionsCommand, args: []string{"pending", "-o", "yaml"}},
+		{name: "Reconcile with argument", command: reconcileCommand, args: []string{"now"}},
+		{name: "Export nothing", command: exportCommand},
+		{name: "Export unknown", command: exportCommand, args: []string{"users"}},
+		{name: "Export with argument", command: exportCommand, args: []string{"accounts", "all"}},
+		{name: "Export table output", command: exportCommand, args: []string{"transactions", "-o", "table"}},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.command(io.Discard, tc.args)
+
+			var usage usageError
+			require.ErrorAs(t, err, &usage)
+		})
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	cases := []struct {
+		name       string
+		args       []string
+		positional []string
+		config     []string
+		output     string
+	}{
+		{
+			name:       "Nothing",
+			args:       []string{},
+			positional: []string{},
+			config:     []string{},
+			output:     outputTable,
+		},
+		{
+			name:       "Flags then ids",
+			args:       []string{"-o", "json", "1", "2"},
+			positional: []string{"1", "2"},
+			config:     []string{},
+			output:     outputJSON,
+		},
+		{
+			name:       "Config flags after the ids",
+			args:       []string{"1", "-database.host", "db", "3"},
+			positional: []string{"1"},
+			config:     []string{"-database.host", "db", "3"},
+			output:     outputTable,
+		},
+		{
+			name:       "Config flags only",
+			args:       []string{"-o", "json", "-database.host", "db"},
+			positional: []string{},
+			config:     []string{"-database.host", "db"},
+			output:     outputJSON,
+		},
+		{
+			name:       "Config flag with its value",
+			args:       []string{"--dry-run", "--database.port=5433", "-o", "json"},
+			positional: []string{},
+			config:     []string{"--database.port=5433", "-o", "json"},
+			output:     outputTable,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var opts adminOptions
+			fs := newFlagSet("usage", &opts, true)
+			fs.SetOutput(io.Discard)
+
+			positional, config, err := parseArgs(fs, tc.args)
+			require.NoError(t, err)
+			require.Equal(t, tc.positional, positional)
+			require.Equal(t, tc.config, config)
+			require.Equal(t, tc.output, opts.output)
+		})
+	}
+}
+
+func TestParseIDs(t *testing.T) {
+	ids, err := parseIDs([]string{"1", "18446744073709551615"})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 18446744073709551615}, ids)
+
+	for _, arg := range []string{"0", "-1", "1.5", "", "18446744073709551616"} {
+		_, err := parseIDs([]string{arg})
+		require.Equal(t, usageError(fmt.Sprintf("invalid id %q", arg)), err, arg)
+	}
+}
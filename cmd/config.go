@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"task/common"
+)
+
+// configCommand runs `task config check`, which loads the configuration
+// the way serve does and prints the result with secrets redacted, or every
+// problem found.
+func configCommand(w io.Writer, args []string) error {
+	if len(args) == 0 || args[0] != "check" {
+		return usageError("usage: task config check [flags]")
+	}
+
+	cfg, err := common.Load(args[1:])
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w, "# configuration is valid"); err != nil {
+		return err
+	}
+
+	return cfg.Dump(w)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"task/internal/domain/account/entity"
+	transaction "task/internal/domain/transaction/entity"
+	"time"
+)
+
+const exportUsage = "usage: task export accounts|transactions [flags] [config flags]"
+
+// exportCommand runs `task export`, which writes every account or
+// transaction as CSV or JSON, password hashes excluded.
+func exportCommand(w io.Writer, args []string) error {
+	what, args, err := subcommand(args, exportUsage)
+	if err != nil {
+		return err
+	}
+	if what != "accounts" && what != "transactions" {
+		return usageError(exportUsage)
+	}
+
+	var (
+		opts      adminOptions
+		status    string
+		accountID uint64
+	)
+
+	fs := flag.NewFlagSet(exportUsage, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), exportUsage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&opts.output, "o", outputCSV, "output format, csv or json")
+	if what == "transactions" {
+		fs.StringVar(&status, "status", "", "export only transactions with this status")
+		fs.Uint64Var(&accountID, "account", 0, "export only transactions of this account")
+	}
+
+	positional, config, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usageError(exportUsage)
+	}
+
+	if err := checkOutput(opts.output, outputCSV, outputJSON); err != nil {
+		return err
+	}
+
+	a, err := connect(w, opts, config)
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	ctx := context.Background()
+
+	if what == "accounts" {
+		accounts, err := a.accounts.ListAccounts(ctx)
+		if err != nil {
+			return err
+		}
+		if a.output == outputJSON {
+			return render(w, outputJSON, orEmpty(accounts), nil)
+		}
+		return exportAccounts(w, accounts)
+	}
+
+	transactions, err := a.transactions.ListTransactions(ctx, status, accountID)
+	if err != nil {
+		return err
+	}
+	if a.output == outputJSON {
+		return render(w, outputJSON, orEmpty(transactions), nil)
+	}
+
+	return exportTransactions(w, transactions)
+}
+
+func exportAccounts(w io.Writer, accounts []*entity.Account) error {
+	cw := csv.NewWriter(w)
+
+	cw.Write([]string{"id", "currency", "balance", "email", "email_verified_at"}) //nolint:errcheck
+	for _, account := range accounts {
+		verified := ""
+		if account.EmailVerifiedAt != nil {
+			verified = account.EmailVerifiedAt.UTC().Format(time.RFC3339)
+		}
+		cw.Write([]string{ //nolint:errcheck
+			strconv.FormatUint(account.ID, 10),
+			account.Currency,
+			strconv.FormatFloat(account.Balance, 'f', -1, 64),
+			account.Email,
+			verified,
+		})
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func exportTransactions(w io.Writer, transactions []*transaction.Transaction) error {
+	cw := csv.NewWriter(w)
+
+	cw.Write([]string{"id", "status", "account_id", "amount", "currency", "to_account"}) //nolint:errcheck
+	for _, t := range transactions {
+		cw.Write([]string{ //nolint:errcheck
+			strconv.FormatUint(t.ID, 10),
+			t.Status,
+			strconv.FormatUint(t.AccountID, 10),
+			strconv.FormatFloat(t.Amount, 'f', -1, 64),
+			t.Currency,
+			strconv.FormatUint(t.ToAccount, 10),
+		})
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCommand(t *testing.T) {
+	verified := time.Date(2024, 3, 1, 12, 30, 0, 0, time.FixedZone("CET", 3600))
+
+	cases := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "Accounts",
+			args: []string{"accounts"},
+			want: "id,currency,balance,email,email_verified_at\n" +
+				"1,USD,100.25,one@example.com,2024-03-01T11:30:00Z\n" +
+				"2,USD,0,\"two, the second@example.com\",\n",
+		},
+		{
+			name: "Transactions",
+			args: []string{"transactions"},
+			want: "id,status,account_id,amount,currency,to_account\n" +
+				"1,created,1,50,USD,0\n" +
+				"2,created,1,70,USD,2\n" +
+				"3,success,2,10,USD,0\n" +
+				"4,created,2,500,USD,1\n",
+		},
+		{
+			name: "Transactions of an account",
+			args: []string{"transactions", "-status", "created", "-account", "2"},
+			want: "id,status,account_id,amount,currency,to_account\n" +
+				"4,created,2,500,USD,1\n",
+		},
+		{
+			name: "No transactions",
+			args: []string{"transactions", "-account", "3"},
+			want: "id,status,account_id,amount,currency,to_account\n",
+		},
+		{
+			name: "No transactions as JSON",
+			args: []string{"transactions", "-account", "3", "-o", "json"},
+			want: "[]\n",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			store := newFakeStore()
+			store.accounts[1].Balance = 100.25
+			store.accounts[1].EmailVerifiedAt = &verified
+			store.accounts[2].Email = "two, the second@example.com"
+			store.use(t)
+
+			var out bytes.Buffer
+			require.NoError(t, exportCommand(&out, tc.args))
+			require.Equal(t, tc.want, out.String())
+		})
+	}
+}
@@ -2,61 +2,254 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"golang.org/x/exp/slog"
+	"net"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 	"task/common"
+	"task/internal/api/ratelimit"
+	"task/internal/api/rpc"
 	"task/internal/api/server"
+	accService "task/internal/domain/account/service"
+	authService "task/internal/domain/auth/service"
+	"task/internal/domain/mail/mailer"
+	"task/internal/domain/outbox/publisher"
+	outbox "task/internal/domain/outbox/service"
+	stream "task/internal/domain/stream/service"
+	transService "task/internal/domain/transaction/service"
+	webhook "task/internal/domain/webhook/service"
+	"task/internal/health"
+	"task/internal/lifecycle"
+	"task/internal/metrics"
+	"task/internal/migrate"
+	"task/migrations"
+	"time"
 )
 
+const usage = `usage: task [command] [flags]
+
+commands:
+  serve           run the servers and workers (default)
+  config check    validate the configuration and print it, secrets redacted
+  migrate up      apply the pending migrations
+  migrate down [n]
+                  revert the latest n migrations, 1 by default
+  migrate status  list the migrations and whether they are applied
+  migrate seed    insert the sample data for local development
+  accounts get <id>...
+                  show accounts with the amount pending on them
+  accounts list   list every account
+  transactions list|pending
+                  list transactions, or those waiting to be settled
+  transactions settle -all | <id>...
+                  settle pending transactions
+  transactions reverse <id>...
+                  undo settled transactions
+  reconcile       report balances and transactions that disagree
+  export accounts|transactions
+                  write every account or transaction as csv or json
+
+Commands that change something accept -dry-run, which makes the change in
+a database transaction and rolls it back.
+
+Run a command with -h for its flags.
+`
+
 func main() {
 
 	logger := common.NewLogger()
 
-	di, err := common.NewDIContainer()
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	var err error
+	switch command {
+	case "serve":
+		err = serve(logger, args)
+	case "config":
+		err = configCommand(os.Stdout, args)
+	case "migrate":
+		err = migrateCommand(os.Stdout, args)
+	case "accounts":
+		err = accountsCommand(os.Stdout, args)
+	case "transactions":
+		err = transactionsCommand(os.Stdout, args)
+	case "reconcile":
+		err = reconcileCommand(os.Stdout, args)
+	case "export":
+		err = exportCommand(os.Stdout, args)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var (
+		invalid *common.ValidationError
+		misused usageError
+	)
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(2)
+	case errors.As(err, &misused):
+		fmt.Fprintln(os.Stderr, misused.Error())
+		os.Exit(2)
+	case errors.As(err, &invalid):
+		// Printed as is, since a list of problems is hard to read in a
+		// log record.
+		fmt.Fprintln(os.Stderr, invalid.Error())
+		os.Exit(1)
+	case err != nil:
+		logger.Error("command failed", slog.String("command", command), slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+}
+
+// usageError is a command line that does not parse, printed as is.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+func serve(logger *slog.Logger, args []string) error {
+	ctx := common.WithContext(context.Background(), logger)
+
+	cfg, err := common.Load(args)
+	if err != nil {
+		return err
+	}
+
+	di, err := common.NewDIContainer(cfg)
+	if err != nil {
+		return fmt.Errorf("cannot create dependency injection container: %w", err)
+	}
+
+	app := lifecycle.New(logger, di.Config.ContextTimeout)
+
+	// The pool is registered first so that it is closed last.
+	app.Close("database pool", func(context.Context) error {
+		di.Pool.Close()
+		return nil
+	})
+
+	migrator, err := migrate.New(di.Pool, migrations.Schema)
+	if err != nil {
+		return err
+	}
+
+	if cfg.AutoMigrate {
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return fmt.Errorf("cannot migrate: %w", err)
+		}
+		for _, migration := range applied {
+			logger.Info("migration applied", slog.Int64("version", migration.Version), slog.String("name", migration.Name))
+		}
+	}
+
+	tracerProvider, err := common.NewTracerProvider(ctx, di.Config.Tracing)
 	if err != nil {
-		logger.Error("cannot create dependency injection container", slog.String("error", err.Error()))
-		return
+		return fmt.Errorf("cannot create tracer provider: %w", err)
 	}
+	app.Close("tracer provider", tracerProvider.Shutdown)
+
+	if err := metrics.RegisterPool(di.Pool); err != nil {
+		return fmt.Errorf("cannot register pool metrics: %w", err)
+	}
+
+	broadcaster := stream.NewBroadcaster()
+
+	mail, err := mailer.New(di.Config.Mail)
+	if err != nil {
+		return fmt.Errorf("cannot create mailer: %w", err)
+	}
+	app.Close("mailer", func(context.Context) error { return mail.Close() })
+
+	limiter, err := ratelimit.New(di.Config.RateLimit)
+	if err != nil {
+		return fmt.Errorf("cannot create rate limit store: %w", err)
+	}
+	app.Close("rate limit store", func(context.Context) error { return limiter.Close() })
+
+	authenticationService := authService.NewService(di, mail)
+	accountService := accService.NewService(di, authenticationService)
+	transactionService := transService.NewService(di, authenticationService)
 
-	apiServer := server.NewServer(di)
+	apiServer := server.NewServer(di, accountService, transactionService, authenticationService, broadcaster, limiter)
 
 	handler, err := apiServer.GetHTTPHandler(logger)
 	if err != nil {
-		logger.Error("cannot create http handler", slog.String("error", err.Error()))
+		return fmt.Errorf("cannot create http handler: %w", err)
 	}
 
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	broker, err := publisher.New(di.Config.Outbox)
+	if err != nil {
+		return fmt.Errorf("cannot create outbox publisher: %w", err)
+	}
+
+	dispatcher := webhook.NewDispatcher(di)
+	pub := publisher.NewMulti(dispatcher, broker)
+	app.Close("outbox publisher", func(context.Context) error { return pub.Close() })
+
+	workers := health.NewWorkers()
+	app.Go("outbox relay", workers.Track("outbox_relay", outbox.NewRelay(di, pub).Run))
+	app.Go("webhook dispatcher", workers.Track("webhook_dispatcher", dispatcher.Run))
+	app.Go("stream listener", workers.Track("stream_listener", stream.NewListener(di, broadcaster).Run))
+
+	probes := health.New(di.Config.AdminServer.CheckTimeout)
+	probes.Register("database", health.Ping(di.Pool))
+	probes.Register("schema", migrator.Check)
+	probes.Register("workers", workers.Check)
+
+	// Readiness fails first, so that traffic moves away before the
+	// servers stop accepting it.
+	app.OnShutdown(func(context.Context) {
+		probes.Drain()
+		time.Sleep(di.Config.DrainDelay)
+	})
 
-	srv := http.Server{
+	lis, err := net.Listen("tcp", di.Config.GRPCServer.Address)
+	if err != nil {
+		return fmt.Errorf("cannot listen for grpc: %w", err)
+	}
+
+	httpServer := &http.Server{
 		Addr:              di.Config.Address,
 		Handler:           handler,
 		ReadHeaderTimeout: di.Config.ReadHeaderTimeout,
 		WriteTimeout:      di.Config.Timeout,
 		IdleTimeout:       di.Config.IdleTimeout,
 	}
+	// Event streams only end when the broadcaster closes, so it closes as
+	// soon as the server starts shutting down rather than after it stopped.
+	httpServer.RegisterOnShutdown(func() { broadcaster.Close() }) //nolint:errcheck
+	app.HTTP("http server", httpServer)
 
-	logger.Info("Server is running", slog.String("address", di.Config.Address))
-
-	if err := srv.ListenAndServe(); err != nil {
-		logger.Error("server stopped", slog.String("error", err.Error()))
-	}
+	app.HTTP("admin server", &http.Server{
+		Addr:              di.Config.AdminServer.Address,
+		Handler:           server.GetAdminHandler(probes),
+		ReadHeaderTimeout: di.Config.ReadHeaderTimeout,
+	})
 
-	<-done
-	logger.Info("stopping server")
+	app.GRPC("grpc server", rpc.NewServer(logger, accountService, transactionService, authenticationService, limiter, di.Config.RateLimit), lis)
 
-	ctx, cancel := context.WithTimeout(context.Background(), di.Config.ContextTimeout)
-	defer cancel()
+	logger.Info("application is running",
+		slog.String("address", di.Config.Address),
+		slog.String("admin_address", di.Config.AdminServer.Address),
+		slog.String("grpc_address", di.Config.GRPCServer.Address),
+	)
 
-	if err := srv.Shutdown(ctx); err != nil {
-		logger.Error("failed to stop server", err)
-		return
+	if err := app.Run(ctx); err != nil {
+		return err
 	}
 
-	di.Pool.Close()
+	logger.Info("application stopped")
 
-	logger.Info("server stopped")
+	return nil
 }
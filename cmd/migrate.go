@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"task/common"
+	"task/internal/migrate"
+	"task/migrations"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = "usage: task migrate up|down [n]|status|seed [flags]"
+
+// migrateCommand runs `task migrate`. The configuration flags follow the
+// subcommand and, for down, the number of migrations to revert.
+func migrateCommand(w io.Writer, args []string) error {
+	if len(args) == 0 {
+		return usageError(migrateUsage)
+	}
+
+	command, args := args[0], args[1:]
+
+	steps := 1
+	if command == "down" && len(args) > 0 {
+		if n, err := strconv.Atoi(args[0]); err == nil {
+			if n < 1 {
+				return usageError(migrateUsage)
+			}
+			steps, args = n, args[1:]
+		}
+	}
+
+	cfg, err := common.Load(args)
+	if err != nil {
+		return err
+	}
+
+	pool, err := common.NewConnectionDB(cfg.URL())
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+
+	migrator, err := migrate.New(pool, migrations.Schema)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Fprintf(w, "applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintf(w, "schema is up to date at version %d\n", migrator.Latest())
+		}
+		return err
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Fprintf(w, "reverted %d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return printStatus(w, statuses)
+	case "seed":
+		if err := migrator.Check(ctx); err != nil {
+			return fmt.Errorf("migrate the schema before seeding: %w", err)
+		}
+		seeded, err := migrate.Seed(ctx, pool, migrations.Seed)
+		for _, name := range seeded {
+			fmt.Fprintf(w, "seeded %s\n", name)
+		}
+		return err
+	default:
+		return usageError(migrateUsage)
+	}
+}
+
+func printStatus(w io.Writer, statuses []migrate.Status) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		applied := "pending"
+		if status.AppliedAt != nil {
+			applied = status.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%04d\t%s\t%s\n", status.Version, status.Name, applied)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"task/internal/api/response"
+	"task/internal/domain/transaction/entity"
+)
+
+const transactionsUsage = `usage: task transactions list [flags] [config flags]
+       task transactions pending [flags] [config flags]
+       task transactions settle [flags] -all | <id>... [config flags]
+       task transactions reverse [flags] <id>... [config flags]`
+
+const reconcileUsage = "usage: task reconcile [flags] [config flags]"
+
+// outcome is what a settlement or reversal did, or with -dry-run would do,
+// to one transaction.
+type outcome struct {
+	ID     uint64 `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	DryRun bool   `json:"dry_run,omitempty"`
+}
+
+// transactionsCommand runs `task transactions`. Settling and reversing
+// carry on past a transaction that fails, and report every outcome.
+func transactionsCommand(w io.Writer, args []string) error {
+	command, args, err := subcommand(args, transactionsUsage)
+	if err != nil {
+		return err
+	}
+
+	var (
+		opts      adminOptions
+		fs        *flag.FlagSet
+		status    string
+		accountID uint64
+		all       bool
+	)
+
+	switch command {
+	case "list":
+		fs = newFlagSet(transactionsUsage, &opts, false)
+		fs.StringVar(&status, "status", "", "list only transactions with this status")
+	case "pending":
+		fs = newFlagSet(transactionsUsage, &opts, false)
+		status = response.StatusCreated
+	case "settle":
+		fs = newFlagSet(transactionsUsage, &opts, true)
+		fs.BoolVar(&all, "all", false, "settle every pending transaction")
+	case "reverse":
+		fs = newFlagSet(transactionsUsage, &opts, true)
+	default:
+		return usageError(transactionsUsage)
+	}
+	if command != "reverse" {
+		fs.Uint64Var(&accountID, "account", 0, "only transactions of this account")
+	}
+
+	positional, config, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	var ids []uint64
+	switch command {
+	case "list", "pending":
+		if len(positional) > 0 {
+			return usageError(transactionsUsage)
+		}
+	case "settle", "reverse":
+		if all == (len(positional) > 0) {
+			return usageError(transactionsUsage)
+		}
+		if ids, err = parseIDs(positional); err != nil {
+			return err
+		}
+	}
+
+	if err := checkOutput(opts.output, outputTable, outputJSON); err != nil {
+		return err
+	}
+
+	a, err := connect(w, opts, config)
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	ctx := context.Background()
+
+	if command == "list" || command == "pending" {
+		transactions, err := a.transactions.ListTransactions(ctx, status, accountID)
+		if err != nil {
+			return err
+		}
+		return printTransactions(w, a.output, transactions)
+	}
+
+	if all {
+		pending, err := a.transactions.ListTransactions(ctx, response.StatusCreated, accountID)
+		if err != nil {
+			return err
+		}
+		for _, transaction := range pending {
+			ids = append(ids, transaction.ID)
+		}
+	}
+
+	outcomes := make([]outcome, 0, len(ids))
+	for _, id := range ids {
+		if command == "settle" {
+			outcomes = append(outcomes, a.settle(ctx, id, accountID))
+		} else {
+			outcomes = append(outcomes, a.reverse(ctx, id))
+		}
+	}
+
+	if err := printOutcomes(w, a.output, outcomes); err != nil {
+		return err
+	}
+
+	var failed int
+	for _, o := range outcomes {
+		if o.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d transactions could not be %sd", failed, len(outcomes), command)
+	}
+
+	return nil
+}
+
+// settle settles a pending transaction. A withdrawal the balance does not
+// cover fails the transaction, which the outcome shows.
+func (a *admin) settle(ctx context.Context, id uint64, accountID uint64) outcome {
+	o := outcome{ID: id, DryRun: a.dryRun}
+
+	err := a.mutate(ctx, func(ctx context.Context) error {
+		if accountID != 0 {
+			transaction, err := a.transactions.GetTransactionByID(ctx, id)
+			if err != nil {
+				return err
+			}
+			if transaction.AccountID != accountID {
+				return fmt.Errorf("transaction belongs to account %d", transaction.AccountID)
+			}
+		}
+
+		settleErr := a.transactions.UpdateTransactionStatus(ctx, id)
+
+		transaction, err := a.transactions.GetTransactionByID(ctx, id)
+		if err != nil {
+			return errors.Join(settleErr, err)
+		}
+		o.Status = transaction.Status
+
+		return settleErr
+	})
+	if err != nil {
+		o.Error = err.Error()
+	}
+
+	return o
+}
+
+func (a *admin) reverse(ctx context.Context, id uint64) outcome {
+	o := outcome{ID: id, DryRun: a.dryRun}
+
+	err := a.mutate(ctx, func(ctx context.Context) error {
+		transaction, err := a.transactions.ReverseTransaction(ctx, id)
+		if err != nil {
+			return err
+		}
+		o.Status = transaction.Status
+
+		return nil
+	})
+	if err != nil {
+		o.Error = err.Error()
+	}
+
+	return o
+}
+
+// reconcileCommand runs `task reconcile`, which fails when it finds any
+// discrepancy, so that it can alert from a scheduled job.
+func reconcileCommand(w io.Writer, args []string) error {
+	var opts adminOptions
+	fs := newFlagSet(reconcileUsage, &opts, false)
+
+	positional, config, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		return usageError(reconcileUsage)
+	}
+
+	if err := checkOutput(opts.output, outputTable, outputJSON); err != nil {
+		return err
+	}
+
+	a, err := connect(w, opts, config)
+	if err != nil {
+		return err
+	}
+	defer a.close()
+
+	discrepancies, err := a.transactions.Reconcile(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if err := printDiscrepancies(w, a.output, discrepancies); err != nil {
+		return err
+	}
+
+	if len(discrepancies) > 0 {
+		return fmt.Errorf("%d discrepancies found", len(discrepancies))
+	}
+
+	return nil
+}
+
+func printTransactions(w io.Writer, output string, transactions []*entity.Transaction) error {
+	return render(w, output, orEmpty(transactions), func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tSTATUS\tKIND\tACCOUNT\tTO ACCOUNT\tAMOUNT\tCURRENCY")
+		for _, t := range transactions {
+			to := "-"
+			if t.ToAccount > 0 {
+				to = fmt.Sprint(t.ToAccount)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%.2f\t%s\n", t.ID, t.Status, t.Kind(), t.AccountID, to, t.Amount, t.Currency)
+		}
+	})
+}
+
+func printOutcomes(w io.Writer, output string, outcomes []outcome) error {
+	return render(w, output, orEmpty(outcomes), func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tSTATUS\tERROR")
+		for _, o := range outcomes {
+			errText := "-"
+			if o.Error != "" {
+				errText = o.Error
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", o.ID, o.Status, errText)
+		}
+		if len(outcomes) > 0 && outcomes[0].DryRun {
+			fmt.Fprintln(w, "dry run, nothing was changed")
+		}
+	})
+}
+
+func printDiscrepancies(w io.Writer, output string, discrepancies []*entity.Discrepancy) error {
+	return render(w, output, orEmpty(discrepancies), func(w io.Writer) {
+		if len(discrepancies) == 0 {
+			fmt.Fprintln(w, "no discrepancies found")
+			return
+		}
+		fmt.Fprintln(w, "KIND\tACCOUNT\tTRANSACTION\tDETAIL")
+		for _, d := range discrepancies {
+			transaction := "-"
+			if d.TransactionID != 0 {
+				transaction = fmt.Sprint(d.TransactionID)
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", d.Kind, d.AccountID, transaction, d.Detail)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"task/internal/api/response"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionsCommand_SettleAndReverse(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     string
+		outcomes []outcome
+		err      string
+		balances map[uint64]float64
+		statuses map[uint64]string
+	}{
+		{
+			name:     "Deposit",
+			args:     "settle 1",
+			outcomes: []outcome{{ID: 1, Status: response.StatusSuccess}},
+			balances: map[uint64]float64{1: 150},
+			statuses: map[uint64]string{1: response.StatusSuccess},
+		},
+		{
+			name:     "Withdrawal",
+			args:     "settle 2",
+			outcomes: []outcome{{ID: 2, Status: response.StatusSuccess}},
+			balances: map[uint64]float64{1: 30, 2: 70},
+			statuses: map[uint64]string{2: response.StatusSuccess},
+		},
+		{
+			name:     "Uncovered withdrawal",
+			args:     "settle 4",
+			outcomes: []outcome{{ID: 4, Status: response.StatusError, Error: "insufficient funds"}},
+			err:      "1 of 1 transactions could not be settled",
+			statuses: map[uint64]string{4: response.StatusError},
+		},
+		{
+			name:     "Another account",
+			args:     "settle -account 2 1",
+			outcomes: []outcome{{ID: 1, Error: "transaction belongs to account 1"}},
+			err:      "1 of 1 transactions could not be settled",
+		},
+		{
+			name: "All of an account",
+			args: "settle -all -account 1",
+			outcomes: []outcome{
+				{ID: 1, Status: response.StatusSuccess},
+				{ID: 2, Status: response.StatusSuccess},
+			},
+			balances: map[uint64]float64{1: 80, 2: 70},
+			statuses: map[uint64]string{1: response.StatusSuccess, 2: response.StatusSuccess},
+		},
+		{
+			name: "Carries on past a failure",
+			args: "settle 3 1",
+			outcomes: []outcome{
+				{ID: 3, Status: response.StatusSuccess, Error: "transaction is not pending"},
+				{ID: 1, Status: response.StatusSuccess},
+			},
+			err:      "1 of 2 transactions could not be settled",
+			balances: map[uint64]float64{1: 150},
+			statuses: map[uint64]string{1: response.StatusSuccess},
+		},
+		{
+			name:     "Reversal",
+			args:     "reverse 3",
+			outcomes: []outcome{{ID: 3, Status: response.StatusReversed}},
+			balances: map[uint64]float64{2: -10},
+			statuses: map[uint64]string{3: response.StatusReversed},
+		},
+		{
+			name:     "Reversal of a pending transaction",
+			args:     "reverse 1",
+			outcomes: []outcome{{ID: 1, Error: "transaction is not settled"}},
+			err:      "1 of 1 transactions could not be reversed",
+		},
+		{
+			name:     "Dry run",
+			args:     "settle -dry-run 2",
+			outcomes: []outcome{{ID: 2, Status: response.StatusSuccess, DryRun: true}},
+		},
+		{
+			name:     "Dry run of an uncovered withdrawal",
+			args:     "settle -dry-run 4",
+			outcomes: []outcome{{ID: 4, Status: response.StatusError, Error: "insufficient funds", DryRun: true}},
+			err:      "1 of 1 transactions could not be settled",
+		},
+		{
+			name: "Dry run of all",
+			args: "settle -dry-run -all",
+			outcomes: []outcome{
+				{ID: 1, Status: response.StatusSuccess, DryRun: true},
+				{ID: 2, Status: response.StatusSuccess, DryRun: true},
+				{ID: 4, Status: response.StatusError, Error: "insufficient funds", DryRun: true},
+			},
+			err: "1 of 3 transactions could not be settled",
+		},
+		{
+			name:     "Dry run of a reversal",
+			args:     "reverse -dry-run 3",
+			outcomes: []outcome{{ID: 3, Status: response.StatusReversed, DryRun: true}},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			store := newFakeStore()
+			store.use(t)
+
+			args := strings.Fields(tc.args)
+			args = append(args[:1], append([]string{"-o", "json"}, args[1:]...)...)
+
+			var out bytes.Buffer
+			err := transactionsCommand(&out, args)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			var outcomes []outcome
+			require.NoError(t, json.Unmarshal(out.Bytes(), &outcomes))
+			require.Equal(t, tc.outcomes, outcomes)
+
+			want := newFakeStore()
+			for id, balance := range tc.balances {
+				want.accounts[id].Balance = balance
+			}
+			for id, status := range tc.statuses {
+				want.transactions[id].Status = status
+			}
+			require.Equal(t, want, store)
+		})
+	}
+}
+
+func TestTransactionsCommand_DryRunTable(t *testing.T) {
+	newFakeStore().use(t)
+
+	var out bytes.Buffer
+	require.NoError(t, transactionsCommand(&out, []string{"settle", "-dry-run", "1"}))
+
+	require.Equal(t, "ID  STATUS   ERROR\n1   success  -\ndry run, nothing was changed\n", out.String())
+}
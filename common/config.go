@@ -2,9 +2,6 @@ package common
 
 import (
 	"fmt"
-	"github.com/ilyakaznacheev/cleanenv"
-	"log"
-	"os"
 	"time"
 )
 
@@ -13,25 +10,156 @@ type Config struct {
 	StorageConfig `yaml:"storage"`
 	//StoragePath string `yaml:"storage_path" env-required:"true"`
 	HTTPServer     `yaml:"http_server"`
-	ContextTimeout time.Duration `yaml:"context_timeout" env-default:"5s"`
+	GRPCServer     GRPCServer      `yaml:"grpc_server"`
+	AdminServer    AdminServer     `yaml:"admin_server"`
+	ContextTimeout time.Duration   `yaml:"context_timeout" env-default:"5s" validate:"gt=0"`
+	Outbox         OutboxConfig    `yaml:"outbox"`
+	Webhook        WebhookConfig   `yaml:"webhook"`
+	Auth           AuthConfig      `yaml:"auth"`
+	Mail           MailConfig      `yaml:"mail"`
+	RateLimit      RateLimitConfig `yaml:"rate_limit"`
+	Tracing        TracingConfig   `yaml:"tracing"`
 }
 
 type StorageConfig struct {
-	Driver   string `yaml:"driver"`
-	Host     string `yaml:"host"`
-	Port     string `yaml:"port"`
-	Database string `yaml:"database"`
-	Username string `yaml:"username"`
-	Password string `yaml:"password"`
+	Driver   string `yaml:"driver" env:"STORAGE_DRIVER" env-default:"postgresql" validate:"oneof=postgres postgresql"`
+	Host     string `yaml:"host" env:"STORAGE_HOST" env-default:"localhost" validate:"required"`
+	Port     string `yaml:"port" env:"STORAGE_PORT" env-default:"5432" validate:"required,numeric"`
+	Database string `yaml:"database" env:"STORAGE_DATABASE" validate:"required"`
+	Username string `yaml:"username" env:"STORAGE_USERNAME" validate:"required"`
+	Password string `yaml:"password" env:"STORAGE_PASSWORD" secret:"true"`
+	// AutoMigrate applies pending migrations on startup. Instances starting
+	// together take turns, so only one of them migrates.
+	AutoMigrate bool `yaml:"auto_migrate" env-default:"false"`
 }
 
 type HTTPServer struct {
-	Address           string        `yaml:"address" env-default:"localhost:8080"`
-	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env-default:"4s"`
-	Timeout           time.Duration `yaml:"timeout" env-default:"4s"`
-	IdleTimeout       time.Duration `yaml:"idle_timeout" env-default:"60s"`
-	//User        string        `yaml:"user" env-required:"true"`
-	//Password    string        `yaml:"password" env-required:"true" env:"HTTP_SERVER_PASSWORD"`
+	Address           string        `yaml:"address" env-default:"localhost:8080" validate:"hostname_port"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env-default:"4s" validate:"gt=0"`
+	Timeout           time.Duration `yaml:"timeout" env-default:"4s" validate:"gt=0"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env-default:"60s" validate:"gt=0"`
+	MaxBodyBytes      int64         `yaml:"max_body_bytes" env-default:"1048576" validate:"gt=0"`
+	// LegacySunset is announced on the unversioned routes as the date
+	// they stop working; unset, they are only marked deprecated.
+	LegacySunset time.Time `yaml:"legacy_sunset" env:"HTTP_LEGACY_SUNSET" env-layout:"2006-01-02"`
+	// DrainDelay is how long readiness fails before the server shuts down,
+	// for the orchestrator to stop sending traffic.
+	DrainDelay time.Duration `yaml:"drain_delay" env-default:"5s" validate:"gte=0"`
+}
+
+type GRPCServer struct {
+	Address string `yaml:"address" env-default:"localhost:9090" validate:"hostname_port"`
+}
+
+// AdminServer serves operational endpoints such as /metrics and the
+// health probes on a listener of its own, so they stay off the public
+// address.
+type AdminServer struct {
+	// Address is on loopback by default. Where the orchestrator probes over
+	// the network, set it to the pod address, e.g. with
+	// TASK_ADMIN_SERVER_ADDRESS, and keep the port out of what the load
+	// balancer publishes: /metrics is served there too.
+	Address string `yaml:"address" env-default:"localhost:9100" validate:"hostname_port"`
+	// CheckTimeout bounds the dependency checks of the readiness probe.
+	CheckTimeout time.Duration `yaml:"check_timeout" env-default:"2s" validate:"gt=0"`
+}
+
+type OutboxConfig struct {
+	Publisher    string        `yaml:"publisher" env-default:"memory" validate:"oneof=memory file nats kafka"`
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"1s" validate:"gt=0"`
+	BatchSize    int           `yaml:"batch_size" env-default:"100" validate:"gt=0"`
+	FilePath     string        `yaml:"file_path" env-default:"outbox.jsonl"`
+	NATS         struct {
+		URL     string `yaml:"url" env-default:"nats://localhost:4222" validate:"url"`
+		Subject string `yaml:"subject" env-default:"task.events" validate:"required"`
+	} `yaml:"nats"`
+	Kafka struct {
+		Brokers []string `yaml:"brokers" env-default:"localhost:9092" validate:"dive,hostname_port"`
+		Topic   string   `yaml:"topic" env-default:"task.events" validate:"required"`
+	} `yaml:"kafka"`
+	// Lease is how long a relay has to publish the batch it claimed before
+	// another instance may claim the events again.
+	Lease time.Duration `yaml:"lease" env-default:"1m" validate:"gt=0"`
+	// An event that cannot be published is retried with exponential
+	// backoff, and parked after MaxAttempts so that the later events of its
+	// account go through.
+	MaxAttempts int           `yaml:"max_attempts" env-default:"10" validate:"gt=0"`
+	BackoffBase time.Duration `yaml:"backoff_base" env-default:"1s" validate:"gt=0"`
+	BackoffMax  time.Duration `yaml:"backoff_max" env-default:"5m" validate:"gtefield=BackoffBase"`
+}
+
+type WebhookConfig struct {
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"1s" validate:"gt=0"`
+	BatchSize    int           `yaml:"batch_size" env-default:"50" validate:"gt=0"`
+	Timeout      time.Duration `yaml:"timeout" env-default:"10s" validate:"gt=0"`
+	MaxAttempts  int           `yaml:"max_attempts" env-default:"8" validate:"gt=0"`
+	BackoffBase  time.Duration `yaml:"backoff_base" env-default:"10s" validate:"gt=0"`
+	BackoffMax   time.Duration `yaml:"backoff_max" env-default:"1h" validate:"gtefield=BackoffBase"`
+	// AllowPrivateTargets lets webhooks go to loopback, private and
+	// link-local addresses, for development only.
+	AllowPrivateTargets bool `yaml:"allow_private_targets" env-default:"false"`
+}
+
+type AuthConfig struct {
+	Secret     string        `yaml:"secret" env:"AUTH_SECRET" secret:"true" validate:"required"`
+	Issuer     string        `yaml:"issuer" env-default:"task" validate:"required"`
+	AccessTTL  time.Duration `yaml:"access_ttl" env-default:"15m" validate:"gt=0"`
+	RefreshTTL time.Duration `yaml:"refresh_ttl" env-default:"720h" validate:"gtfield=AccessTTL"`
+	// SignatureSkew is how far the timestamp of a signed request may be
+	// from the server clock, either way.
+	SignatureSkew time.Duration `yaml:"signature_skew" env-default:"5m" validate:"gt=0"`
+	// VerificationTTL and ResetTTL bound the life of the single-use tokens
+	// sent by email.
+	VerificationTTL time.Duration `yaml:"verification_ttl" env-default:"48h" validate:"gt=0"`
+	ResetTTL        time.Duration `yaml:"reset_ttl" env-default:"1h" validate:"gt=0"`
+	// StepUpThresholds is the withdrawal amount per currency from which a
+	// fresh one-time code is required.
+	StepUpThresholds map[string]float64 `yaml:"step_up_thresholds" env-default:"USD:1000,EUR:1000,RUB:100000" validate:"dive,keys,currency,endkeys,gt=0"`
+}
+
+type MailConfig struct {
+	Mailer   string `yaml:"mailer" env-default:"memory" validate:"oneof=memory file smtp"`
+	From     string `yaml:"from" env-default:"Task <no-reply@task.local>" validate:"required"`
+	FilePath string `yaml:"file_path" env-default:"mail.jsonl"`
+	// BaseURL is prepended to the links in emails, e.g. the page that
+	// takes a password reset token.
+	BaseURL string `yaml:"base_url" env-default:"http://localhost:8080" validate:"url"`
+	SMTP    struct {
+		Host     string `yaml:"host" env-default:"localhost" validate:"required"`
+		Port     int    `yaml:"port" env-default:"587" validate:"min=1,max=65535"`
+		Username string `yaml:"username"`
+		Password string `yaml:"password" env:"SMTP_PASSWORD" secret:"true"`
+	} `yaml:"smtp"`
+}
+
+// RateLimitConfig sets the token buckets of the HTTP and gRPC APIs, which a
+// caller shares between them. Every caller gets ReadBurst requests at once,
+// refilled at ReadLimit per Period; deposits, withdrawals and settlements
+// also draw on the smaller money budget.
+type RateLimitConfig struct {
+	Enabled    bool          `yaml:"enabled" env-default:"true"`
+	Store      string        `yaml:"store" env-default:"memory" validate:"oneof=memory redis"`
+	Period     time.Duration `yaml:"period" env-default:"1m" validate:"gt=0"`
+	ReadLimit  int           `yaml:"read_limit" env-default:"600" validate:"gt=0"`
+	ReadBurst  int           `yaml:"read_burst" env-default:"100" validate:"gt=0"`
+	MoneyLimit int           `yaml:"money_limit" env-default:"60" validate:"gt=0"`
+	MoneyBurst int           `yaml:"money_burst" env-default:"10" validate:"gt=0"`
+	Redis      struct {
+		Address  string `yaml:"address" env-default:"localhost:6379" validate:"hostname_port"`
+		Password string `yaml:"password" env:"REDIS_PASSWORD" secret:"true"`
+		DB       int    `yaml:"db" validate:"gte=0"`
+		Prefix   string `yaml:"prefix" env-default:"task:ratelimit:"`
+	} `yaml:"redis"`
+}
+
+// TracingConfig selects where spans go: nowhere, stdout for local use or
+// an OTLP/HTTP collector at Endpoint.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" env-default:"none" validate:"oneof=none stdout otlp"`
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" env-default:"localhost:4318" validate:"hostname_port"`
+	Insecure    bool    `yaml:"insecure" env-default:"true"`
+	SampleRatio float64 `yaml:"sample_ratio" env-default:"1" validate:"min=0,max=1"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME" env-default:"task" validate:"required"`
 }
 
 func (sc *StorageConfig) URL() string {
@@ -46,22 +174,3 @@ func (sc *StorageConfig) URL() string {
 		sc.Database,
 	)
 }
-
-func MustLoad() *Config {
-	configPath := os.Getenv("CONFIG_PATH")
-	if configPath == "" {
-		log.Fatal("CONFIG_PATH is not set")
-	}
-
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		log.Fatalf("config file does not exist: %s", configPath)
-	}
-
-	var cfg Config
-
-	if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
-		log.Fatalf("cannot read config: %s", err)
-	}
-
-	return &cfg
-}
@@ -0,0 +1,510 @@
+package common
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix starts the environment variable every setting can be overridden
+// with, e.g. TASK_HTTP_SERVER_ADDRESS for http_server.address. Settings with
+// an env tag can also be set by the names in the tag.
+const EnvPrefix = "TASK_"
+
+// FileSuffix marks an environment variable that names a file holding the
+// value of a secret, e.g. AUTH_SECRET_FILE.
+const FileSuffix = "_FILE"
+
+const redacted = "[redacted]"
+
+// ValidationError lists every problem found in a configuration: unknown
+// keys, malformed values and values that fail their validate tags.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e.Problems, "\n  ")
+}
+
+// Load reads the configuration in layers, each overriding the one before:
+// the env-default tags, the YAML file given by -config or CONFIG_PATH,
+// environment variables, secret files and finally flags named after the
+// YAML path of a setting, e.g. -http_server.address. Problems are collected
+// across all layers and returned at once as a *ValidationError.
+func Load(args []string) (*Config, error) {
+	const op = "common.Load"
+
+	var cfg Config
+	settings := settingsOf(&cfg)
+
+	flags := flag.NewFlagSet("task", flag.ContinueOnError)
+	path := flags.String("config", os.Getenv("CONFIG_PATH"), "path to the YAML configuration file (env CONFIG_PATH)")
+
+	byPath := make(map[string]setting, len(settings))
+	for _, s := range settings {
+		byPath[s.path] = s
+		flags.Var(&flagValue{}, s.path, "env "+strings.Join(s.envNames(), ", "))
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("%s: unexpected arguments %s", op, strings.Join(flags.Args(), " "))
+	}
+
+	var problems []string
+
+	for _, s := range settings {
+		if def, ok := s.tag.Lookup("env-default"); ok {
+			if err := s.set(def); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: bad default %q: %s", s.path, def, err))
+			}
+		}
+	}
+
+	if *path != "" {
+		problems = append(problems, readFile(*path, &cfg)...)
+	}
+
+	for _, s := range settings {
+		if err := s.readEnv(); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		s, ok := byPath[f.Name]
+		if !ok {
+			return
+		}
+
+		if err := s.set(f.Value.String()); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: flag -%s: %s", s.path, f.Name, err))
+		}
+	})
+
+	problems = append(problems, validateConfig(&cfg)...)
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%s: %w", op, &ValidationError{Problems: problems})
+	}
+
+	return &cfg, nil
+}
+
+// readFile decodes the YAML file onto cfg, rejecting keys that match no
+// setting.
+func readFile(path string, cfg *Config) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	defer f.Close()
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+
+	err = decoder.Decode(cfg)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		problems := make([]string, len(typeErr.Errors))
+		for i, problem := range typeErr.Errors {
+			problems[i] = path + ": " + problem
+		}
+		return problems
+	}
+
+	return []string{path + ": " + err.Error()}
+}
+
+var configValidator = newConfigValidator()
+
+func newConfigValidator() *validator.Validate {
+	v := validator.New()
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return yamlName(field)
+	})
+
+	_ = v.RegisterValidation("currency", func(fl validator.FieldLevel) bool {
+		return SupportedCurrency(fl.Field().String())
+	})
+
+	return v
+}
+
+func validateConfig(cfg *Config) []string {
+	err := configValidator.Struct(cfg)
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		if err != nil {
+			return []string{err.Error()}
+		}
+		return nil
+	}
+
+	problems := make([]string, len(fieldErrs))
+	for i, fieldErr := range fieldErrs {
+		_, path, _ := strings.Cut(fieldErr.Namespace(), ".")
+		problems[i] = path + ": " + validationMessage(fieldErr)
+	}
+
+	return problems
+}
+
+func validationMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "oneof":
+		return fmt.Sprintf("must be one of %s, got %q", strings.ReplaceAll(fieldErr.Param(), " ", ", "), fmt.Sprint(fieldErr.Value()))
+	case "gt":
+		return "must be greater than " + fieldErr.Param()
+	case "gte", "min":
+		return "must be at least " + fieldErr.Param()
+	case "max":
+		return "must be at most " + fieldErr.Param()
+	case "gtfield", "gtefield":
+		return "must be greater than " + toSnake(fieldErr.Param())
+	case "hostname_port":
+		return fmt.Sprintf("must be host:port, got %q", fmt.Sprint(fieldErr.Value()))
+	case "url":
+		return fmt.Sprintf("must be a URL, got %q", fmt.Sprint(fieldErr.Value()))
+	case "numeric":
+		return "must be a number"
+	case "currency":
+		return fmt.Sprintf("must be one of %s, got %q", strings.Join(Currencies, ", "), fmt.Sprint(fieldErr.Value()))
+	default:
+		return "fails " + fieldErr.Tag()
+	}
+}
+
+// Dump writes the configuration as YAML, with secrets redacted, the way
+// Load would read it back.
+func (c *Config) Dump(w io.Writer) error {
+	const op = "common.Config.Dump"
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(dumpNode(reflect.ValueOf(c).Elem())); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func dumpNode(v reflect.Value) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+
+		name := yamlName(field)
+		if name == "" {
+			continue
+		}
+
+		var value *yaml.Node
+		switch {
+		case isGroup(field.Type):
+			value = dumpNode(v.Field(i))
+		case field.Tag.Get("secret") == "true" && !v.Field(i).IsZero():
+			value = &yaml.Node{Kind: yaml.ScalarNode, Value: redacted}
+		default:
+			value = scalarNode(v.Field(i), field.Tag)
+		}
+
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, value)
+	}
+
+	return node
+}
+
+func scalarNode(v reflect.Value, tag reflect.StructTag) *yaml.Node {
+	switch value := v.Interface().(type) {
+	case time.Duration:
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: value.String()}
+	case time.Time:
+		if value.IsZero() {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: value.Format(layout(tag))}
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for i := 0; i < v.Len(); i++ {
+			node.Content = append(node.Content, scalarNode(v.Index(i), tag))
+		}
+		return node
+	case reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, key := range keys {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key.String()}, scalarNode(v.MapIndex(key), tag))
+		}
+		return node
+	}
+
+	var node yaml.Node
+	_ = node.Encode(v.Interface())
+
+	return &node
+}
+
+// setting is a leaf of the configuration, addressed by its YAML path, e.g.
+// http_server.address.
+type setting struct {
+	path  string
+	value reflect.Value
+	tag   reflect.StructTag
+}
+
+func settingsOf(cfg *Config) []setting {
+	return appendSettings(nil, reflect.ValueOf(cfg).Elem(), "")
+}
+
+func appendSettings(settings []setting, v reflect.Value, prefix string) []setting {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+
+		name := yamlName(field)
+		if name == "" {
+			continue
+		}
+
+		if isGroup(field.Type) {
+			settings = appendSettings(settings, v.Field(i), prefix+name+".")
+			continue
+		}
+
+		settings = append(settings, setting{path: prefix + name, value: v.Field(i), tag: field.Tag})
+	}
+
+	return settings
+}
+
+// envNames are the variables the setting is read from, in order of
+// precedence.
+func (s setting) envNames() []string {
+	var names []string
+	if env := s.tag.Get("env"); env != "" {
+		names = strings.Split(env, ",")
+	}
+
+	return append(names, EnvPrefix+strings.ToUpper(strings.ReplaceAll(s.path, ".", "_")))
+}
+
+// readEnv sets the setting from the first of its variables that is set.
+// Secrets may instead be read from the file named by the variable with
+// FileSuffix appended.
+func (s setting) readEnv() error {
+	names := s.envNames()
+
+	for _, name := range names {
+		if raw, ok := os.LookupEnv(name); ok {
+			if err := s.set(raw); err != nil {
+				return fmt.Errorf("%s: env %s: %w", s.path, name, err)
+			}
+			return nil
+		}
+	}
+
+	if s.tag.Get("secret") != "true" {
+		return nil
+	}
+
+	for _, name := range names {
+		path, ok := os.LookupEnv(name + FileSuffix)
+		if !ok {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s: env %s: %w", s.path, name+FileSuffix, err)
+		}
+
+		return s.set(strings.TrimRight(string(content), "\r\n"))
+	}
+
+	return nil
+}
+
+func (s setting) set(raw string) error {
+	separator := s.tag.Get("env-separator")
+	if separator == "" {
+		separator = ","
+	}
+
+	return parseValue(s.value, raw, separator, layout(s.tag))
+}
+
+// parseValue sets v from its text form. Slices are separated by separator,
+// maps are key:value pairs separated by separator.
+func parseValue(v reflect.Value, raw string, separator string, timeLayout string) error {
+	switch v.Interface().(type) {
+	case time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case time.Time:
+		if raw == "" {
+			v.Set(reflect.ValueOf(time.Time{}))
+			return nil
+		}
+		t, err := time.Parse(timeLayout, raw)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		items := splitList(raw, separator)
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := parseValue(slice.Index(i), item, separator, timeLayout); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		for _, item := range splitList(raw, separator) {
+			key, value, ok := strings.Cut(item, ":")
+			if !ok {
+				return fmt.Errorf("%q is not a key:value pair", item)
+			}
+
+			k := reflect.New(v.Type().Key()).Elem()
+			if err := parseValue(k, strings.TrimSpace(key), separator, timeLayout); err != nil {
+				return err
+			}
+			e := reflect.New(v.Type().Elem()).Elem()
+			if err := parseValue(e, strings.TrimSpace(value), separator, timeLayout); err != nil {
+				return err
+			}
+			m.SetMapIndex(k, e)
+		}
+		v.Set(m)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+func splitList(raw string, separator string) []string {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+
+	items := strings.Split(raw, separator)
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+
+	return items
+}
+
+func layout(tag reflect.StructTag) string {
+	if l := tag.Get("env-layout"); l != "" {
+		return l
+	}
+
+	return time.RFC3339
+}
+
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "-" {
+		return ""
+	}
+
+	return name
+}
+
+// isGroup tells a struct of settings from a single setting.
+func isGroup(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{})
+}
+
+func toSnake(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && r >= 'A' && r <= 'Z' && !(name[i-1] >= 'A' && name[i-1] <= 'Z') {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+
+	return strings.ToLower(b.String())
+}
+
+// flagValue records the text of a flag, which is applied once the other
+// layers have been read.
+type flagValue struct {
+	raw string
+}
+
+func (f *flagValue) String() string {
+	if f == nil {
+		return ""
+	}
+
+	return f.raw
+}
+
+func (f *flagValue) Set(raw string) error {
+	f.raw = raw
+	return nil
+}
@@ -0,0 +1,116 @@
+package common
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+const validConfig = `
+storage:
+  host: "db"
+  database: "task"
+  username: "task"
+http_server:
+  address: "localhost:7777"
+  timeout: 3s
+auth:
+  secret: "file-secret"
+rate_limit:
+  enabled: false
+`
+
+func TestLoad_Layers(t *testing.T) {
+	path := writeFile(t, "config.yaml", validConfig)
+	secret := writeFile(t, "secret", "db-password\n")
+
+	t.Setenv("STORAGE_HOST", "env-db")
+	t.Setenv("TASK_HTTP_SERVER_TIMEOUT", "5s")
+	t.Setenv("TASK_HTTP_SERVER_ADDRESS", "localhost:8888")
+	t.Setenv("STORAGE_PASSWORD_FILE", secret)
+
+	cfg, err := Load([]string{"-config", path, "-http_server.address", "localhost:9999"})
+	require.NoError(t, err)
+
+	// Defaults.
+	require.Equal(t, "5432", cfg.Port)
+	require.Equal(t, "localhost:9100", cfg.AdminServer.Address)
+	require.Equal(t, time.Minute, cfg.RateLimit.Period)
+	require.Equal(t, map[string]float64{"USD": 1000, "EUR": 1000, "RUB": 100000}, cfg.Auth.StepUpThresholds)
+	// The file, even where it sets a zero value over a default.
+	require.Equal(t, "task", cfg.Database)
+	require.Equal(t, "file-secret", cfg.Auth.Secret)
+	require.False(t, cfg.RateLimit.Enabled)
+	// Environment variables, by the name in the env tag or the derived one,
+	// and secret files.
+	require.Equal(t, "env-db", cfg.Host)
+	require.Equal(t, 5*time.Second, cfg.Timeout)
+	require.Equal(t, "db-password", cfg.StorageConfig.Password)
+	// Flags.
+	require.Equal(t, "localhost:9999", cfg.Address)
+}
+
+func TestLoad_ReportsEveryProblem(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+http_server:
+  address: "nope"
+  user: "myuser"
+outbox:
+  publisher: "rabbit"
+auth:
+  access_ttl: 1h
+  refresh_ttl: 30m
+  step_up_thresholds:
+    GBP: 10
+`)
+
+	_, err := Load([]string{"-config", path, "-webhook.max_attempts", "many"})
+
+	var invalid *ValidationError
+	require.True(t, errors.As(err, &invalid))
+	require.Equal(t, []string{
+		path + ": line 4: field user not found in type common.HTTPServer",
+		"webhook.max_attempts: flag -webhook.max_attempts: strconv.ParseInt: parsing \"many\": invalid syntax",
+		"storage.database: is required",
+		"storage.username: is required",
+		`http_server.address: must be host:port, got "nope"`,
+		`outbox.publisher: must be one of memory, file, nats, kafka, got "rabbit"`,
+		"auth.secret: is required",
+		"auth.refresh_ttl: must be greater than access_ttl",
+		`auth.step_up_thresholds[GBP]: must be one of USD, EUR, RUB, got "GBP"`,
+	}, invalid.Problems)
+}
+
+func TestConfig_Dump(t *testing.T) {
+	path := writeFile(t, "config.yaml", validConfig)
+
+	cfg, err := Load([]string{"-config", path})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, cfg.Dump(&out))
+
+	require.Contains(t, out.String(), "  secret: '[redacted]'\n")
+	require.Contains(t, out.String(), "  password: \"\"\n")
+	require.Contains(t, out.String(), "  timeout: 3s\n")
+	require.NotContains(t, out.String(), "file-secret")
+
+	// The dump reads back to the same configuration.
+	again, err := Load([]string{"-config", writeFile(t, "dump.yaml", out.String()), "-auth.secret", "file-secret"})
+	require.NoError(t, err)
+	require.Equal(t, cfg, again)
+}
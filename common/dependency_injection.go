@@ -11,9 +11,7 @@ type DependencyContainer struct {
 	Config *Config
 }
 
-func NewDIContainer() (*DependencyContainer, error) {
-	cfg := MustLoad()
-
+func NewDIContainer(cfg *Config) (*DependencyContainer, error) {
 	pool, err := NewConnectionDB(cfg.URL())
 	if err != nil {
 		return nil, err
@@ -29,7 +27,13 @@ func NewConnectionDB(uri string) (*pgxpool.Pool, error) {
 
 	const op = "common.NewConnectionDB"
 
-	pool, err := pgxpool.New(context.Background(), uri)
+	config, err := pgxpool.ParseConfig(uri)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	config.ConnConfig.Tracer = QueryTracer{}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
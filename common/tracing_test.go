@@ -0,0 +1,148 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"golang.org/x/exp/slog"
+)
+
+// recordSpans installs a tracer provider that records every span until the
+// test ends.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	return recorder
+}
+
+func TestQueryTracer(t *testing.T) {
+	cases := []struct {
+		name       string
+		sql        string
+		err        error
+		wantName   string
+		wantStatus codes.Code
+		wantAttr   attribute.KeyValue
+	}{
+		{
+			name:       "Success",
+			sql:        "\n\t\tupdate account set balance = $1 where id = $2",
+			wantName:   "db UPDATE",
+			wantStatus: codes.Unset,
+			wantAttr:   attribute.Int64("db.rows_affected", 1),
+		},
+		{
+			name:       "Failure",
+			sql:        "SELECT id FROM account",
+			err:        errors.New("relation does not exist"),
+			wantName:   "db SELECT",
+			wantStatus: codes.Error,
+			wantAttr:   semconv.DBOperation("SELECT"),
+		},
+		{
+			name:       "Empty statement",
+			wantName:   "db QUERY",
+			wantStatus: codes.Unset,
+			wantAttr:   semconv.DBSystemPostgreSQL,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := recordSpans(t)
+
+			ctx, parent := StartSpan(context.Background(), "service")
+
+			tracer := QueryTracer{}
+			queryCtx := tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: tc.sql})
+			tracer.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{
+				CommandTag: pgconn.NewCommandTag("UPDATE 1"),
+				Err:        tc.err,
+			})
+			parent.End()
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 2)
+
+			span := spans[0]
+			require.Equal(t, tc.wantName, span.Name())
+			require.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+			require.Equal(t, tc.wantStatus, span.Status().Code)
+			require.Contains(t, span.Attributes(), semconv.DBStatement(tc.sql))
+			require.Contains(t, span.Attributes(), tc.wantAttr)
+
+			if tc.err != nil {
+				require.Len(t, span.Events(), 1)
+				require.Equal(t, "exception", span.Events()[0].Name)
+			}
+		})
+	}
+}
+
+func TestTraceHandler(t *testing.T) {
+	recordSpans(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(NewTraceHandler(slog.NewJSONHandler(&buf, nil))).With("component", "test")
+
+	ctx, span := StartSpan(context.Background(), "request")
+	defer span.End()
+
+	cases := []struct {
+		name        string
+		ctx         context.Context
+		wantTraceID string
+		wantSpanID  string
+	}{
+		{
+			name:        "In a span",
+			ctx:         ctx,
+			wantTraceID: span.SpanContext().TraceID().String(),
+			wantSpanID:  span.SpanContext().SpanID().String(),
+		},
+		{
+			name: "Without a span",
+			ctx:  context.Background(),
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+			logger.InfoCtx(tc.ctx, "request processed")
+
+			var record map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+			require.Equal(t, "test", record["component"])
+			if tc.wantTraceID == "" {
+				require.NotContains(t, record, "trace_id")
+				require.NotContains(t, record, "span_id")
+				return
+			}
+			require.Equal(t, tc.wantTraceID, record["trace_id"])
+			require.Equal(t, tc.wantSpanID, record["span_id"])
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Querier is the subset of pgx shared by *pgxpool.Pool and pgx.Tx.
+type Querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type txCtxKey struct{}
+
+type Transactor struct {
+	pool *pgxpool.Pool
+}
+
+func NewTransactor(pool *pgxpool.Pool) *Transactor {
+	return &Transactor{
+		pool: pool,
+	}
+}
+
+// WithinTransaction runs fn inside a database transaction carried by ctx.
+// Nested calls join the outer transaction.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	const op = "common.Transactor.WithinTransaction"
+
+	if _, ok := ctx.Value(txCtxKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if err := fn(context.WithValue(ctx, txCtxKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DryRun runs fn inside a database transaction carried by ctx and rolls it
+// back, so that nested WithinTransaction calls commit nothing. Inside an
+// outer transaction it rolls back to a savepoint instead.
+func (t *Transactor) DryRun(ctx context.Context, fn func(ctx context.Context) error) error {
+	const op = "common.Transactor.DryRun"
+
+	var (
+		tx  pgx.Tx
+		err error
+	)
+	if outer, ok := ctx.Value(txCtxKey{}).(pgx.Tx); ok {
+		tx, err = outer.Begin(ctx)
+	} else {
+		tx, err = t.pool.Begin(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	return fn(context.WithValue(ctx, txCtxKey{}, tx))
+}
+
+// Conn returns the transaction stored in ctx, or pool when there is none.
+func Conn(ctx context.Context, pool *pgxpool.Pool) Querier {
+	if tx, ok := ctx.Value(txCtxKey{}).(pgx.Tx); ok {
+		return tx
+	}
+
+	return pool
+}
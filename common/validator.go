@@ -5,6 +5,19 @@ import (
 	"task/internal/domain/Errors"
 )
 
+// Currencies are the ISO 4217 codes accounts and transactions may use.
+var Currencies = []string{"USD", "EUR", "RUB"}
+
+func SupportedCurrency(code string) bool {
+	for _, currency := range Currencies {
+		if code == currency {
+			return true
+		}
+	}
+
+	return false
+}
+
 func ValidateCurrency(trCurrency string, acCurrency string, amount float64) (float64, error) {
 	const op = "common.ValidateCurrency"
 
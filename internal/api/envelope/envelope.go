@@ -0,0 +1,46 @@
+package envelope
+
+import (
+	"github.com/go-chi/chi/v5/middleware"
+	"net/http"
+	"strings"
+)
+
+// Version is the current version of the API; its routes live under
+// Prefix.
+const (
+	Version = "v1"
+	Prefix  = "/" + Version
+)
+
+// Envelope wraps every body of the versioned API: the resource in Data on
+// success, the problem details in Error otherwise.
+type Envelope struct {
+	Data  any  `json:"data"`
+	Error any  `json:"error"`
+	Meta  Meta `json:"meta"`
+}
+
+type Meta struct {
+	Version   string `json:"version"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// New wraps data and err for the request r.
+func New(r *http.Request, data any, err any) Envelope {
+	return Envelope{
+		Data:  data,
+		Error: err,
+		Meta: Meta{
+			Version:   Version,
+			RequestID: middleware.GetReqID(r.Context()),
+		},
+	}
+}
+
+// Versioned reports whether r addresses the versioned API. The legacy
+// routes keep the bodies they had before it, so the decision is made on the
+// path and holds even for requests rejected before routing.
+func Versioned(r *http.Request) bool {
+	return r.URL.Path == Prefix || strings.HasPrefix(r.URL.Path, Prefix+"/")
+}
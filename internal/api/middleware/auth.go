@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"task/common"
+	"task/internal/api/problem"
+	"task/internal/api/response"
+	"task/internal/domain/Errors"
+	"task/internal/domain/auth/entity"
+	rbac "task/internal/domain/rbac/entity"
+)
+
+const (
+	HeaderAPIKey = "X-API-Key"
+	// HeaderOTP carries the one-time code required by high-value
+	// operations.
+	HeaderOTP = "X-OTP"
+)
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string, clientIP string) (*entity.Principal, error)
+}
+
+// AccountResolver returns the account a request acts on.
+type AccountResolver func(r *http.Request) (uint64, error)
+
+// Authenticate resolves the caller from the bearer token or the X-API-Key
+// header and stores it in the request context. Requests without valid
+// credentials get 401.
+func Authenticate(authenticator Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			raw, ok := credential(r)
+			if !ok {
+				response.Unauthorized(w, r)
+				return
+			}
+
+			principal, err := authenticator.Authenticate(ctx, raw, ClientIP(r))
+			if err != nil {
+				common.FromContext(ctx).Debug("authentication failed", "error", err.Error())
+				response.Unauthorized(w, r)
+				return
+			}
+
+			ctx = entity.WithPrincipal(ctx, principal)
+			if code := r.Header.Get(HeaderOTP); code != "" {
+				ctx = entity.WithOTP(ctx, code)
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequirePermission lets the request through only when the caller's roles
+// grant every permission.
+func RequirePermission(permissions ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := entity.PrincipalFromContext(r.Context())
+			if !ok {
+				response.Unauthorized(w, r)
+				return
+			}
+
+			for _, permission := range permissions {
+				if !principal.Can(permission) {
+					response.Forbidden(w, r)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireOwner lets the request through only when the caller owns every
+// account returned by the resolvers, or may act on any account. Resolvers
+// report missing resources as Errors.ErrForbidden so that ids of other
+// customers are not disclosed.
+func RequireOwner(resolvers ...AccountResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := entity.PrincipalFromContext(r.Context())
+			if !ok {
+				response.Unauthorized(w, r)
+				return
+			}
+
+			for _, resolve := range resolvers {
+				accountID, err := resolve(r)
+				if errors.Is(err, Errors.ErrIncorrectID) {
+					problem.Render(w, r, err)
+					return
+				}
+				if errors.Is(err, Errors.ErrForbidden) || err == nil && !principal.CanAccess(accountID, rbac.PermAccountsAny) {
+					response.Forbidden(w, r)
+					return
+				}
+				if err != nil {
+					common.FromRequest(r).Error("cannot resolve resource owner", "error", err.Error())
+					problem.Render(w, r, err)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// URLParam resolves the account id from a route parameter.
+func URLParam(key string) AccountResolver {
+	return func(r *http.Request) (uint64, error) {
+		return parseID(chi.URLParam(r, key))
+	}
+}
+
+// BodyField resolves the account id from a top-level field of the JSON
+// body. The body is restored for the handler.
+func BodyField(field string) AccountResolver {
+	return func(r *http.Request) (uint64, error) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s", Errors.ErrIncorrectID, err)
+		}
+		_ = r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return 0, fmt.Errorf("%w: %s", Errors.ErrIncorrectID, err)
+		}
+
+		return parseID(string(fields[field]))
+	}
+}
+
+// Lookup resolves the account owning the resource named by a route
+// parameter, e.g. the account of a transaction.
+func Lookup(key string, owner func(ctx context.Context, id uint64) (uint64, error)) AccountResolver {
+	return func(r *http.Request) (uint64, error) {
+		id, err := parseID(chi.URLParam(r, key))
+		if err != nil {
+			return 0, err
+		}
+
+		return owner(r.Context(), id)
+	}
+}
+
+func credential(r *http.Request) (string, bool) {
+	if key := r.Header.Get(HeaderAPIKey); key != "" {
+		return key, true
+	}
+
+	scheme, raw, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || raw == "" {
+		return "", false
+	}
+
+	return strings.TrimSpace(raw), true
+}
+
+// ClientIP is the peer address. Forwarding headers are not trusted, so the
+// API key allowlists see the address of the proxy when there is one.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func parseID(value string) (uint64, error) {
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", Errors.ErrIncorrectID, value)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+	"task/internal/api/response"
+)
+
+// LimitBody caps request bodies at limit bytes. Requests that announce a
+// larger body get 413 straight away; others fail once a handler reads past
+// the limit. A limit of zero or less disables the check.
+func LimitBody(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				response.RequestTooLarge(w, r)
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
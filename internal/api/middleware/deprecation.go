@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strings"
+	"task/internal/api/envelope"
+	"time"
+)
+
+// Deprecated keeps the legacy routes working while pointing their clients
+// to the versioned API. A request matching one of the route patterns in
+// successors gets Deprecation, a Link to the successor route with the
+// parameters of the request filled in and, when a date is given, Sunset
+// (RFC 8594). It matches the route itself, ahead of routing, so that
+// rejected requests carry the headers too.
+func Deprecated(routes chi.Routes, successors map[string]string, sunset time.Time) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if envelope.Versioned(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rctx := chi.NewRouteContext()
+			if !routes.Match(rctx, r.Method, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			successor, ok := successors[rctx.RoutePattern()]
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			for i, key := range rctx.URLParams.Keys {
+				successor = strings.ReplaceAll(successor, "{"+key+"}", rctx.URLParams.Values[i])
+			}
+
+			w.Header().Set("Deprecation", "true")
+			w.Header().Add("Link", "<"+successor+`>; rel="successor-version"`)
+			if !sunset.IsZero() {
+				w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
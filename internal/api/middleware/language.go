@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"net/http"
+	"task/internal/i18n"
+)
+
+// Language picks the language of error messages and notifications from
+// the Accept-Language header, see i18n.Negotiate.
+func Language(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := i18n.Negotiate(r.Header.Get("Accept-Language"))
+
+		w.Header().Set("Content-Language", lang)
+		w.Header().Add("Vary", "Accept-Language")
+
+		next.ServeHTTP(w, r.WithContext(i18n.WithLanguage(r.Context(), lang)))
+	})
+}
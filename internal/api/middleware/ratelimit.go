@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"task/common"
+	"task/internal/api/ratelimit"
+	"task/internal/api/response"
+	"task/internal/domain/auth/entity"
+	"time"
+)
+
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+	HeaderRetryAfter         = "Retry-After"
+)
+
+type Limiter interface {
+	Take(ctx context.Context, key string, policy ratelimit.Policy) (*ratelimit.Result, error)
+}
+
+// RateLimit takes a token from the caller's bucket of the policy and
+// answers 429 when it is empty. Callers are told apart by API key, then by
+// account, then by address, so it goes after Authenticate on protected
+// routes. The store failing lets requests through rather than taking the
+// API down with it.
+func RateLimit(limiter Limiter, policy ratelimit.Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			res, err := limiter.Take(ctx, policy.Name+":"+RateLimitKey(ctx, ClientIP(r)), policy)
+			if err != nil {
+				common.FromRequest(r).Error("cannot check rate limit", "policy", policy.Name, "error", err.Error())
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			header.Set(HeaderRateLimitLimit, strconv.Itoa(policy.Burst))
+			header.Set(HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+			header.Set(HeaderRateLimitReset, ceilSeconds(res.Reset))
+			header.Set(HeaderRateLimitPolicy, fmt.Sprintf("%d;w=%s;burst=%d", policy.Limit, ceilSeconds(policy.Period), policy.Burst))
+
+			if !res.Allowed {
+				header.Set(HeaderRetryAfter, ceilSeconds(res.RetryAfter))
+				response.TooManyRequests(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimitKey names the bucket of the caller in ctx, or of clientIP for
+// anonymous callers. The gRPC API uses it too, so that a caller has one
+// budget whichever API it calls.
+func RateLimitKey(ctx context.Context, clientIP string) string {
+	principal, ok := entity.PrincipalFromContext(ctx)
+	switch {
+	case ok && principal.APIKeyID != 0:
+		return "key:" + strconv.FormatUint(principal.APIKeyID, 10)
+	case ok && principal.AccountID != 0:
+		return "account:" + strconv.FormatUint(principal.AccountID, 10)
+	default:
+		return "ip:" + clientIP
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
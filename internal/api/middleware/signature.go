@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"task/common"
+	"task/internal/api/problem"
+	"task/internal/api/response"
+	"task/internal/domain/Errors"
+	apikey "task/internal/domain/apikey/entity"
+	"task/internal/domain/auth/entity"
+	"task/pkg/signing"
+)
+
+type SignatureVerifier interface {
+	VerifySignature(ctx context.Context, keyID uint64, req *apikey.SignedRequest) error
+}
+
+// VerifySignature checks signed requests made with an API key, see package
+// signing. Unsigned requests pass unless the key requires signing; a bad
+// signature, stale timestamp or replayed nonce gets 401. Requests made with
+// access tokens are not signed.
+func VerifySignature(verifier SignatureVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			principal, ok := entity.PrincipalFromContext(ctx)
+			if !ok {
+				response.Unauthorized(w, r)
+				return
+			}
+			if principal.APIKeyID == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			signature := r.Header.Get(signing.HeaderSignature)
+			if signature == "" {
+				if principal.SignatureRequired {
+					common.FromContext(ctx).Debug("unsigned request", "api_key_id", principal.APIKeyID)
+					response.Unauthorized(w, r)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			timestamp, err := strconv.ParseInt(r.Header.Get(signing.HeaderTimestamp), 10, 64)
+			nonce := r.Header.Get(signing.HeaderNonce)
+			if err != nil || nonce == "" || len(nonce) > 64 {
+				response.Unauthorized(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				response.Unauthorized(w, r)
+				return
+			}
+			_ = r.Body.Close()
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			err = verifier.VerifySignature(ctx, principal.APIKeyID, &apikey.SignedRequest{
+				Method:    r.Method,
+				URI:       r.URL.RequestURI(),
+				Timestamp: timestamp,
+				Nonce:     nonce,
+				Body:      body,
+				Signature: signature,
+			})
+			if errors.Is(err, Errors.ErrInvalidSignature) || errors.Is(err, Errors.ErrReplayedRequest) {
+				common.FromContext(ctx).Debug("signature rejected", "error", err.Error())
+				response.Unauthorized(w, r)
+				return
+			}
+			if err != nil {
+				common.FromRequest(r).Error("cannot verify signature", "error", err.Error())
+				problem.Render(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
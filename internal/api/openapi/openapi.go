@@ -0,0 +1,216 @@
+package openapi
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/go-chi/chi/v5"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"task/common"
+	"task/internal/api/problem"
+	"task/internal/api/validate"
+	"task/internal/i18n"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+//go:embed docs.html
+var docsHTML []byte
+
+// swaggerUI holds the files of swagger-ui-dist 5.29.1 the docs page loads,
+// so that it works without reaching a CDN.
+//
+//go:embed swagger-ui
+var swaggerUI embed.FS
+
+type Spec struct {
+	doc    *openapi3.T
+	json   []byte
+	assets http.Handler
+}
+
+// Load parses and validates the embedded OpenAPI document.
+func Load() (*Spec, error) {
+	const op = "openapi.Load"
+
+	loader := openapi3.NewLoader()
+
+	doc, err := loader.LoadFromData(specYAML)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	raw, err := doc.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	assets, err := fs.Sub(swaggerUI, "swagger-ui")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Spec{
+		doc:    doc,
+		json:   raw,
+		assets: http.StripPrefix("/docs/", http.FileServer(http.FS(assets))),
+	}, nil
+}
+
+// Operation returns the operation documented for a chi route pattern.
+func (s *Spec) Operation(method string, pattern string) (*openapi3.PathItem, *openapi3.Operation) {
+	item := s.doc.Paths.Find(pattern)
+	if item == nil {
+		return nil, nil
+	}
+
+	return item, item.GetOperation(method)
+}
+
+func (s *Spec) ServeJSON(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(s.json)
+}
+
+func (s *Spec) ServeDocs(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(docsHTML)
+}
+
+// ServeAsset serves a file of Swagger UI, under /docs/{asset}.
+func (s *Spec) ServeAsset(w http.ResponseWriter, r *http.Request) {
+	s.assets.ServeHTTP(w, r)
+}
+
+// Validator checks path parameters, query, headers and body of a request
+// against its operation. It relies on chi having matched the route already,
+// so it has to be mounted on a group rather than on the root router.
+func (s *Spec) Validator() func(http.Handler) http.Handler {
+	options := &openapi3filter.Options{
+		MultiError:         true,
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rctx := chi.RouteContext(r.Context())
+			pattern := rctx.RoutePattern()
+
+			item, operation := s.Operation(r.Method, pattern)
+			if operation == nil {
+				common.FromRequest(r).Warn("route is missing from the OpenAPI document", "pattern", pattern, "method", r.Method)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			params := make(map[string]string, len(rctx.URLParams.Keys))
+			for i, key := range rctx.URLParams.Keys {
+				params[key] = rctx.URLParams.Values[i]
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: params,
+				Route: &routers.Route{
+					Spec:      s.doc,
+					Path:      pattern,
+					PathItem:  item,
+					Method:    r.Method,
+					Operation: operation,
+				},
+				Options: options,
+			}
+
+			if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+				p := problem.New(r, http.StatusBadRequest, problem.CodeValidationFailed)
+				p.Detail = i18n.T(r.Context(), "error.schema_mismatch", nil)
+				p.Errors = validationErrors(err)
+				problem.Write(w, r, p)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// validationErrors flattens the errors of the filter into field errors of
+// the same shape the handlers report.
+func validationErrors(err error) []validate.FieldError {
+	var multi openapi3.MultiError
+	if !errors.As(err, &multi) {
+		return fieldErrors(err, "")
+	}
+
+	var fields []validate.FieldError
+	for _, e := range multi {
+		fields = append(fields, fieldErrors(e, "")...)
+	}
+
+	return fields
+}
+
+func fieldErrors(err error, field string) []validate.FieldError {
+	var (
+		multi   openapi3.MultiError
+		request *openapi3filter.RequestError
+		schema  *openapi3.SchemaError
+	)
+
+	switch {
+	case errors.As(err, &request) && request.Err != nil:
+		if request.Parameter != nil {
+			field = request.Parameter.Name
+		}
+		return fieldErrors(request.Err, field)
+	case errors.As(err, &multi):
+		var fields []validate.FieldError
+		for _, e := range multi {
+			fields = append(fields, fieldErrors(e, field)...)
+		}
+		return fields
+	case errors.As(err, &schema):
+		if path := jsonPath(schema.JSONPointer()); path != "" {
+			field = path
+		}
+		return []validate.FieldError{{Field: field, Rule: schema.SchemaField, Message: schema.Reason}}
+	case errors.As(err, &request):
+		if request.Parameter != nil {
+			field = request.Parameter.Name
+		}
+		return []validate.FieldError{{Field: field, Rule: "schema", Message: request.Reason}}
+	default:
+		return []validate.FieldError{{Field: field, Rule: "schema", Message: err.Error()}}
+	}
+}
+
+// jsonPath turns a JSON pointer into the path notation of package validate,
+// e.g. "scopes[1]".
+func jsonPath(pointer []string) string {
+	var path strings.Builder
+
+	for _, part := range pointer {
+		if _, err := strconv.Atoi(part); err == nil {
+			path.WriteString("[" + part + "]")
+			continue
+		}
+		if path.Len() > 0 {
+			path.WriteString(".")
+		}
+		path.WriteString(part)
+	}
+
+	return path.String()
+}
@@ -0,0 +1,63 @@
+package problem
+
+import (
+	"net/http"
+	"task/internal/domain/Errors"
+)
+
+const (
+	CodeInternal         = "internal_error"
+	CodeUnauthenticated  = "unauthenticated"
+	CodeForbidden        = "forbidden"
+	CodeRateLimited      = "rate_limited"
+	CodeRequestTooLarge  = "request_too_large"
+	CodeValidationFailed = "validation_failed"
+)
+
+var errorCodes = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{Errors.ErrAccountNotFound, http.StatusNotFound, "account_not_found"},
+	{Errors.ErrTransactionNotFound, http.StatusNotFound, "transaction_not_found"},
+	{Errors.ErrWebhookNotFound, http.StatusNotFound, "webhook_not_found"},
+	{Errors.ErrDeliveryNotFound, http.StatusNotFound, "delivery_not_found"},
+	{Errors.ErrRoleNotFound, http.StatusNotFound, "role_not_found"},
+	{Errors.ErrPermissionNotFound, http.StatusNotFound, "permission_not_found"},
+	{Errors.ErrAPIKeyNotFound, http.StatusNotFound, "api_key_not_found"},
+	{Errors.ErrSessionNotFound, http.StatusNotFound, "session_not_found"},
+	{Errors.ErrAccountExists, http.StatusConflict, "account_exists"},
+	{Errors.ErrTransactionExists, http.StatusConflict, "transaction_exists"},
+	{Errors.ErrNotPending, http.StatusConflict, "transaction_not_pending"},
+	{Errors.ErrNotSettled, http.StatusConflict, "transaction_not_settled"},
+	{Errors.ErrRoleExists, http.StatusConflict, "role_exists"},
+	{Errors.ErrBuiltinRole, http.StatusConflict, "builtin_role"},
+	{Errors.ErrTOTPEnabled, http.StatusConflict, "totp_enabled"},
+	{Errors.ErrTOTPNotEnabled, http.StatusConflict, "totp_not_enabled"},
+	{Errors.ErrNegativeBalance, http.StatusUnprocessableEntity, "negative_balance"},
+	{Errors.ErrZeroBalance, http.StatusUnprocessableEntity, "zero_balance"},
+	{Errors.ErrPolicyViolation, http.StatusUnprocessableEntity, "policy_violation"},
+	{Errors.ErrInvalidCurrency, http.StatusBadRequest, "invalid_currency"},
+	{Errors.ErrInvalidAmount, http.StatusBadRequest, "invalid_amount"},
+	{Errors.ErrInvalidBalance, http.StatusBadRequest, "invalid_balance"},
+	{Errors.ErrInvalidEmail, http.StatusBadRequest, "invalid_email"},
+	{Errors.ErrIncorrectID, http.StatusBadRequest, "invalid_id"},
+	{Errors.ErrInvalidWebhookURL, http.StatusBadRequest, "invalid_webhook_url"},
+	{Errors.ErrInvalidRoleName, http.StatusBadRequest, "invalid_role_name"},
+	{Errors.ErrInvalidAPIKey, http.StatusBadRequest, "invalid_api_key"},
+	{Errors.ErrInvalidIPAllowlist, http.StatusBadRequest, "invalid_ip_allowlist"},
+	{Errors.ErrWeakPassword, http.StatusBadRequest, "weak_password"},
+	{Errors.ErrInvalidOTP, http.StatusBadRequest, "invalid_otp"},
+	{Errors.ErrEmptyRequest, http.StatusBadRequest, "empty_request"},
+	{Errors.ErrMalformedRequest, http.StatusBadRequest, "malformed_request"},
+	{Errors.ErrValidation, http.StatusBadRequest, CodeValidationFailed},
+	{Errors.ErrRequestTooLarge, http.StatusRequestEntityTooLarge, CodeRequestTooLarge},
+	{Errors.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials"},
+	{Errors.ErrInvalidToken, http.StatusUnauthorized, "invalid_token"},
+	{Errors.ErrInvalidSignature, http.StatusUnauthorized, "invalid_signature"},
+	{Errors.ErrReplayedRequest, http.StatusUnauthorized, "replayed_request"},
+	{Errors.ErrUnauthenticated, http.StatusUnauthorized, CodeUnauthenticated},
+	{Errors.ErrForbidden, http.StatusForbidden, CodeForbidden},
+	{Errors.ErrStepUpRequired, http.StatusForbidden, "step_up_required"},
+}
@@ -0,0 +1,123 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"net/http"
+	"task/internal/api/envelope"
+	"task/internal/api/validate"
+	"task/internal/i18n"
+)
+
+// ContentType is the media type of problem details, RFC 7807.
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details object. Code is a stable, machine
+// readable identifier of the error; RequestID matches the X-Request-Id of
+// the logs.
+type Problem struct {
+	Type      string                `json:"type"`
+	Title     string                `json:"title"`
+	Status    int                   `json:"status"`
+	Detail    string                `json:"detail,omitempty"`
+	Instance  string                `json:"instance,omitempty"`
+	Code      string                `json:"code"`
+	RequestID string                `json:"request_id,omitempty"`
+	Errors    []validate.FieldError `json:"errors,omitempty"`
+}
+
+// Error overrides the status or detail the error table gives to the error
+// it wraps. Zero values keep the ones of the table; Detail is a message key
+// of package i18n.
+type Error struct {
+	Err    error
+	Status int
+	Detail string
+	Args   i18n.Args
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap answers err with status and the message detail instead of the
+// defaults of its sentinel, e.g. 403 for an invalid one-time code of a
+// step-up check.
+func Wrap(err error, status int, detail string, args i18n.Args) error {
+	return &Error{Err: err, Status: status, Detail: detail, Args: args}
+}
+
+// New builds the problem of the request r. The detail is the message of
+// code in the language of the request.
+func New(r *http.Request, status int, code string) *Problem {
+	return &Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    i18n.T(r.Context(), "error."+code, nil),
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+}
+
+// FromError maps err to a problem. Sentinels of the Errors package get
+// their status and code from the error table; anything else is an internal
+// error whose details stay in the logs.
+func FromError(r *http.Request, err error) *Problem {
+	p := New(r, http.StatusInternalServerError, CodeInternal)
+
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			p = New(r, e.status, e.code)
+			break
+		}
+	}
+
+	var invalid *validate.Error
+	if errors.As(err, &invalid) {
+		p.Errors = invalid.Fields
+	}
+
+	var override *Error
+	if errors.As(err, &override) {
+		if override.Status != 0 {
+			p.Status = override.Status
+			p.Title = http.StatusText(override.Status)
+		}
+		if override.Detail != "" {
+			p.Detail = i18n.T(r.Context(), override.Detail, override.Args)
+		}
+	}
+
+	return p
+}
+
+// Write answers the request with p. The versioned API carries it in the
+// error member of its envelope, the legacy routes as a document of its own.
+func Write(w http.ResponseWriter, r *http.Request, p *Problem) {
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if envelope.Versioned(r) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(p.Status)
+
+		_ = json.NewEncoder(w).Encode(envelope.New(r, nil, p))
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// Render answers the request with the problem of err.
+func Render(w http.ResponseWriter, r *http.Request, err error) {
+	Write(w, r, FromError(r, err))
+}
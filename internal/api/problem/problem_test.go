@@ -0,0 +1,75 @@
+package problem
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"task/internal/api/validate"
+	"task/internal/domain/Errors"
+	"task/internal/i18n"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromError(t *testing.T) {
+	t.Parallel()
+
+	fields := []validate.FieldError{{Field: "amount", Rule: "gt", Message: "must be greater than 0"}}
+
+	tests := []struct {
+		name string
+		err  error
+		want Problem
+	}{
+		{
+			name: "sentinel",
+			err:  fmt.Errorf("op: %w", Errors.ErrTransactionExists),
+			want: Problem{Status: http.StatusConflict, Title: "Conflict", Code: "transaction_exists", Detail: "transaction already exists"},
+		},
+		{
+			name: "field errors",
+			err:  fmt.Errorf("op: %w", &validate.Error{Fields: fields}),
+			want: Problem{Status: http.StatusBadRequest, Title: "Bad Request", Code: CodeValidationFailed, Detail: "invalid request", Errors: fields},
+		},
+		{
+			name: "override",
+			err:  fmt.Errorf("op: %w", Wrap(fmt.Errorf("inner: %w", Errors.ErrInvalidOTP), http.StatusForbidden, "error.totp_required_for_amount", nil)),
+			want: Problem{Status: http.StatusForbidden, Title: "Forbidden", Code: "invalid_otp", Detail: "two-factor authentication must be enabled for this amount"},
+		},
+		{
+			name: "unknown",
+			err:  errors.New("pq: deadlock detected"),
+			want: Problem{Status: http.StatusInternalServerError, Title: "Internal Server Error", Code: CodeInternal, Detail: "internal error"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := FromError(httptest.NewRequest(http.MethodPost, "/transaction/deposit", nil), tc.err)
+
+			tc.want.Type = "about:blank"
+			tc.want.Instance = "/transaction/deposit"
+			require.Equal(t, &tc.want, got)
+		})
+	}
+}
+
+func TestErrorCodesHaveMessages(t *testing.T) {
+	t.Parallel()
+
+	codes := []string{CodeInternal, CodeUnauthenticated, CodeForbidden, CodeRateLimited, CodeRequestTooLarge, CodeValidationFailed}
+	for _, e := range errorCodes {
+		codes = append(codes, e.code)
+	}
+
+	for _, code := range codes {
+		for _, lang := range []string{"en", "ru"} {
+			require.NotEqual(t, "error."+code, i18n.Translate(lang, "error."+code, nil), "message of %s in %s", code, lang)
+		}
+	}
+}
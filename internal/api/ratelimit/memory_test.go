@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_Take(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+
+	policy := Policy{Name: "money", Limit: 60, Period: time.Minute, Burst: 3}
+
+	for remaining := 2; remaining >= 0; remaining-- {
+		res, err := m.Take(ctx, "account:1", policy)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+		require.Equal(t, remaining, res.Remaining)
+	}
+
+	res, err := m.Take(ctx, "account:1", policy)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Equal(t, time.Second, res.RetryAfter)
+	require.Equal(t, 3*time.Second, res.Reset)
+
+	res, err = m.Take(ctx, "account:2", policy)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+
+	now = now.Add(1500 * time.Millisecond)
+
+	res, err = m.Take(ctx, "account:1", policy)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, 0, res.Remaining)
+
+	now = now.Add(time.Hour)
+
+	res, err = m.Take(ctx, "account:1", policy)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, 2, res.Remaining)
+	require.NotContains(t, m.buckets, "account:2")
+}
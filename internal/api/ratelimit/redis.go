@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"task/common"
+)
+
+// takeScript refills and takes from a bucket atomically. It reads the
+// clock of the server so that instances with skewed clocks share buckets
+// fairly. The token count is returned as a string because Lua numbers are
+// truncated to integers on the way out.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1]) or burst
+local updated = tonumber(state[2]) or now
+
+tokens = math.min(burst, tokens + math.max(0, now - updated) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+
+return {allowed, tostring(tokens)}
+`)
+
+// Redis keeps buckets in Redis or a compatible server, shared by every
+// instance of the service.
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedis(cfg common.RateLimitConfig) (*Redis, error) {
+	const op = "api/ratelimit.NewRedis"
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Address,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Redis{
+		client: client,
+		prefix: cfg.Redis.Prefix,
+	}, nil
+}
+
+func (r *Redis) Take(ctx context.Context, key string, policy Policy) (*Result, error) {
+	const op = "api/ratelimit.Redis.Take"
+
+	values, err := takeScript.Run(ctx, r.client, []string{r.prefix + key}, policy.rate(), policy.Burst).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(values) != 2 {
+		return nil, fmt.Errorf("%s: unexpected script result %v", op, values)
+	}
+
+	allowed, _ := values[0].(int64)
+	raw, _ := values[1].(string)
+
+	tokens, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return result(policy, allowed == 1, tokens), nil
+}
+
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
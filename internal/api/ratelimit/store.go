@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"task/common"
+	"time"
+)
+
+const (
+	TypeMemory = "memory"
+	TypeRedis  = "redis"
+)
+
+// Policy is a token bucket: it holds up to Burst tokens and gains Limit
+// tokens every Period. Each request takes one.
+type Policy struct {
+	Name   string
+	Limit  int
+	Period time.Duration
+	Burst  int
+}
+
+// ReadPolicy is the budget every request draws on.
+func ReadPolicy(cfg common.RateLimitConfig) Policy {
+	return Policy{
+		Name:   "read",
+		Limit:  cfg.ReadLimit,
+		Period: cfg.Period,
+		Burst:  cfg.ReadBurst,
+	}
+}
+
+// MoneyPolicy is the budget that deposits, withdrawals and settlements draw
+// on as well.
+func MoneyPolicy(cfg common.RateLimitConfig) Policy {
+	return Policy{
+		Name:   "money",
+		Limit:  cfg.MoneyLimit,
+		Period: cfg.Period,
+		Burst:  cfg.MoneyBurst,
+	}
+}
+
+// rate is the refill rate in tokens per second.
+func (p Policy) rate() float64 {
+	return float64(p.Limit) / p.Period.Seconds()
+}
+
+// Result is the state of a bucket after a request.
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next token when the request was
+	// denied.
+	RetryAfter time.Duration
+}
+
+type Store interface {
+	Take(ctx context.Context, key string, policy Policy) (*Result, error)
+	Close() error
+}
+
+func New(cfg common.RateLimitConfig) (Store, error) {
+	const op = "api/ratelimit.New"
+
+	switch cfg.Store {
+	case TypeMemory:
+		return NewMemory(), nil
+	case TypeRedis:
+		redis, err := NewRedis(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		return redis, nil
+	default:
+		return nil, fmt.Errorf("%s: unknown store %q", op, cfg.Store)
+	}
+}
+
+// result describes a bucket left with tokens.
+func result(policy Policy, allowed bool, tokens float64) *Result {
+	rate := policy.rate()
+
+	res := &Result{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(policy.Burst) - tokens) / rate),
+	}
+	if !allowed {
+		res.RetryAfter = seconds((1 - tokens) / rate)
+	}
+
+	return res
+}
+
+func seconds(s float64) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+
+	return time.Duration(s * float64(time.Second))
+}
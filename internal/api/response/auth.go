@@ -0,0 +1,17 @@
+package response
+
+import (
+	"net/http"
+	"task/internal/api/problem"
+)
+
+// Unauthorized answers a request without valid credentials.
+func Unauthorized(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="task"`)
+	problem.Write(w, r, problem.New(r, http.StatusUnauthorized, problem.CodeUnauthenticated))
+}
+
+// Forbidden answers a request whose caller may not touch the resource.
+func Forbidden(w http.ResponseWriter, r *http.Request) {
+	problem.Write(w, r, problem.New(r, http.StatusForbidden, problem.CodeForbidden))
+}
@@ -0,0 +1,11 @@
+package response
+
+import (
+	"net/http"
+	"task/internal/api/problem"
+)
+
+// TooManyRequests answers a request over the rate limit of its caller.
+func TooManyRequests(w http.ResponseWriter, r *http.Request) {
+	problem.Write(w, r, problem.New(r, http.StatusTooManyRequests, problem.CodeRateLimited))
+}
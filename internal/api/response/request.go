@@ -0,0 +1,11 @@
+package response
+
+import (
+	"net/http"
+	"task/internal/api/problem"
+)
+
+// RequestTooLarge answers a request whose body is over the size limit.
+func RequestTooLarge(w http.ResponseWriter, r *http.Request) {
+	problem.Write(w, r, problem.New(r, http.StatusRequestEntityTooLarge, problem.CodeRequestTooLarge))
+}
@@ -1,5 +1,11 @@
 package response
 
+import (
+	"github.com/go-chi/render"
+	"net/http"
+	"task/internal/api/envelope"
+)
+
 type Response struct {
 	Status string `json:"status"`
 	Error  string `json:"error,omitempty"`
@@ -9,4 +15,18 @@ const (
 	StatusSuccess = "success"
 	StatusError   = "error"
 	StatusCreated = "created"
+	// StatusReversed marks a settled transaction whose effect on the
+	// balance has been undone.
+	StatusReversed = "reversed"
 )
+
+// Render answers the versioned API with data in the envelope and the
+// legacy routes with the body they have always had.
+func Render(w http.ResponseWriter, r *http.Request, data any, legacy any) {
+	if !envelope.Versioned(r) {
+		render.JSON(w, r, legacy)
+		return
+	}
+
+	render.JSON(w, r, envelope.New(r, data, nil))
+}
@@ -0,0 +1,98 @@
+package rpc
+
+import (
+	"context"
+	"task/common"
+	"task/internal/api/rpc/pb"
+	"task/internal/domain/Errors"
+	"task/internal/domain/account/entity"
+	"task/internal/domain/account/service"
+	rbac "task/internal/domain/rbac/entity"
+)
+
+type accountServer struct {
+	pb.UnimplementedAccountServiceServer
+	service *service.Service
+}
+
+func (s *accountServer) RegisterAccount(ctx context.Context, req *pb.RegisterAccountRequest) (*pb.Account, error) {
+	// Registration is public; only an operator sets a balance.
+	if req.GetBalance() != 0 {
+		return nil, toStatus(Errors.ErrInvalidBalance)
+	}
+
+	if err := validateCurrency(req.GetCurrency()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	account := entity.NewAccount(req.GetId(), req.GetCurrency(), req.GetPassword(), req.GetEmail())
+
+	if _, err := s.service.SaveAccount(ctx, account); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toAccount(account), nil
+}
+
+func (s *accountServer) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.Account, error) {
+	if err := authorize(ctx, rbac.PermAccountsRead, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	account, err := s.service.GetAccount(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toAccount(account), nil
+}
+
+func (s *accountServer) UpdateBalance(ctx context.Context, req *pb.UpdateBalanceRequest) (*pb.Account, error) {
+	if err := authorize(ctx, rbac.PermAccountsWrite, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := validateCurrency(req.GetCurrency()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := s.service.UpdateBalance(ctx, req.GetId(), req.GetBalance(), req.GetCurrency()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	account, err := s.service.GetAccount(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toAccount(account), nil
+}
+
+func (s *accountServer) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	if err := authorize(ctx, rbac.PermAccountsDelete, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := s.service.DeleteAccount(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.DeleteAccountResponse{}, nil
+}
+
+func toAccount(account *entity.Account) *pb.Account {
+	return &pb.Account{
+		Id:       account.ID,
+		Currency: account.Currency,
+		Balance:  account.Balance,
+		Email:    account.Email,
+	}
+}
+
+func validateCurrency(currency string) error {
+	if _, err := common.ValidateCurrency(currency, currency, 0); err != nil {
+		return Errors.ErrInvalidCurrency
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"net"
+	"strings"
+	"task/internal/api/rpc/pb"
+	"task/internal/domain/Errors"
+	"task/internal/domain/auth/entity"
+	rbac "task/internal/domain/rbac/entity"
+)
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string, clientIP string) (*entity.Principal, error)
+}
+
+// publicMethods can be called without an access token.
+var publicMethods = map[string]bool{
+	pb.AccountService_RegisterAccount_FullMethodName: true,
+}
+
+// authenticate resolves the caller from the "authorization: Bearer <token>"
+// or "x-api-key" metadata, mirroring the HTTP middleware. A one-time code
+// for large withdrawals goes in "x-otp".
+func authenticate(authenticator Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		var raw string
+		if values := md.Get("x-api-key"); len(values) > 0 {
+			raw = values[0]
+		} else if values := md.Get("authorization"); len(values) > 0 {
+			scheme, value, ok := strings.Cut(values[0], " ")
+			if ok && strings.EqualFold(scheme, "Bearer") {
+				raw = strings.TrimSpace(value)
+			}
+		}
+		if raw == "" {
+			return nil, toStatus(Errors.ErrUnauthenticated)
+		}
+
+		principal, err := authenticator.Authenticate(ctx, raw, peerIP(ctx))
+		if err != nil {
+			return nil, toStatus(Errors.ErrUnauthenticated)
+		}
+
+		// Request signing is defined over HTTP requests only, so keys that
+		// require it cannot be used here.
+		if principal.SignatureRequired {
+			return nil, toStatus(Errors.ErrInvalidSignature)
+		}
+
+		ctx = entity.WithPrincipal(ctx, principal)
+		if values := md.Get("x-otp"); len(values) > 0 {
+			ctx = entity.WithOTP(ctx, values[0])
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// peerIP is the address of the caller, or empty when it has none.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return ""
+	}
+
+	return host
+}
+
+// authorize fails unless the caller has the permission and may act on the
+// account.
+func authorize(ctx context.Context, permission string, accountID uint64) error {
+	principal, ok := entity.PrincipalFromContext(ctx)
+	if !ok {
+		return Errors.ErrUnauthenticated
+	}
+
+	if !principal.Can(permission) || !principal.CanAccess(accountID, rbac.PermAccountsAny) {
+		return Errors.ErrForbidden
+	}
+
+	return nil
+}
+
+// authorizeLookup authorizes against the owner of a resource. Missing
+// resources are reported as forbidden, like over HTTP.
+func authorizeLookup(ctx context.Context, permission string, notFound error, owner func() (uint64, error)) error {
+	accountID, err := owner()
+	if errors.Is(err, notFound) {
+		return Errors.ErrForbidden
+	}
+	if err != nil {
+		return err
+	}
+
+	return authorize(ctx, permission, accountID)
+}
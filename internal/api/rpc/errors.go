@@ -0,0 +1,60 @@
+package rpc
+
+import (
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"task/internal/domain/Errors"
+)
+
+var errorCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{Errors.ErrAccountNotFound, codes.NotFound},
+	{Errors.ErrTransactionNotFound, codes.NotFound},
+	{Errors.ErrWebhookNotFound, codes.NotFound},
+	{Errors.ErrDeliveryNotFound, codes.NotFound},
+	{Errors.ErrAccountExists, codes.AlreadyExists},
+	{Errors.ErrTransactionExists, codes.AlreadyExists},
+	{Errors.ErrNotPending, codes.FailedPrecondition},
+	{Errors.ErrNotSettled, codes.FailedPrecondition},
+	{Errors.ErrNegativeBalance, codes.FailedPrecondition},
+	{Errors.ErrZeroBalance, codes.FailedPrecondition},
+	{Errors.ErrInvalidCurrency, codes.InvalidArgument},
+	{Errors.ErrInvalidAmount, codes.InvalidArgument},
+	{Errors.ErrInvalidBalance, codes.InvalidArgument},
+	{Errors.ErrInvalidEmail, codes.InvalidArgument},
+	{Errors.ErrIncorrectID, codes.InvalidArgument},
+	{Errors.ErrInvalidWebhookURL, codes.InvalidArgument},
+	{Errors.ErrWeakPassword, codes.InvalidArgument},
+	{Errors.ErrUnauthenticated, codes.Unauthenticated},
+	{Errors.ErrForbidden, codes.PermissionDenied},
+	{Errors.ErrRoleNotFound, codes.NotFound},
+	{Errors.ErrPermissionNotFound, codes.NotFound},
+	{Errors.ErrRoleExists, codes.AlreadyExists},
+	{Errors.ErrInvalidRoleName, codes.InvalidArgument},
+	{Errors.ErrBuiltinRole, codes.FailedPrecondition},
+	{Errors.ErrPolicyViolation, codes.FailedPrecondition},
+	{Errors.ErrAPIKeyNotFound, codes.NotFound},
+	{Errors.ErrInvalidAPIKey, codes.InvalidArgument},
+	{Errors.ErrInvalidSignature, codes.Unauthenticated},
+	{Errors.ErrReplayedRequest, codes.Unauthenticated},
+	{Errors.ErrStepUpRequired, codes.PermissionDenied},
+	{Errors.ErrInvalidOTP, codes.PermissionDenied},
+	{Errors.ErrTOTPNotEnabled, codes.FailedPrecondition},
+	{Errors.ErrInvalidIPAllowlist, codes.InvalidArgument},
+	{Errors.ErrSessionNotFound, codes.NotFound},
+}
+
+// toStatus maps sentinel errors from the Errors package to gRPC status codes.
+// Anything else is reported as Internal without leaking its details.
+func toStatus(err error) error {
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			return status.Error(e.code, e.err.Error())
+		}
+	}
+
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"task/internal/domain/Errors"
+	"task/internal/domain/auth/password"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatus(t *testing.T) {
+	cases := []struct {
+		name    string
+		err     error
+		code    codes.Code
+		message string
+	}{
+		{name: "Not found", err: fmt.Errorf("op: %w", Errors.ErrAccountNotFound), code: codes.NotFound, message: "account not found"},
+		{name: "Exists", err: fmt.Errorf("op: %w", Errors.ErrTransactionExists), code: codes.AlreadyExists, message: "transaction already exists"},
+		{name: "Precondition", err: fmt.Errorf("op: %w", Errors.ErrNegativeBalance), code: codes.FailedPrecondition, message: "negative balance"},
+		{name: "Invalid argument", err: fmt.Errorf("op: %w", Errors.ErrInvalidCurrency), code: codes.InvalidArgument, message: "invalid currency"},
+		{name: "Weak password", err: fmt.Errorf("op: %w", password.Validate("short")), code: codes.InvalidArgument, message: "password does not meet the policy"},
+		{name: "Unauthenticated", err: Errors.ErrUnauthenticated, code: codes.Unauthenticated, message: "unauthenticated"},
+		{name: "Forbidden", err: Errors.ErrForbidden, code: codes.PermissionDenied, message: "forbidden"},
+		{name: "Step-up", err: fmt.Errorf("op: %w", Errors.ErrStepUpRequired), code: codes.PermissionDenied, message: "one-time code required"},
+		{name: "Unknown", err: errors.New("pq: password authentication failed"), code: codes.Internal, message: "internal error"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			st, ok := status.FromError(toStatus(tc.err))
+			require.True(t, ok)
+			require.Equal(t, tc.code, st.Code())
+			require.Equal(t, tc.message, st.Message())
+		})
+	}
+}
+
+func TestErrorCodes_Distinct(t *testing.T) {
+	seen := make(map[error]bool)
+	for _, e := range errorCodes {
+		require.False(t, seen[e.err], "%v is mapped twice", e.err)
+		seen[e.err] = true
+	}
+}
@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"math"
+	"strconv"
+	"task/common"
+	mw "task/internal/api/middleware"
+	"task/internal/api/ratelimit"
+	"task/internal/api/rpc/pb"
+)
+
+// moneyMethods draw on the money budget as well, like the HTTP routes that
+// move money.
+var moneyMethods = map[string]bool{
+	pb.TransactionService_Deposit_FullMethodName:           true,
+	pb.TransactionService_Withdraw_FullMethodName:          true,
+	pb.TransactionService_SettleTransaction_FullMethodName: true,
+}
+
+// rateLimit takes a token from the caller's read bucket for every call, and
+// from the money bucket for moneyMethods. Callers are told apart the way
+// the HTTP API does, so it goes after authenticate, and a caller over the
+// limit gets ResourceExhausted with a retry-after header. The store failing
+// lets calls through.
+func rateLimit(limiter mw.Limiter, read ratelimit.Policy, money ratelimit.Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		key := mw.RateLimitKey(ctx, peerIP(ctx))
+
+		policies := []ratelimit.Policy{read}
+		if moneyMethods[info.FullMethod] {
+			policies = append(policies, money)
+		}
+
+		for _, policy := range policies {
+			res, err := limiter.Take(ctx, policy.Name+":"+key, policy)
+			if err != nil {
+				common.FromContext(ctx).Error("cannot check rate limit", "policy", policy.Name, "error", err.Error())
+				continue
+			}
+
+			if !res.Allowed {
+				retryAfter := strconv.FormatInt(int64(math.Ceil(res.RetryAfter.Seconds())), 10)
+				grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfter)) //nolint:errcheck
+				return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
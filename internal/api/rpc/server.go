@@ -0,0 +1,140 @@
+package rpc
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"runtime/debug"
+	"strings"
+	"task/common"
+	mw "task/internal/api/middleware"
+	"task/internal/api/ratelimit"
+	"task/internal/api/rpc/pb"
+	accService "task/internal/domain/account/service"
+	transService "task/internal/domain/transaction/service"
+	"time"
+)
+
+// NewServer builds the gRPC server on top of the same service instances and
+// rate limit store the HTTP handlers use.
+func NewServer(
+	logger *slog.Logger,
+	accountService *accService.Service,
+	transactionService *transService.Service,
+	authenticator Authenticator,
+	limiter mw.Limiter,
+	limits common.RateLimitConfig,
+) *grpc.Server {
+	interceptors := []grpc.UnaryServerInterceptor{
+		recoverer,
+		tracing,
+		accessLog(logger),
+		authenticate(authenticator),
+	}
+	if limits.Enabled {
+		interceptors = append(interceptors, rateLimit(limiter, ratelimit.ReadPolicy(limits), ratelimit.MoneyPolicy(limits)))
+	}
+
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+
+	pb.RegisterAccountServiceServer(srv, &accountServer{service: accountService})
+	pb.RegisterTransactionServiceServer(srv, &transactionServer{service: transactionService})
+	reflection.Register(srv)
+
+	return srv
+}
+
+func recoverer(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if rvr := recover(); rvr != nil {
+			common.FromContext(ctx).Error("panic in grpc handler",
+				slog.String("method", info.FullMethod),
+				slog.Any("panic", rvr),
+				slog.String("stack", string(debug.Stack())),
+			)
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+// tracing starts a server span for every call, continuing the trace
+// propagated in the incoming metadata.
+func tracing(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+	ctx, span := common.StartSpan(ctx, strings.TrimPrefix(info.FullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(semconv.RPCSystemGRPC),
+	)
+	defer span.End()
+
+	resp, err := handler(ctx, req)
+
+	st, _ := status.FromError(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(st.Code())))
+	if err != nil {
+		span.SetStatus(otelcodes.Error, st.Message())
+	}
+
+	return resp, err
+}
+
+// metadataCarrier adapts incoming metadata to the propagators.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// accessLog injects logger into the context and logs every call, the way
+// common.AccessHandler does for HTTP.
+func accessLog(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		ctx = common.WithContext(ctx, logger)
+
+		resp, err := handler(ctx, req)
+
+		st, _ := status.FromError(err)
+		attrs := []any{
+			slog.String("method", info.FullMethod),
+			slog.String("code", st.Code().String()),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+
+		logger.InfoCtx(ctx, "rpc processed", attrs...)
+
+		return resp, err
+	}
+}
@@ -0,0 +1,361 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"strings"
+	"task/common"
+	"task/internal/api/ratelimit"
+	"task/internal/api/rpc/pb"
+	"task/internal/domain/Errors"
+	accService "task/internal/domain/account/service"
+	"task/internal/domain/auth/entity"
+	rbac "task/internal/domain/rbac/entity"
+	transService "task/internal/domain/transaction/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// principals authenticates the tokens used by the tests.
+type principals map[string]*entity.Principal
+
+func (p principals) Authenticate(_ context.Context, credential string, _ string) (*entity.Principal, error) {
+	principal, ok := p[credential]
+	if !ok {
+		return nil, Errors.ErrInvalidToken
+	}
+
+	return principal, nil
+}
+
+var testPrincipals = principals{
+	"owner": {
+		AccountID: 1,
+		Permissions: map[string]bool{
+			rbac.PermAccountsRead:      true,
+			rbac.PermAccountsWrite:     true,
+			rbac.PermTransactionsRead:  true,
+			rbac.PermTransactionsWrite: true,
+		},
+	},
+	"reader": {
+		AccountID: 1,
+		Permissions: map[string]bool{
+			rbac.PermAccountsRead:     true,
+			rbac.PermTransactionsRead: true,
+		},
+	},
+	"admin": {
+		AccountID: 99,
+		Permissions: map[string]bool{
+			rbac.PermAccountsAny:       true,
+			rbac.PermAccountsWrite:     true,
+			rbac.PermTransactionsWrite: true,
+		},
+	},
+	"signing key": {
+		AccountID:         1,
+		SignatureRequired: true,
+		Permissions: map[string]bool{
+			rbac.PermTransactionsWrite: true,
+		},
+	},
+}
+
+// dial serves the API over an in-memory listener without rate limits.
+func dial(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+
+	return dialLimited(t, common.RateLimitConfig{})
+}
+
+// dialLimited serves the API over an in-memory listener. The services have
+// no database, so calls are expected to stop before the repositories.
+func dialLimited(t *testing.T, limits common.RateLimitConfig) *grpc.ClientConn {
+	t.Helper()
+
+	di := &common.DependencyContainer{Config: &common.Config{}}
+	srv := NewServer(
+		slog.New(common.NewDiscardHandler()),
+		accService.NewService(di, nil),
+		transService.NewService(di, nil),
+		testPrincipals,
+		ratelimit.NewMemory(),
+		limits,
+	)
+
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis) //nolint:errcheck
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestTransactionServer_RejectsInvalidAmounts(t *testing.T) {
+	client := pb.NewTransactionServiceClient(dial(t))
+
+	cases := []struct {
+		name   string
+		amount float64
+	}{
+		{name: "Negative", amount: -100},
+		{name: "Zero", amount: 0},
+		{name: "Too large", amount: 2_000_000_000},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			req := &pb.CreateTransactionRequest{Id: 1, AccountId: 1, Amount: tc.amount, Currency: "USD", ToAccount: 2}
+
+			_, err := client.Deposit(withToken("owner"), req)
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+			_, err = client.Withdraw(withToken("owner"), req)
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestAccountServer_RejectsInvalidAccounts(t *testing.T) {
+	client := pb.NewAccountServiceClient(dial(t))
+
+	cases := []struct {
+		name   string
+		modify func(req *pb.RegisterAccountRequest)
+	}{
+		{name: "Weak password", modify: func(req *pb.RegisterAccountRequest) { req.Password = "short" }},
+		{name: "Starting balance", modify: func(req *pb.RegisterAccountRequest) { req.Balance = 1_000_000 }},
+		{name: "Zero id", modify: func(req *pb.RegisterAccountRequest) { req.Id = 0 }},
+		{name: "Invalid email", modify: func(req *pb.RegisterAccountRequest) { req.Email = "owner" }},
+		{name: "Email with a display name", modify: func(req *pb.RegisterAccountRequest) { req.Email = "Owner <owner@example.com>" }},
+		{name: "Overlong email", modify: func(req *pb.RegisterAccountRequest) { req.Email = strings.Repeat("o", 250) + "@example.com" }},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			req := &pb.RegisterAccountRequest{
+				Id:       1,
+				Currency: "USD",
+				Password: "correct horse battery",
+				Email:    "owner@example.com",
+			}
+			tc.modify(req)
+
+			_, err := client.RegisterAccount(context.Background(), req)
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestAccountServer_RejectsInvalidBalances(t *testing.T) {
+	client := pb.NewAccountServiceClient(dial(t))
+
+	cases := []struct {
+		name    string
+		token   string
+		id      uint64
+		balance float64
+	}{
+		{name: "Negative", token: "owner", id: 1, balance: -100},
+		{name: "NaN", token: "owner", id: 1, balance: math.NaN()},
+		{name: "Infinite", token: "owner", id: 1, balance: math.Inf(1)},
+		{name: "Too large", token: "owner", id: 1, balance: 1e300},
+		{name: "Zero id", token: "admin", id: 0, balance: 10},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := client.UpdateBalance(withToken(tc.token), &pb.UpdateBalanceRequest{Id: tc.id, Balance: tc.balance, Currency: "USD"})
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+// TestServer_Authorization stops allowed calls at the currency check of the
+// services, before any repository, so that InvalidArgument means the call
+// got past authentication and authorization.
+func TestServer_Authorization(t *testing.T) {
+	conn := dial(t)
+	accounts := pb.NewAccountServiceClient(conn)
+	transactions := pb.NewTransactionServiceClient(conn)
+
+	deposit := func(ctx context.Context, accountID uint64) error {
+		_, err := transactions.Deposit(ctx, &pb.CreateTransactionRequest{Id: 1, AccountId: accountID, Amount: 10, Currency: "XXX"})
+		return err
+	}
+	updateBalance := func(ctx context.Context, accountID uint64) error {
+		_, err := accounts.UpdateBalance(ctx, &pb.UpdateBalanceRequest{Id: accountID, Balance: 10, Currency: "XXX"})
+		return err
+	}
+
+	cases := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{
+			name: "No token",
+			call: func() error { return deposit(context.Background(), 1) },
+			want: codes.Unauthenticated,
+		},
+		{
+			name: "Unknown token",
+			call: func() error { return deposit(withToken("stolen"), 1) },
+			want: codes.Unauthenticated,
+		},
+		{
+			name: "Key that must sign requests",
+			call: func() error { return deposit(withToken("signing key"), 1) },
+			want: codes.Unauthenticated,
+		},
+		{
+			name: "Wrong owner",
+			call: func() error { return deposit(withToken("owner"), 2) },
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "Wrong owner of an account",
+			call: func() error { return updateBalance(withToken("owner"), 2) },
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "Missing permission",
+			call: func() error { return deposit(withToken("reader"), 1) },
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "Owner",
+			call: func() error { return deposit(withToken("owner"), 1) },
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "Owner of an account",
+			call: func() error { return updateBalance(withToken("owner"), 1) },
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "Any account",
+			call: func() error { return deposit(withToken("admin"), 2) },
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "Public method",
+			call: func() error {
+				_, err := accounts.RegisterAccount(context.Background(), &pb.RegisterAccountRequest{Id: 1, Currency: "XXX"})
+				return err
+			},
+			want: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, status.Code(tc.call()))
+		})
+	}
+}
+
+// TestServer_RateLimit stops allowed calls at the currency check of the
+// services, like TestServer_Authorization.
+func TestServer_RateLimit(t *testing.T) {
+	conn := dialLimited(t, common.RateLimitConfig{
+		Enabled:    true,
+		Period:     time.Minute,
+		ReadLimit:  60,
+		ReadBurst:  3,
+		MoneyLimit: 6,
+		MoneyBurst: 1,
+	})
+	accounts := pb.NewAccountServiceClient(conn)
+	transactions := pb.NewTransactionServiceClient(conn)
+
+	deposit := func(token string) (metadata.MD, error) {
+		var header metadata.MD
+		_, err := transactions.Deposit(withToken(token), &pb.CreateTransactionRequest{Id: 1, AccountId: 1, Amount: 10, Currency: "XXX"}, grpc.Header(&header))
+		return header, err
+	}
+	updateBalance := func(token string) error {
+		_, err := accounts.UpdateBalance(withToken(token), &pb.UpdateBalanceRequest{Id: 1, Balance: 10, Currency: "XXX"})
+		return err
+	}
+
+	// The money budget runs out first.
+	_, err := deposit("owner")
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	header, err := deposit("owner")
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.Equal(t, []string{"10"}, header.Get("retry-after"))
+
+	// Both deposits took a read token; the third one is the last.
+	require.Equal(t, codes.InvalidArgument, status.Code(updateBalance("owner")))
+	require.Equal(t, codes.ResourceExhausted, status.Code(updateBalance("owner")))
+
+	// Other callers have buckets of their own.
+	require.Equal(t, codes.InvalidArgument, status.Code(updateBalance("admin")))
+}
+
+func TestAuthorizeLookup(t *testing.T) {
+	ctx := entity.WithPrincipal(context.Background(), testPrincipals["owner"])
+	unavailable := errors.New("connection refused")
+
+	cases := []struct {
+		name    string
+		owner   uint64
+		err     error
+		wantErr error
+	}{
+		{name: "Owner", owner: 1},
+		{name: "Other owner", owner: 2, wantErr: Errors.ErrForbidden},
+		{name: "Missing", err: Errors.ErrTransactionNotFound, wantErr: Errors.ErrForbidden},
+		{name: "Lookup failed", err: unavailable, wantErr: unavailable},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := authorizeLookup(ctx, rbac.PermTransactionsRead, Errors.ErrTransactionNotFound, func() (uint64, error) {
+				return tc.owner, tc.err
+			})
+
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"task/internal/api/rpc/pb"
+	"task/internal/domain/Errors"
+	rbac "task/internal/domain/rbac/entity"
+	"task/internal/domain/transaction/entity"
+	"task/internal/domain/transaction/service"
+)
+
+type transactionServer struct {
+	pb.UnimplementedTransactionServiceServer
+	service *service.Service
+}
+
+func (s *transactionServer) Deposit(ctx context.Context, req *pb.CreateTransactionRequest) (*pb.Transaction, error) {
+	if err := authorize(ctx, rbac.PermTransactionsWrite, req.GetAccountId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	transaction, err := s.service.CreateDepositTransaction(ctx, fromCreateRequest(req))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toTransaction(transaction), nil
+}
+
+func (s *transactionServer) Withdraw(ctx context.Context, req *pb.CreateTransactionRequest) (*pb.Transaction, error) {
+	if err := authorize(ctx, rbac.PermTransactionsWrite, req.GetAccountId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	transaction, err := s.service.CreateWithdrawTransaction(ctx, fromCreateRequest(req))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toTransaction(transaction), nil
+}
+
+func (s *transactionServer) GetTransaction(ctx context.Context, req *pb.GetTransactionRequest) (*pb.Transaction, error) {
+	transaction, err := s.service.GetTransactionByID(ctx, req.GetId())
+	if errors.Is(err, Errors.ErrTransactionNotFound) {
+		return nil, toStatus(Errors.ErrForbidden)
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := authorize(ctx, rbac.PermTransactionsRead, transaction.AccountID); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toTransaction(transaction), nil
+}
+
+func (s *transactionServer) SettleTransaction(ctx context.Context, req *pb.SettleTransactionRequest) (*pb.SettleTransactionResponse, error) {
+	if err := s.authorizeTransaction(ctx, rbac.PermTransactionsSettle, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := s.service.UpdateTransactionStatus(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.SettleTransactionResponse{}, nil
+}
+
+func (s *transactionServer) DeleteTransaction(ctx context.Context, req *pb.DeleteTransactionRequest) (*pb.DeleteTransactionResponse, error) {
+	if err := s.authorizeTransaction(ctx, rbac.PermTransactionsDelete, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := s.service.DeleteTransactionByID(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.DeleteTransactionResponse{}, nil
+}
+
+func (s *transactionServer) GetFrozenBalance(ctx context.Context, req *pb.GetFrozenBalanceRequest) (*pb.FrozenBalance, error) {
+	if err := authorize(ctx, rbac.PermTransactionsRead, req.GetAccountId()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	frozen, err := s.service.GetFrozenBalanceByAccountID(ctx, req.GetAccountId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.FrozenBalance{
+		AccountId: frozen.ID,
+		Currency:  frozen.Currency,
+		Balance:   frozen.Balance,
+	}, nil
+}
+
+func (s *transactionServer) authorizeTransaction(ctx context.Context, permission string, id uint64) error {
+	return authorizeLookup(ctx, permission, Errors.ErrTransactionNotFound, func() (uint64, error) {
+		transaction, err := s.service.GetTransactionByID(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		return transaction.AccountID, nil
+	})
+}
+
+func fromCreateRequest(req *pb.CreateTransactionRequest) *entity.Transaction {
+	return &entity.Transaction{
+		ID:        req.GetId(),
+		AccountID: req.GetAccountId(),
+		Amount:    req.GetAmount(),
+		Currency:  req.GetCurrency(),
+		ToAccount: req.GetToAccount(),
+	}
+}
+
+func toTransaction(transaction *entity.Transaction) *pb.Transaction {
+	return &pb.Transaction{
+		Id:        transaction.ID,
+		Status:    transaction.Status,
+		AccountId: transaction.AccountID,
+		Amount:    transaction.Amount,
+		Currency:  transaction.Currency,
+		ToAccount: transaction.ToAccount,
+	}
+}
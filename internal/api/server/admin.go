@@ -0,0 +1,24 @@
+package server
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"net/http"
+	"task/internal/health"
+	"task/internal/metrics"
+)
+
+// GetAdminHandler serves the operational endpoints of the admin listener.
+// It carries no authentication, so the listener must be reachable by the
+// orchestrator and the monitoring at most, never from outside.
+func GetAdminHandler(probes *health.Health) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(middleware.Recoverer)
+
+	r.Method(http.MethodGet, "/metrics", metrics.Handler())
+	r.Get("/healthz", probes.Liveness)
+	r.Get("/readyz", probes.Readiness)
+
+	return r
+}
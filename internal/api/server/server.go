@@ -1,43 +1,124 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"task/common"
-	"task/internal/api/response"
+	"task/internal/api/envelope"
+	mw "task/internal/api/middleware"
+	"task/internal/api/openapi"
+	"task/internal/api/problem"
+	"task/internal/api/ratelimit"
+	"task/internal/domain/Errors"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/exp/slog"
 	acc "task/internal/domain/account/controller/handler"
+	accService "task/internal/domain/account/service"
+	key "task/internal/domain/apikey/controller/handler"
+	keyService "task/internal/domain/apikey/service"
+	auth "task/internal/domain/auth/controller/handler"
+	authService "task/internal/domain/auth/service"
+	access "task/internal/domain/rbac/controller/handler"
+	rbac "task/internal/domain/rbac/entity"
+	accessService "task/internal/domain/rbac/service"
+	live "task/internal/domain/stream/controller/handler"
+	stream "task/internal/domain/stream/service"
 	trans "task/internal/domain/transaction/controller/handler"
+	transService "task/internal/domain/transaction/service"
+	hook "task/internal/domain/webhook/controller/handler"
+	hookService "task/internal/domain/webhook/service"
 )
 
 const JSONContentType = "application/json"
 
 type Server struct {
 	account     *acc.Handlers
+	auth        *auth.Handlers
 	transaction *trans.Handlers
+	webhook     *hook.Handlers
+	stream      *live.Handlers
+	rbac        *access.Handlers
+	apiKey      *key.Handlers
+
+	authenticator mw.Authenticator
+	verifier      mw.SignatureVerifier
+	transactions  *transService.Service
+	webhooks      *hookService.Service
+
+	maxBodyBytes int64
+	legacySunset time.Time
+
+	limiter    mw.Limiter
+	readLimit  ratelimit.Policy
+	moneyLimit ratelimit.Policy
 }
 
-func NewServer(di *common.DependencyContainer) *Server {
+func NewServer(
+	di *common.DependencyContainer,
+	accountService *accService.Service,
+	transactionService *transService.Service,
+	authService *authService.Service,
+	broadcaster *stream.Broadcaster,
+	limiter mw.Limiter,
+) *Server {
+	webhookService := hookService.NewService(di)
+	apiKeyService := keyService.NewService(di)
+
+	limits := di.Config.RateLimit
+	if !limits.Enabled {
+		limiter = nil
+	}
+
 	return &Server{
-		account:     acc.NewHandlers(di),
-		transaction: trans.NewHandlers(di),
+		account:     acc.NewHandlers(accountService),
+		auth:        auth.NewHandlers(authService),
+		transaction: trans.NewHandlers(transactionService),
+		webhook:     hook.NewHandlers(webhookService),
+		stream:      live.NewHandlers(di, broadcaster),
+		rbac:        access.NewHandlers(accessService.NewService(di)),
+		apiKey:      key.NewHandlers(apiKeyService),
+
+		authenticator: authService,
+		verifier:      apiKeyService,
+		transactions:  transactionService,
+		webhooks:      webhookService,
+
+		maxBodyBytes: di.Config.MaxBodyBytes,
+		legacySunset: di.Config.LegacySunset,
+
+		limiter:    limiter,
+		readLimit:  ratelimit.ReadPolicy(limits),
+		moneyLimit: ratelimit.MoneyPolicy(limits),
 	}
 }
 
 func (s *Server) GetHTTPHandler(logger *slog.Logger) (http.Handler, error) {
 
+	spec, err := openapi.Load()
+	if err != nil {
+		return nil, err
+	}
+
 	r := chi.NewRouter()
+	successors := make(map[string]string)
 
 	r.Use(
+		mw.Metrics,
+		mw.Trace,
 		middleware.Recoverer,
 
 		middleware.AllowContentType(JSONContentType),
 		render.SetContentType(render.ContentTypeJSON),
 		middleware.RequestID,
+		mw.Language,
+		mw.Deprecated(r, successors, s.legacySunset),
+		mw.LimitBody(s.maxBodyBytes),
 		common.NewHandler(logger),
 		common.AccessHandler(func(r *http.Request, status, size int, duration time.Duration) {
 			requestGroup := slog.Group(
@@ -60,26 +141,186 @@ func (s *Server) GetHTTPHandler(logger *slog.Logger) (http.Handler, error) {
 	)
 
 	r.Group(func(r chi.Router) {
-		r.Post("/accounts/register", ErrorHandler(s.account.Register))
-		r.Get("/accounts/{account_id}", ErrorHandler(s.account.Get))
-		r.Patch("/accounts/{account_id}", ErrorHandler(s.account.Update))
-		r.Delete("/accounts/delete", ErrorHandler(s.account.Delete))
-
-		r.Post("/transaction/deposit", ErrorHandler(s.transaction.Deposit))
-		r.Post("/transaction/withdraw", ErrorHandler(s.transaction.Withdraw))
-		r.Get("/transaction/{transaction_id}", ErrorHandler(s.transaction.GetTransactionByID))
-		r.Patch("/transaction/{transaction_id}", ErrorHandler(s.transaction.UpdateTransactionStatus))
-		r.Delete("/transaction/{transaction_id}", ErrorHandler(s.transaction.DeleteTransactionByID))
-		r.Get("/transaction/frozen/{account_id}", ErrorHandler(s.transaction.GetFrozenBalanceByID))
+		r.Use(spec.Validator())
+
+		api := routes{Router: r, successors: successors}
+
+		api.Group(func(api routes) {
+			api.Use(s.rateLimit(s.readLimit))
+
+			api.Get("/openapi.json", spec.ServeJSON)
+			api.Get("/docs", spec.ServeDocs)
+			api.Get("/docs/{asset}", spec.ServeAsset)
+
+			api.handle(http.MethodPost, "/auth/login", "/auth/login", ErrorHandler(s.auth.Login))
+			api.handle(http.MethodPost, "/auth/refresh", "/auth/refresh", ErrorHandler(s.auth.Refresh))
+			api.handle(http.MethodPost, "/auth/email/verify", "/auth/email/verify", ErrorHandler(s.auth.VerifyEmail))
+			api.handle(http.MethodPost, "/auth/password/forgot", "/auth/password/forgot", ErrorHandler(s.auth.ForgotPassword))
+			api.handle(http.MethodPost, "/auth/password/reset", "/auth/password/reset", ErrorHandler(s.auth.ResetPassword))
+
+			api.handle(http.MethodPost, "/accounts", "/accounts/register", ErrorHandler(s.account.Register))
+		})
+
+		api.Group(func(api routes) {
+			api.Use(mw.Authenticate(s.authenticator), s.rateLimit(s.readLimit), mw.VerifySignature(s.verifier))
+
+			can := mw.RequirePermission
+			account := mw.RequireOwner(mw.URLParam("account_id"))
+			transaction := mw.RequireOwner(mw.Lookup("transaction_id", s.transactionOwner))
+			money := s.rateLimit(s.moneyLimit)
+			webhook := mw.RequireOwner(mw.Lookup("webhook_id", s.webhookOwner))
+
+			api.handle(http.MethodPost, "/auth/email/verification", "/auth/email/verification", ErrorHandler(s.auth.SendVerification))
+			api.handle(http.MethodPost, "/auth/password/change", "/auth/password/change", ErrorHandler(s.auth.ChangePassword))
+			api.handle(http.MethodGet, "/auth/sessions", "/auth/sessions", ErrorHandler(s.auth.ListSessions))
+			api.handle(http.MethodDelete, "/auth/sessions", "/auth/sessions", ErrorHandler(s.auth.RevokeSessions))
+			api.handle(http.MethodDelete, "/auth/sessions/{session_id}", "/auth/sessions/{session_id}", ErrorHandler(s.auth.RevokeSession))
+			api.handle(http.MethodPost, "/auth/2fa/totp", "/auth/2fa/totp", ErrorHandler(s.auth.EnrollTOTP))
+			api.handle(http.MethodPost, "/auth/2fa/totp/confirm", "/auth/2fa/totp/confirm", ErrorHandler(s.auth.ConfirmTOTP))
+			api.handle(http.MethodPost, "/auth/2fa/totp/disable", "/auth/2fa/totp/disable", ErrorHandler(s.auth.DisableTOTP))
+			api.handle(http.MethodPost, "/auth/2fa/recovery-codes", "/auth/2fa/recovery-codes", ErrorHandler(s.auth.RegenerateRecoveryCodes))
+
+			api.With(can(rbac.PermAccountsRead), account).handle(http.MethodGet, "/accounts/{account_id}", "/accounts/{account_id}", ErrorHandler(s.account.Get))
+			api.With(can(rbac.PermAccountsWrite), account).handle(http.MethodPatch, "/accounts/{account_id}", "/accounts/{account_id}", ErrorHandler(s.account.Update))
+			api.With(can(rbac.PermAccountsDelete), account).handle(http.MethodDelete, "/accounts/{account_id}", "", ErrorHandler(s.account.Delete))
+
+			api.With(money, can(rbac.PermTransactionsWrite), mw.RequireOwner(mw.BodyField("account_id"))).handle(http.MethodPost, "/transactions/deposits", "/transaction/deposit", ErrorHandler(s.transaction.Deposit))
+			api.With(money, can(rbac.PermTransactionsWrite), mw.RequireOwner(mw.BodyField("account_id"))).handle(http.MethodPost, "/transactions/withdrawals", "/transaction/withdraw", ErrorHandler(s.transaction.Withdraw))
+			api.With(can(rbac.PermTransactionsRead), transaction).handle(http.MethodGet, "/transactions/{transaction_id}", "/transaction/{transaction_id}", ErrorHandler(s.transaction.GetTransactionByID))
+			api.With(money, can(rbac.PermTransactionsSettle), transaction).handle(http.MethodPatch, "/transactions/{transaction_id}", "/transaction/{transaction_id}", ErrorHandler(s.transaction.UpdateTransactionStatus))
+			api.With(can(rbac.PermTransactionsDelete), transaction).handle(http.MethodDelete, "/transactions/{transaction_id}", "/transaction/{transaction_id}", ErrorHandler(s.transaction.DeleteTransactionByID))
+			api.With(can(rbac.PermTransactionsRead), account).handle(http.MethodGet, "/accounts/{account_id}/frozen-balance", "/transaction/frozen/{account_id}", ErrorHandler(s.transaction.GetFrozenBalanceByID))
+
+			api.With(can(rbac.PermWebhooksWrite), mw.RequireOwner(mw.BodyField("account_id"))).handle(http.MethodPost, "/webhooks", "/webhooks/register", ErrorHandler(s.webhook.Register))
+			api.With(can(rbac.PermWebhooksRead), account).handle(http.MethodGet, "/accounts/{account_id}/webhooks", "/accounts/{account_id}/webhooks", ErrorHandler(s.webhook.ListByAccount))
+			api.With(can(rbac.PermAccountsRead), account).handle(http.MethodGet, "/accounts/{account_id}/stream", "/accounts/{account_id}/stream", ErrorHandler(s.stream.Stream))
+			api.With(can(rbac.PermWebhooksRead), webhook).handle(http.MethodGet, "/webhooks/{webhook_id}", "/webhooks/{webhook_id}", ErrorHandler(s.webhook.Get))
+			api.With(can(rbac.PermWebhooksWrite), webhook).handle(http.MethodDelete, "/webhooks/{webhook_id}", "/webhooks/{webhook_id}", ErrorHandler(s.webhook.Delete))
+			api.With(can(rbac.PermWebhooksRead), webhook).handle(http.MethodGet, "/webhooks/{webhook_id}/deliveries", "/webhooks/{webhook_id}/deliveries", ErrorHandler(s.webhook.ListDeliveries))
+			api.With(can(rbac.PermWebhooksRead), webhook).handle(http.MethodGet, "/webhooks/{webhook_id}/deliveries/{delivery_id}/attempts", "/webhooks/{webhook_id}/deliveries/{delivery_id}/attempts", ErrorHandler(s.webhook.ListAttempts))
+			api.With(can(rbac.PermWebhooksWrite), webhook).handle(http.MethodPost, "/webhooks/{webhook_id}/deliveries/{delivery_id}/redeliver", "/webhooks/{webhook_id}/deliveries/{delivery_id}/redeliver", ErrorHandler(s.webhook.Redeliver))
+
+			api.Group(func(api routes) {
+				api.Use(can(rbac.PermRolesManage))
+
+				api.handle(http.MethodGet, "/admin/permissions", "/admin/permissions", ErrorHandler(s.rbac.ListPermissions))
+				api.handle(http.MethodGet, "/admin/roles", "/admin/roles", ErrorHandler(s.rbac.ListRoles))
+				api.handle(http.MethodPost, "/admin/roles", "/admin/roles", ErrorHandler(s.rbac.CreateRole))
+				api.handle(http.MethodGet, "/admin/roles/{role}", "/admin/roles/{role}", ErrorHandler(s.rbac.GetRole))
+				api.handle(http.MethodDelete, "/admin/roles/{role}", "/admin/roles/{role}", ErrorHandler(s.rbac.DeleteRole))
+				api.handle(http.MethodPost, "/admin/roles/{role}/permissions", "/admin/roles/{role}/permissions", ErrorHandler(s.rbac.GrantPermission))
+				api.handle(http.MethodDelete, "/admin/roles/{role}/permissions/{permission}", "/admin/roles/{role}/permissions/{permission}", ErrorHandler(s.rbac.RevokePermission))
+				api.handle(http.MethodGet, "/admin/accounts/{account_id}/roles", "/admin/accounts/{account_id}/roles", ErrorHandler(s.rbac.ListAccountRoles))
+				api.handle(http.MethodPut, "/admin/accounts/{account_id}/roles/{role}", "/admin/accounts/{account_id}/roles/{role}", ErrorHandler(s.rbac.AssignRole))
+				api.handle(http.MethodDelete, "/admin/accounts/{account_id}/roles/{role}", "/admin/accounts/{account_id}/roles/{role}", ErrorHandler(s.rbac.UnassignRole))
+			})
+
+			api.Group(func(api routes) {
+				api.Use(can(rbac.PermAPIKeysManage))
+
+				api.handle(http.MethodGet, "/admin/api-keys", "/admin/api-keys", ErrorHandler(s.apiKey.List))
+				api.handle(http.MethodPost, "/admin/api-keys", "/admin/api-keys", ErrorHandler(s.apiKey.Create))
+				api.handle(http.MethodGet, "/admin/api-keys/{key_id}", "/admin/api-keys/{key_id}", ErrorHandler(s.apiKey.Get))
+				api.handle(http.MethodDelete, "/admin/api-keys/{key_id}", "/admin/api-keys/{key_id}", ErrorHandler(s.apiKey.Revoke))
+				api.handle(http.MethodPost, "/admin/api-keys/{key_id}/rotate", "/admin/api-keys/{key_id}/rotate", ErrorHandler(s.apiKey.Rotate))
+			})
+		})
 	})
 
 	return r, nil
 }
 
+// routes registers every operation under the /v1 prefix of the versioned
+// API and, until its clients have migrated, on the legacy path it had
+// before. successors maps the legacy patterns to the versioned ones for
+// mw.Deprecated.
+type routes struct {
+	chi.Router
+	successors map[string]string
+}
+
+func (rs routes) With(middlewares ...func(http.Handler) http.Handler) routes {
+	return routes{Router: rs.Router.With(middlewares...), successors: rs.successors}
+}
+
+func (rs routes) Group(fn func(rs routes)) {
+	rs.Router.Group(func(r chi.Router) {
+		fn(routes{Router: r, successors: rs.successors})
+	})
+}
+
+func (rs routes) handle(method string, pattern string, legacy string, handler http.HandlerFunc) {
+	rs.Method(method, envelope.Prefix+pattern, handler)
+
+	if legacy != "" {
+		rs.Method(method, legacy, handler)
+		rs.successors[legacy] = envelope.Prefix + pattern
+	}
+}
+
+// rateLimit draws on the budget of the policy, or does nothing when rate
+// limiting is disabled.
+func (s *Server) rateLimit(policy ratelimit.Policy) func(http.Handler) http.Handler {
+	if s.limiter == nil {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
+	return mw.RateLimit(s.limiter, policy)
+}
+
+func (s *Server) transactionOwner(ctx context.Context, id uint64) (uint64, error) {
+	transaction, err := s.transactions.GetTransactionByID(ctx, id)
+	if errors.Is(err, Errors.ErrTransactionNotFound) {
+		return 0, Errors.ErrForbidden
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return transaction.AccountID, nil
+}
+
+func (s *Server) webhookOwner(ctx context.Context, id uint64) (uint64, error) {
+	subscription, err := s.webhooks.GetSubscription(ctx, id)
+	if errors.Is(err, Errors.ErrWebhookNotFound) {
+		return 0, Errors.ErrForbidden
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return subscription.AccountID, nil
+}
+
+// ErrorHandler answers the errors of a handler with a problem document,
+// see package problem. A handler that has started its response already,
+// like a stream, keeps it; the error is only logged.
 func ErrorHandler(handler func(w http.ResponseWriter, r *http.Request) error) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if err := handler(w, r); err != nil {
-			render.JSON(w, r, response.Response{Error: "Internal server error in handlers func", Status: "error"})
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		err := handler(ww, r)
+		if err == nil {
+			return
+		}
+
+		logger := common.FromRequest(r)
+
+		if ww.Status() != 0 {
+			logger.WarnCtx(r.Context(), "handler failed after responding", slog.String("error", err.Error()))
+			return
 		}
+
+		p := problem.FromError(r, err)
+		if p.Status >= http.StatusInternalServerError {
+			trace.SpanFromContext(r.Context()).RecordError(err)
+			logger.ErrorCtx(r.Context(), "handler failed", slog.String("error", err.Error()))
+		} else {
+			logger.DebugCtx(r.Context(), "request rejected", slog.String("code", p.Code), slog.String("error", err.Error()))
+		}
+
+		problem.Write(w, r, p)
 	}
 }
@@ -0,0 +1,668 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task/common"
+	"task/internal/api/envelope"
+	"task/internal/api/openapi"
+	"task/internal/api/problem"
+	"task/internal/api/ratelimit"
+	"task/internal/api/validate"
+	"task/internal/domain/Errors"
+	apikey "task/internal/domain/apikey/entity"
+	auth "task/internal/domain/auth/entity"
+	authService "task/internal/domain/auth/service"
+	"task/internal/domain/mail/mailer"
+	rbac "task/internal/domain/rbac/entity"
+	stream "task/internal/domain/stream/service"
+	"task/internal/health"
+	"task/pkg/signing"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+var testAuthConfig = common.AuthConfig{
+	Secret:     "test-secret",
+	Issuer:     "task",
+	AccessTTL:  time.Minute,
+	RefreshTTL: time.Hour,
+}
+
+func newTestHandler(t *testing.T) http.Handler {
+	t.Helper()
+
+	di := &common.DependencyContainer{Config: &common.Config{Auth: testAuthConfig}}
+
+	handler, err := NewServer(di, nil, nil, authService.NewService(di, mailer.NewMemory()), stream.NewBroadcaster(), ratelimit.NewMemory()).GetHTTPHandler(common.NewLogger())
+	require.NoError(t, err)
+
+	return handler
+}
+
+func TestGetHTTPHandler_RoutesAreDocumented(t *testing.T) {
+	spec, err := openapi.Load()
+	require.NoError(t, err)
+
+	routes, ok := newTestHandler(t).(chi.Routes)
+	require.True(t, ok)
+
+	err = chi.Walk(routes, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		_, operation := spec.Operation(method, route)
+		require.NotNil(t, operation, "%s %s is missing from openapi.yaml", method, route)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestGetHTTPHandler_ValidatesRequests(t *testing.T) {
+	handler := newTestHandler(t)
+
+	cases := []struct {
+		name string
+		path string
+		body string
+	}{
+		{
+			name: "Unknown currency",
+			path: "/transaction/deposit",
+			body: `{"id":1,"account_id":1,"amount":10,"currency":"RUR"}`,
+		},
+		{
+			name: "Missing to_account",
+			path: "/transaction/withdraw",
+			body: `{"id":1,"account_id":1,"amount":10,"currency":"USD"}`,
+		},
+		{
+			name: "Short password",
+			path: "/accounts/register",
+			body: `{"id":1,"currency":"USD","password":"short","email":"owner@example.com"}`,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", JSONContentType)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+			require.Contains(t, rec.Body.String(), "request does not match the API specification")
+		})
+	}
+}
+
+type fakeAuthenticator map[string]*auth.Principal
+
+func (f fakeAuthenticator) Authenticate(_ context.Context, credential string, _ string) (*auth.Principal, error) {
+	principal, ok := f[credential]
+	if !ok {
+		return nil, Errors.ErrInvalidToken
+	}
+
+	return principal, nil
+}
+
+type rejectingVerifier struct{}
+
+func (rejectingVerifier) VerifySignature(context.Context, uint64, *apikey.SignedRequest) error {
+	return Errors.ErrInvalidSignature
+}
+
+func TestGetHTTPHandler_Authorization(t *testing.T) {
+	di := &common.DependencyContainer{Config: &common.Config{}}
+
+	s := NewServer(di, nil, nil, nil, stream.NewBroadcaster(), ratelimit.NewMemory())
+	s.authenticator = fakeAuthenticator{
+		"customer": {
+			AccountID: 1,
+			Roles:     []string{rbac.RoleCustomer},
+			Permissions: map[string]bool{
+				rbac.PermAccountsRead:      true,
+				rbac.PermTransactionsRead:  true,
+				rbac.PermTransactionsWrite: true,
+			},
+		},
+		"operator": {
+			AccountID: 3,
+			Roles:     []string{rbac.RoleOperator},
+			Permissions: map[string]bool{
+				rbac.PermAccountsRead:  true,
+				rbac.PermAccountsWrite: true,
+			},
+		},
+		"partner": {
+			AccountID:         1,
+			APIKeyID:          5,
+			SignatureRequired: true,
+			Permissions: map[string]bool{
+				rbac.PermTransactionsWrite: true,
+			},
+		},
+	}
+	s.verifier = rejectingVerifier{}
+
+	handler, err := s.GetHTTPHandler(common.NewLogger())
+	require.NoError(t, err)
+
+	cases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		token      string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{
+			name:       "Missing token",
+			method:     http.MethodGet,
+			path:       "/accounts/1",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "Invalid token",
+			method:     http.MethodGet,
+			path:       "/accounts/1",
+			token:      "not-a-token",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "Foreign account",
+			method:     http.MethodGet,
+			path:       "/accounts/2",
+			token:      "customer",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Withdraw from foreign account",
+			method:     http.MethodPost,
+			path:       "/transaction/withdraw",
+			body:       `{"id":1,"account_id":2,"amount":10,"currency":"USD","to_account":1}`,
+			token:      "customer",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Customer overrides balance",
+			method:     http.MethodPatch,
+			path:       "/accounts/1",
+			body:       `{"id":1,"balance":1000000,"currency":"USD"}`,
+			token:      "customer",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			// The legacy route never named the account, so it is gone.
+			name:       "Legacy account deletion",
+			method:     http.MethodDelete,
+			path:       "/accounts/delete",
+			token:      "customer",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "Customer settles transaction",
+			method:     http.MethodPatch,
+			path:       "/transaction/1",
+			token:      "customer",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Customer deletes transaction",
+			method:     http.MethodDelete,
+			path:       "/transaction/1",
+			token:      "customer",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Customer uses admin API",
+			method:     http.MethodGet,
+			path:       "/admin/roles",
+			token:      "customer",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Operator updates foreign path account",
+			method:     http.MethodPatch,
+			path:       "/accounts/1",
+			body:       `{"balance":10,"currency":"USD"}`,
+			token:      "operator",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Operator updates own path account with foreign body id",
+			method:     http.MethodPatch,
+			path:       "/accounts/3",
+			body:       `{"id":2,"balance":10,"currency":"USD"}`,
+			token:      "operator",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Unsigned request with signing key",
+			method:     http.MethodPost,
+			path:       "/transaction/deposit",
+			body:       `{"id":1,"account_id":1,"amount":10,"currency":"USD"}`,
+			token:      "partner",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "Rejected signature",
+			method: http.MethodPost,
+			path:   "/transaction/deposit",
+			body:   `{"id":1,"account_id":1,"amount":10,"currency":"USD"}`,
+			token:  "partner",
+			headers: map[string]string{
+				signing.HeaderSignature: "v1=00",
+				signing.HeaderTimestamp: "1690891200",
+				signing.HeaderNonce:     "3f2a",
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", JSONContentType)
+			}
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			for name, value := range tc.headers {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestGetHTTPHandler_RateLimit(t *testing.T) {
+	di := &common.DependencyContainer{Config: &common.Config{
+		RateLimit: common.RateLimitConfig{
+			Enabled:    true,
+			Period:     time.Minute,
+			ReadLimit:  60,
+			ReadBurst:  3,
+			MoneyLimit: 6,
+			MoneyBurst: 1,
+		},
+	}}
+
+	s := NewServer(di, nil, nil, nil, stream.NewBroadcaster(), ratelimit.NewMemory())
+	s.authenticator = fakeAuthenticator{
+		"customer": {
+			AccountID:   1,
+			Permissions: map[string]bool{rbac.PermTransactionsWrite: true},
+		},
+	}
+
+	handler, err := s.GetHTTPHandler(common.NewLogger())
+	require.NoError(t, err)
+
+	withdraw := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/transaction/withdraw", strings.NewReader(`{"id":1,"account_id":2,"amount":10,"currency":"USD","to_account":1}`))
+		req.Header.Set("Content-Type", JSONContentType)
+		req.Header.Set("Authorization", "Bearer customer")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := withdraw()
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Equal(t, "1", rec.Header().Get("RateLimit-Limit"))
+	require.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	require.Equal(t, "6;w=60;burst=1", rec.Header().Get("RateLimit-Policy"))
+
+	rec = withdraw()
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "10", rec.Header().Get("Retry-After"))
+
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "1", rec.Header().Get("Retry-After"))
+}
+
+func TestGetHTTPHandler_Docs(t *testing.T) {
+	handler := newTestHandler(t)
+
+	cases := []struct {
+		name        string
+		path        string
+		code        int
+		contentType string
+	}{
+		{name: "Page", path: "/docs", code: http.StatusOK, contentType: "text/html"},
+		{name: "Stylesheet", path: "/docs/swagger-ui.css", code: http.StatusOK, contentType: "text/css"},
+		{name: "Script", path: "/docs/swagger-ui-bundle.js", code: http.StatusOK, contentType: "javascript"},
+		{name: "Unknown file", path: "/docs/LICENSE", code: http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			require.Equal(t, tc.code, rec.Code)
+			require.Contains(t, rec.Header().Get("Content-Type"), tc.contentType)
+			require.NotContains(t, rec.Body.String(), "unpkg.com")
+		})
+	}
+}
+
+func TestGetHTTPHandler_RequestBody(t *testing.T) {
+	di := &common.DependencyContainer{Config: &common.Config{
+		Auth:       testAuthConfig,
+		HTTPServer: common.HTTPServer{MaxBodyBytes: 64},
+	}}
+
+	handler, err := NewServer(di, nil, nil, authService.NewService(di, mailer.NewMemory()), stream.NewBroadcaster(), nil).GetHTTPHandler(common.NewLogger())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   string
+		wantErrors []validate.FieldError
+	}{
+		{
+			name:       "too large",
+			body:       `{"account_id":1,"password":"` + strings.Repeat("x", 64) + `"}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   problem.CodeRequestTooLarge,
+		},
+		{
+			name:       "unknown field",
+			body:       `{"account_id":1,"password":"secret","admin":true}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeValidationFailed,
+			wantErrors: []validate.FieldError{{Field: "admin", Rule: "unknown", Message: "is not a known field"}},
+		},
+		{
+			name:       "schema errors",
+			body:       `{"account_id":1,"password":""}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeValidationFailed,
+			wantErrors: []validate.FieldError{{Field: "password", Rule: "minLength", Message: "minimum string length is 1"}},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", JSONContentType)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.wantStatus, rec.Code)
+			require.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+
+			var got problem.Problem
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+			require.Equal(t, tc.wantStatus, got.Status)
+			require.Equal(t, tc.wantCode, got.Code)
+			require.Equal(t, tc.wantErrors, got.Errors)
+			require.NotEmpty(t, got.RequestID)
+			require.Equal(t, "/auth/login", got.Instance)
+		})
+	}
+}
+
+func TestGetHTTPHandler_RegisterWithoutBalance(t *testing.T) {
+	handler := newTestHandler(t)
+
+	for _, path := range []string{"/accounts/register", envelope.Prefix + "/accounts"} {
+		path := path
+
+		t.Run(path, func(t *testing.T) {
+			body := `{"id":1,"currency":"USD","balance":1000000,"password":"correct horse battery","email":"owner@example.com"}`
+			req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+			req.Header.Set("Content-Type", JSONContentType)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+			require.Contains(t, rec.Body.String(), `{"field":"balance","rule":"unknown"`)
+		})
+	}
+}
+
+func TestGetHTTPHandler_Language(t *testing.T) {
+	handler := newTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"account_id":1,"password":"secret","admin":true}`))
+	req.Header.Set("Content-Type", JSONContentType)
+	req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en;q=0.8")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, "ru", rec.Header().Get("Content-Language"))
+
+	var got problem.Problem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+	require.Equal(t, problem.CodeValidationFailed, got.Code)
+	require.Equal(t, "некорректный запрос", got.Detail)
+	require.Equal(t, []validate.FieldError{{Field: "admin", Rule: "unknown", Message: "неизвестное поле"}}, got.Errors)
+}
+
+func TestGetHTTPHandler_Versions(t *testing.T) {
+	di := &common.DependencyContainer{Config: &common.Config{
+		Auth:       testAuthConfig,
+		HTTPServer: common.HTTPServer{LegacySunset: time.Date(2027, time.June, 30, 0, 0, 0, 0, time.UTC)},
+	}}
+
+	handler, err := NewServer(di, nil, nil, authService.NewService(di, mailer.NewMemory()), stream.NewBroadcaster(), nil).GetHTTPHandler(common.NewLogger())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		method          string
+		path            string
+		body            string
+		wantStatus      int
+		wantContentType string
+		wantLink        string
+	}{
+		{
+			name:            "versioned",
+			method:          http.MethodPost,
+			path:            "/v1/auth/login",
+			body:            `{"account_id":1,"password":"secret","admin":true}`,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: JSONContentType,
+		},
+		{
+			name:            "legacy",
+			method:          http.MethodPost,
+			path:            "/auth/login",
+			body:            `{"account_id":1,"password":"secret","admin":true}`,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: problem.ContentType,
+			wantLink:        `</v1/auth/login>; rel="successor-version"`,
+		},
+		{
+			name:            "legacy renamed",
+			method:          http.MethodGet,
+			path:            "/transaction/frozen/7",
+			wantStatus:      http.StatusUnauthorized,
+			wantContentType: problem.ContentType,
+			wantLink:        `</v1/accounts/7/frozen-balance>; rel="successor-version"`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", JSONContentType)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.wantStatus, rec.Code)
+			require.Equal(t, tc.wantContentType, rec.Header().Get("Content-Type"))
+			require.Equal(t, tc.wantLink, rec.Header().Get("Link"))
+
+			if tc.wantLink == "" {
+				require.Empty(t, rec.Header().Get("Deprecation"))
+
+				var got struct {
+					Data  any              `json:"data"`
+					Error *problem.Problem `json:"error"`
+					Meta  envelope.Meta    `json:"meta"`
+				}
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+				require.Nil(t, got.Data)
+				require.NotNil(t, got.Error)
+				require.Equal(t, problem.CodeValidationFailed, got.Error.Code)
+				require.Equal(t, envelope.Version, got.Meta.Version)
+				require.Equal(t, got.Error.RequestID, got.Meta.RequestID)
+				return
+			}
+
+			require.Equal(t, "true", rec.Header().Get("Deprecation"))
+			require.Equal(t, "Wed, 30 Jun 2027 00:00:00 GMT", rec.Header().Get("Sunset"))
+
+			var got problem.Problem
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&got))
+			require.Equal(t, tc.wantStatus, got.Status)
+		})
+	}
+}
+
+func TestGetAdminHandler_Metrics(t *testing.T) {
+	handler := newTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts/42", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	rec := httptest.NewRecorder()
+	GetAdminHandler(health.New(time.Second)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `task_http_request_duration_seconds_count{method="GET",route="/v1/accounts/{account_id}",status="401"}`)
+	require.NotContains(t, rec.Body.String(), `route="/v1/accounts/42"`)
+}
+
+func TestGetHTTPHandler_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previous, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(propagator)
+	})
+
+	handler := newTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/accounts/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+	require.Equal(t, "GET /v1/accounts/{account_id}", span.Name())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	require.Contains(t, span.Attributes(), semconv.HTTPStatusCode(http.StatusUnauthorized))
+}
+
+func TestErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		handler    func(w http.ResponseWriter, r *http.Request) error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "not found",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				return fmt.Errorf("account.Handlers.Get: %w", Errors.ErrAccountNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"type":"about:blank","title":"Not Found","status":404,"detail":"account not found","instance":"/accounts/7","code":"account_not_found"}`,
+		},
+		{
+			name: "negative balance",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				return fmt.Errorf("transaction.Handlers.Withdraw: %w", Errors.ErrNegativeBalance)
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"type":"about:blank","title":"Unprocessable Entity","status":422,"detail":"negative balance","instance":"/accounts/7","code":"negative_balance"}`,
+		},
+		{
+			name: "internal error",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				return errors.New("connection refused")
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"internal error","instance":"/accounts/7","code":"internal_error"}`,
+		},
+		{
+			name: "response started",
+			handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"status":"success"}`))
+				return errors.New("stream closed")
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"status":"success"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/accounts/7", nil)
+			req = req.WithContext(common.WithContext(req.Context(), common.NewLogger()))
+			rec := httptest.NewRecorder()
+
+			ErrorHandler(tc.handler).ServeHTTP(rec, req)
+
+			require.Equal(t, tc.wantStatus, rec.Code)
+			require.JSONEq(t, tc.wantBody, rec.Body.String())
+		})
+	}
+}
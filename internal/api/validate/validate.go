@@ -0,0 +1,213 @@
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"task/common"
+	"task/internal/domain/Errors"
+	"task/internal/i18n"
+)
+
+// FieldError is one invalid field of a request, named by its JSON path,
+// e.g. "scopes[1]".
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Error lists every invalid field of a request. It matches
+// Errors.ErrValidation.
+type Error struct {
+	Fields []FieldError
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Field + " " + field.Message
+	}
+
+	return Errors.ErrValidation.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (e *Error) Unwrap() error {
+	return Errors.ErrValidation
+}
+
+var engine = newEngine()
+
+func newEngine() *validator.Validate {
+	v := validator.New()
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	_ = v.RegisterValidation("currency", func(fl validator.FieldLevel) bool {
+		return common.SupportedCurrency(fl.Field().String())
+	})
+
+	return v
+}
+
+// DecodeJSON decodes the body into v strictly: unknown fields, trailing
+// data and mistyped values are rejected. It then checks the validate tags
+// of v. The body size is limited by the LimitBody middleware. Messages are
+// in the language of the request.
+func DecodeJSON(r *http.Request, v any) error {
+	ctx := r.Context()
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		return decodeError(ctx, err)
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: unexpected data after the JSON value", Errors.ErrMalformedRequest)
+	}
+
+	return StructContext(ctx, v)
+}
+
+// Struct checks the validate tags of v.
+func Struct(v any) error {
+	return StructContext(context.Background(), v)
+}
+
+// StructContext checks the validate tags of v with messages in the
+// language of ctx.
+func StructContext(ctx context.Context, v any) error {
+	err := engine.Struct(v)
+
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return err
+	}
+
+	fields := make([]FieldError, len(invalid))
+	for i, fe := range invalid {
+		fields[i] = FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Message: message(ctx, fe),
+		}
+	}
+
+	return &Error{Fields: fields}
+}
+
+func decodeError(ctx context.Context, err error) error {
+	var (
+		tooLarge *http.MaxBytesError
+		mistyped *json.UnmarshalTypeError
+		syntax   *json.SyntaxError
+	)
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return Errors.ErrEmptyRequest
+	case errors.As(err, &tooLarge):
+		return fmt.Errorf("%w: limit is %d bytes", Errors.ErrRequestTooLarge, tooLarge.Limit)
+	case errors.As(err, &mistyped) && mistyped.Field != "":
+		return &Error{Fields: []FieldError{{
+			Field:   mistyped.Field,
+			Rule:    "type",
+			Message: i18n.T(ctx, "validation.type."+jsonType(mistyped.Type), nil),
+		}}}
+	case errors.As(err, &syntax):
+		return fmt.Errorf("%w: %s at offset %d", Errors.ErrMalformedRequest, syntax.Error(), syntax.Offset)
+	}
+
+	// encoding/json has no type for unknown fields.
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return &Error{Fields: []FieldError{{
+			Field:   strings.Trim(name, `"`),
+			Rule:    "unknown",
+			Message: i18n.T(ctx, "validation.unknown", nil),
+		}}}
+	}
+
+	return fmt.Errorf("%w: %s", Errors.ErrMalformedRequest, err)
+}
+
+// fieldPath drops the struct name from a validator namespace.
+func fieldPath(namespace string) string {
+	_, path, ok := strings.Cut(namespace, ".")
+	if !ok {
+		return namespace
+	}
+
+	return path
+}
+
+// message translates a failed rule, see the validation.* keys of the
+// catalogs.
+func message(ctx context.Context, fe validator.FieldError) string {
+	args := i18n.Args{"param": fe.Param()}
+
+	var key string
+
+	switch fe.Tag() {
+	case "required", "email", "url", "alphanum", "gt", "gte", "lt", "lte":
+		key = fe.Tag()
+	case "currency":
+		key, args["values"] = "one_of", strings.Join(common.Currencies, ", ")
+	case "oneof":
+		key, args["values"] = "one_of", strings.Join(strings.Fields(fe.Param()), ", ")
+	case "ip|cidr":
+		key = "ip_or_cidr"
+	case "min", "max", "len":
+		key = fe.Tag() + "." + sizeKind(fe.Kind())
+	default:
+		key = "invalid"
+	}
+
+	return i18n.T(ctx, "validation."+key, args)
+}
+
+func sizeKind(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "items"
+	default:
+		return "value"
+	}
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.String:
+		return "string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "unsigned"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
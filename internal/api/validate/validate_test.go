@@ -0,0 +1,114 @@
+package validate
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"task/internal/domain/Errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testRequest struct {
+	ID       uint64   `json:"id" validate:"required"`
+	Amount   float64  `json:"amount" validate:"gt=0"`
+	Currency string   `json:"currency" validate:"required,currency"`
+	Email    string   `json:"email" validate:"omitempty,email"`
+	Scopes   []string `json:"scopes" validate:"max=2,dive,required"`
+}
+
+func TestDecodeJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		limit      int64
+		wantErr    error
+		wantFields []FieldError
+	}{
+		{
+			name: "valid",
+			body: `{"id":1,"amount":10.5,"currency":"USD","email":"a@example.com","scopes":["read"]}`,
+		},
+		{
+			name:    "empty body",
+			body:    ``,
+			wantErr: Errors.ErrEmptyRequest,
+		},
+		{
+			name:    "malformed",
+			body:    `{"id":1,`,
+			wantErr: Errors.ErrMalformedRequest,
+		},
+		{
+			name:    "trailing data",
+			body:    `{"id":1,"amount":1,"currency":"USD"} {}`,
+			wantErr: Errors.ErrMalformedRequest,
+		},
+		{
+			name:    "too large",
+			body:    `{"id":1,"amount":1,"currency":"USD"}`,
+			limit:   8,
+			wantErr: Errors.ErrRequestTooLarge,
+		},
+		{
+			name:       "unknown field",
+			body:       `{"id":1,"amount":1,"currency":"USD","status":"success"}`,
+			wantErr:    Errors.ErrValidation,
+			wantFields: []FieldError{{Field: "status", Rule: "unknown", Message: "is not a known field"}},
+		},
+		{
+			name:       "wrong type",
+			body:       `{"id":-1,"amount":1,"currency":"USD"}`,
+			wantErr:    Errors.ErrValidation,
+			wantFields: []FieldError{{Field: "id", Rule: "type", Message: "must be a non-negative integer"}},
+		},
+		{
+			name:    "invalid fields",
+			body:    `{"amount":-3,"currency":"GBP","email":"nope","scopes":["read",""]}`,
+			wantErr: Errors.ErrValidation,
+			wantFields: []FieldError{
+				{Field: "id", Rule: "required", Message: "is required"},
+				{Field: "amount", Rule: "gt", Message: "must be greater than 0"},
+				{Field: "currency", Rule: "currency", Message: "must be one of USD, EUR, RUB"},
+				{Field: "email", Rule: "email", Message: "must be a valid email address"},
+				{Field: "scopes[1]", Rule: "required", Message: "is required"},
+			},
+		},
+		{
+			name:       "too many items",
+			body:       `{"id":1,"amount":1,"currency":"EUR","scopes":["a","b","c"]}`,
+			wantErr:    Errors.ErrValidation,
+			wantFields: []FieldError{{Field: "scopes", Rule: "max", Message: "must have at most 2 items"}},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			if tc.limit > 0 {
+				r.Body = http.MaxBytesReader(httptest.NewRecorder(), r.Body, tc.limit)
+			}
+
+			var req testRequest
+			err := DecodeJSON(r, &req)
+
+			if tc.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.wantErr)
+
+			if tc.wantFields != nil {
+				var invalid *Error
+				require.ErrorAs(t, err, &invalid)
+				require.Equal(t, tc.wantFields, invalid.Fields)
+			}
+		})
+	}
+}
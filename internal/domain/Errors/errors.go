@@ -9,8 +9,41 @@ var (
 	ErrZeroBalance         = errors.New("zero balance")
 	ErrNegativeBalance     = errors.New("negative balance")
 	ErrInvalidCurrency     = errors.New("invalid currency")
+	ErrInvalidAmount       = errors.New("invalid amount")
+	ErrInvalidBalance      = errors.New("invalid balance")
+	ErrInvalidEmail        = errors.New("invalid email")
 	ErrAccountExists       = errors.New("account already exists")
 	ErrTransactionNotFound = errors.New("transaction not found")
 	ErrTransactionExists   = errors.New("transaction already exists")
+	ErrNotPending          = errors.New("transaction is not pending")
+	ErrNotSettled          = errors.New("transaction is not settled")
 	ErrIncorrectID         = errors.New("incorrect id")
+	ErrWebhookNotFound     = errors.New("webhook not found")
+	ErrDeliveryNotFound    = errors.New("webhook delivery not found")
+	ErrInvalidWebhookURL   = errors.New("invalid webhook url")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrInvalidToken        = errors.New("invalid token")
+	ErrUnauthenticated     = errors.New("unauthenticated")
+	ErrForbidden           = errors.New("forbidden")
+	ErrRoleNotFound        = errors.New("role not found")
+	ErrRoleExists          = errors.New("role already exists")
+	ErrInvalidRoleName     = errors.New("invalid role name")
+	ErrPermissionNotFound  = errors.New("permission not found")
+	ErrBuiltinRole         = errors.New("builtin role cannot be deleted")
+	ErrPolicyViolation     = errors.New("permission cannot be granted to this role")
+	ErrAPIKeyNotFound      = errors.New("api key not found")
+	ErrInvalidAPIKey       = errors.New("invalid api key")
+	ErrInvalidIPAllowlist  = errors.New("invalid ip allowlist")
+	ErrInvalidSignature    = errors.New("invalid request signature")
+	ErrReplayedRequest     = errors.New("replayed request")
+	ErrWeakPassword        = errors.New("password does not meet the policy")
+	ErrTOTPNotEnabled      = errors.New("two-factor authentication is not enabled")
+	ErrTOTPEnabled         = errors.New("two-factor authentication is already enabled")
+	ErrInvalidOTP          = errors.New("invalid one-time code")
+	ErrStepUpRequired      = errors.New("one-time code required")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrEmptyRequest        = errors.New("empty request")
+	ErrMalformedRequest    = errors.New("malformed request")
+	ErrRequestTooLarge     = errors.New("request body too large")
+	ErrValidation          = errors.New("invalid request")
 )
@@ -4,30 +4,25 @@ import (
 	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/render"
-	"io"
 	"net/http"
 	"strconv"
-	"task/common"
-	"task/internal/api/response"
+	"task/internal/api/problem"
+	"task/internal/api/validate"
+	"task/internal/domain/Errors"
 	"task/internal/domain/account/controller/handler/request"
 	"task/internal/domain/account/entity"
 	"task/internal/domain/account/service"
-)
-
-const (
-	USD = "USD"
-	EUR = "EUR"
-	RUB = "RUB"
+	"task/internal/domain/auth/password"
+	"task/internal/i18n"
 )
 
 type Handlers struct {
 	service *service.Service
 }
 
-func NewHandlers(di *common.DependencyContainer) *Handlers {
+func NewHandlers(service *service.Service) *Handlers {
 	return &Handlers{
-		service: service.NewService(di),
+		service: service,
 	}
 }
 
@@ -35,31 +30,23 @@ func (h *Handlers) Register(w http.ResponseWriter, r *http.Request) error {
 	const op = "account.Handlers.Register"
 	ctx := r.Context()
 
-	var account entity.Account
-
-	err := render.DecodeJSON(r.Body, &account)
+	var req request.RequestRegister
 
-	if errors.Is(err, io.EOF) {
-		render.JSON(w, r, response.Response{Error: "empty request", Status: "error"})
+	if err := validate.DecodeJSON(r, &req); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	if err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to decode request", Status: "error"})
-		return fmt.Errorf("%s: %w", op, err)
-	}
+	account := entity.NewAccount(req.ID, req.Currency, req.Password, req.Email)
 
-	if account.Currency != USD && account.Currency != EUR && account.Currency != RUB {
-		render.JSON(w, r, response.Response{Error: "invalid currency", Status: "error"})
-		return fmt.Errorf("%s: %w", op, err)
+	_, err := h.service.SaveAccount(ctx, account)
+	if errors.Is(err, Errors.ErrWeakPassword) {
+		return fmt.Errorf("%s: %w", op, problem.Wrap(err, 0, "error.password_policy", i18n.Args{"min": password.MinLength, "max": password.MaxLength}))
 	}
-
-	if _, err := h.service.SaveAccount(ctx, &account); err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to save account", Status: "error"})
+	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	request.ResponseRegisterOK(w, r, &account)
+	request.ResponseRegisterOK(w, r, account)
 
 	return nil
 
@@ -71,13 +58,11 @@ func (h *Handlers) Get(w http.ResponseWriter, r *http.Request) error {
 
 	id, err := GetIDFromRequest(r, "account_id")
 	if err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to decode request", Status: "error"})
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	account, err := h.service.GetAccount(ctx, id)
 	if err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to get account", Status: "error"})
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -93,19 +78,15 @@ func (h *Handlers) Delete(w http.ResponseWriter, r *http.Request) error {
 
 	id, err := GetIDFromRequest(r, "account_id")
 	if err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to get id", Status: "error"})
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	err = h.service.DeleteAccount(ctx, id)
 	if err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to delete account", Status: "error"})
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	render.JSON(w, r, response.Response{
-		Status: "ok",
-	})
+	request.ResponseDeleteOK(w, r)
 
 	return nil
 }
@@ -114,32 +95,34 @@ func (h *Handlers) Update(w http.ResponseWriter, r *http.Request) error {
 	const op = "account.Handlers.Update"
 	ctx := r.Context()
 
-	var req request.Request
+	id, err := GetIDFromRequest(r, "account_id")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
 
-	err := render.DecodeJSON(r.Body, &req)
+	var req request.Request
 
-	if errors.Is(err, io.EOF) {
-		render.JSON(w, r, response.Response{Error: "empty request", Status: "error"})
+	if err := validate.DecodeJSON(r, &req); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	if err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to decode", Status: "error"})
-		return fmt.Errorf("%s: %w", op, err)
+	// The body may repeat the id, as it once had to, but not name another
+	// account than the path.
+	if req.ID != 0 && req.ID != id {
+		return fmt.Errorf("%s: %w: body id %d does not match the path", op, Errors.ErrIncorrectID, req.ID)
 	}
 
-	if req.Currency != USD && req.Currency != EUR && req.Currency != RUB {
-		render.JSON(w, r, response.Response{Error: "invalid currency", Status: "error"})
+	err = h.service.UpdateBalance(ctx, id, req.Balance, req.Currency)
+	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	err = h.service.UpdateBalance(ctx, req.ID, req.Balance, req.Currency)
+	account, err := h.service.GetAccount(ctx, id)
 	if err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to update balance", Status: "error"})
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	request.ResponseUpdateOK(w, r, req.ID, req.Balance, req.Currency)
+	request.ResponseUpdateOK(w, r, account)
 
 	return nil
 }
@@ -147,12 +130,12 @@ func (h *Handlers) Update(w http.ResponseWriter, r *http.Request) error {
 func GetIDFromRequest(r *http.Request, key string) (uint64, error) {
 	param := chi.URLParam(r, key)
 	if param == "" {
-		return 0, fmt.Errorf("empty parameter %s", key)
+		return 0, fmt.Errorf("%w: empty parameter %s", Errors.ErrIncorrectID, key)
 	}
 
 	value, err := strconv.ParseUint(param, 10, 64)
 	if err != nil {
-		return 0, fmt.Errorf("invalid parameter %s", key)
+		return 0, fmt.Errorf("%w: invalid parameter %s", Errors.ErrIncorrectID, key)
 	}
 
 	return value, nil
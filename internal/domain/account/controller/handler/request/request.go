@@ -1,7 +1,6 @@
 package request
 
 import (
-	"github.com/go-chi/render"
 	"net/http"
 	"task/internal/api/response"
 	"task/internal/domain/account/entity"
@@ -10,10 +9,18 @@ import (
 
 type Request struct {
 	ID       uint64  `json:"id,omitempty"`
-	Balance  float64 `json:"balance,omitempty"`
-	Currency string  `json:"currency,omitempty"`
-	Password string  `json:"password,omitempty" validate:"required,alphanumeric"`
-	Email    string  `json:"email,omitempty" validate:"required,email"`
+	Balance  float64 `json:"balance,omitempty" validate:"gte=0,lte=1000000000"`
+	Currency string  `json:"currency,omitempty" validate:"required,currency"`
+}
+
+// RequestRegister opens an account with a zero balance. Registration is
+// public, so the balance is only ever changed by the owner's transactions
+// or an operator.
+type RequestRegister struct {
+	ID       uint64 `json:"id" validate:"required"`
+	Currency string `json:"currency" validate:"required,currency"`
+	Password string `json:"password" validate:"required,max=256"`
+	Email    string `json:"email" validate:"required,email,max=255"`
 }
 
 type ResponseSave struct {
@@ -28,13 +35,13 @@ type ResponseGet struct {
 
 type ResponseUpdate struct {
 	response.Response
-	id       uint64
-	balance  float64
-	currency string
+	ID       uint64  `json:"id"`
+	Balance  float64 `json:"balance"`
+	Currency string  `json:"currency"`
 }
 
 func ResponseRegisterOK(w http.ResponseWriter, r *http.Request, account *entity.Account) {
-	render.JSON(w, r, ResponseSave{
+	response.Render(w, r, accountDTO(account), ResponseSave{
 		Response: response.Response{
 			Status: "ok",
 		},
@@ -47,26 +54,39 @@ func ResponseRegisterOK(w http.ResponseWriter, r *http.Request, account *entity.
 }
 
 func ResponseGetOK(w http.ResponseWriter, r *http.Request, account *entity.Account) {
-	render.JSON(w, r, ResponseGet{
+	response.Render(w, r, accountDTO(account), ResponseGet{
 		Response: response.Response{
 			Status: "ok",
 		},
-		AccountDTO: dto.AccountDTO{
-			ID:       account.ID,
-			Currency: account.Currency,
-			Balance:  account.Balance,
-			Email:    account.Email,
-		},
+		AccountDTO: accountDTO(account),
 	})
 }
 
-func ResponseUpdateOK(w http.ResponseWriter, r *http.Request, id uint64, balance float64, currency string) {
-	render.JSON(w, r, ResponseUpdate{
+func ResponseUpdateOK(w http.ResponseWriter, r *http.Request, account *entity.Account) {
+	response.Render(w, r, accountDTO(account), ResponseUpdate{
 		Response: response.Response{
 			Status: "ok",
 		},
-		id:       id,
-		balance:  balance,
-		currency: currency,
+		ID:       account.ID,
+		Balance:  account.Balance,
+		Currency: account.Currency,
 	})
 }
+
+func ResponseDeleteOK(w http.ResponseWriter, r *http.Request) {
+	response.Render(w, r, nil, response.Response{
+		Status: "ok",
+	})
+}
+
+// accountDTO is the account resource every route of the versioned API
+// answers with.
+func accountDTO(account *entity.Account) dto.AccountDTO {
+	return dto.AccountDTO{
+		ID:            account.ID,
+		Currency:      account.Currency,
+		Balance:       account.Balance,
+		Email:         account.Email,
+		EmailVerified: account.EmailVerifiedAt != nil,
+	}
+}
@@ -1,18 +1,21 @@
 package entity
 
+import "time"
+
 type Account struct {
-	ID       uint64  `json:"id"`
-	Currency string  `json:"currency"`
-	Balance  float64 `json:"balance"`
-	Password string  `json:"password"`
-	Email    string  `json:"email"`
+	ID              uint64     `json:"id"`
+	Currency        string     `json:"currency"`
+	Balance         float64    `json:"balance"`
+	Password        string     `json:"-"`
+	Email           string     `json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" db:"email_verified_at"`
 }
 
-func NewAccount(id uint64, currency string, balance float64, password string, email string) *Account {
+// NewAccount opens an account with a zero balance.
+func NewAccount(id uint64, currency string, password string, email string) *Account {
 	return &Account{
 		ID:       id,
 		Currency: currency,
-		Balance:  balance,
 		Password: password,
 		Email:    email,
 	}
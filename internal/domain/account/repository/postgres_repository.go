@@ -7,6 +7,7 @@ import (
 	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"task/common"
 	"task/internal/domain/Errors"
 	"task/internal/domain/account/entity"
 )
@@ -51,7 +52,7 @@ func (r *PostgresRepository) Save(ctx context.Context, entity *entity.Account) e
 func (r *PostgresRepository) Get(ctx context.Context, id uint64) (*entity.Account, error) {
 	const op = "domain/account.PostgresRepository.Get"
 	query := `
-		SELECT id, currency, balance, password, email FROM account
+		SELECT id, currency, balance, password, email, email_verified_at FROM account
 		WHERE id = @id
 	`
 
@@ -70,6 +71,22 @@ func (r *PostgresRepository) Get(ctx context.Context, id uint64) (*entity.Accoun
 	return &account, nil
 }
 
+// List reads every account but its password hash, by id.
+func (r *PostgresRepository) List(ctx context.Context) ([]*entity.Account, error) {
+	const op = "domain/account.PostgresRepository.List"
+	query := `
+		SELECT id, currency, balance, email, email_verified_at FROM account
+		ORDER BY id
+	`
+
+	var accounts []*entity.Account
+	if err := pgxscan.Select(ctx, r.db, &accounts, query); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return accounts, nil
+}
+
 func (r *PostgresRepository) Delete(ctx context.Context, id uint64) error {
 	const op = "domain/account.PostgresRepository.Delete"
 	query := `
@@ -111,9 +128,81 @@ func (r *PostgresRepository) Update(ctx context.Context, id uint64, balance floa
 		return fmt.Errorf("%s: %w", op, Errors.ErrAccountNotFound)
 	}
 
-	if _, err := r.db.Exec(ctx, query, args); err != nil {
+	if _, err := common.Conn(ctx, r.db).Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) UpdatePassword(ctx context.Context, id uint64, hash string) error {
+	const op = "domain/account.PostgresRepository.UpdatePassword"
+	query := `
+		UPDATE account
+			SET password = @password
+		WHERE id = @id;
+	`
+
+	args := pgx.NamedArgs{
+		"id":       id,
+		"password": hash,
+	}
+
+	tag, err := common.Conn(ctx, r.db).Exec(ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, Errors.ErrAccountNotFound)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) ListByEmail(ctx context.Context, email string) ([]*entity.Account, error) {
+	const op = "domain/account.PostgresRepository.ListByEmail"
+	query := `
+		SELECT id, currency, balance, password, email, email_verified_at FROM account
+		WHERE lower(email) = lower(@email)
+		ORDER BY id
+	`
+
+	args := pgx.NamedArgs{
+		"email": email,
+	}
+
+	var accounts []*entity.Account
+	if err := pgxscan.Select(ctx, r.db, &accounts, query, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return accounts, nil
+}
+
+// MarkEmailVerified verifies the address only if it is still the one the
+// verification was sent to.
+func (r *PostgresRepository) MarkEmailVerified(ctx context.Context, id uint64, email string) error {
+	const op = "domain/account.PostgresRepository.MarkEmailVerified"
+	query := `
+		UPDATE account
+			SET email_verified_at = COALESCE(email_verified_at, now())
+		WHERE id = @id AND email = @email;
+	`
+
+	args := pgx.NamedArgs{
+		"id":    id,
+		"email": email,
+	}
+
+	tag, err := common.Conn(ctx, r.db).Exec(ctx, query, args)
+	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, Errors.ErrAccountNotFound)
+	}
+
 	return nil
 }
@@ -4,31 +4,70 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/mail"
 	"task/common"
 	"task/internal/domain/Errors"
 	"task/internal/domain/account/entity"
 	"task/internal/domain/account/repository"
+	"task/internal/domain/auth/password"
+	outbox "task/internal/domain/outbox/entity"
+	outboxRep "task/internal/domain/outbox/repository"
 )
 
 type Repository interface {
 	Save(ctx context.Context, account *entity.Account) error
 	Get(ctx context.Context, id uint64) (*entity.Account, error)
+	List(ctx context.Context) ([]*entity.Account, error)
 	Delete(ctx context.Context, id uint64) error
 	Update(ctx context.Context, id uint64, balance float64, currency string) error
 }
 
+type Outbox interface {
+	Save(ctx context.Context, event *outbox.Event) error
+}
+
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Verifier sends the email verification link of a new account.
+type Verifier interface {
+	SendVerification(ctx context.Context, accountID uint64) error
+}
+
+// maxBalance bounds the balance an account may be given.
+const maxBalance = 1_000_000_000
+
 type Service struct {
 	repository Repository
+	outbox     Outbox
+	transactor Transactor
+	verifier   Verifier
 }
 
-func NewService(di *common.DependencyContainer) *Service {
+func NewService(di *common.DependencyContainer, verifier Verifier) *Service {
 	return &Service{
 		repository: repository.NewPostgresRepository(di.Pool),
+		outbox:     outboxRep.NewPostgresRepository(di.Pool),
+		transactor: common.NewTransactor(di.Pool),
+		verifier:   verifier,
 	}
 }
 
 func (s *Service) SaveAccount(ctx context.Context, account *entity.Account) (*entity.Account, error) {
 	const op = "domain/account.Service.SaveAccount"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	if err := check(account.ID, account.Balance, account.Currency); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := checkEmail(account.Email); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := password.Validate(account.Password); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
 	_, err := s.repository.Get(ctx, account.ID)
 
@@ -37,10 +76,22 @@ func (s *Service) SaveAccount(ctx context.Context, account *entity.Account) (*en
 		return nil, fmt.Errorf("%s: %w", op, Errors.ErrAccountExists)
 
 	case Errors.ErrAccountNotFound:
+		hash, err := password.Hash(account.Password)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		account.Password = hash
+
 		err = s.repository.Save(ctx, account)
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
+
+		// The account exists either way; the link can be requested again.
+		if err := s.verifier.SendVerification(ctx, account.ID); err != nil {
+			common.FromContext(ctx).Warn("cannot send email verification", "account_id", account.ID, "error", err.Error())
+		}
+
 		return account, nil
 	default:
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -50,6 +101,8 @@ func (s *Service) SaveAccount(ctx context.Context, account *entity.Account) (*en
 
 func (s *Service) GetAccount(ctx context.Context, id uint64) (*entity.Account, error) {
 	const op = "domain/account.Service.GetAccount"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
 
 	account, err := s.repository.Get(ctx, id)
 	if errors.Is(err, Errors.ErrAccountNotFound) {
@@ -62,8 +115,23 @@ func (s *Service) GetAccount(ctx context.Context, id uint64) (*entity.Account, e
 	return account, nil
 }
 
+func (s *Service) ListAccounts(ctx context.Context) ([]*entity.Account, error) {
+	const op = "domain/account.Service.ListAccounts"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	accounts, err := s.repository.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return accounts, nil
+}
+
 func (s *Service) DeleteAccount(ctx context.Context, id uint64) error {
 	const op = "domain/account.Service.Delete"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
 
 	err := s.repository.Delete(ctx, id)
 	if errors.Is(err, Errors.ErrAccountNotFound) {
@@ -78,8 +146,26 @@ func (s *Service) DeleteAccount(ctx context.Context, id uint64) error {
 
 func (s *Service) UpdateBalance(ctx context.Context, id uint64, balance float64, currency string) error {
 	const op = "domain/account.Service.Update"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	if err := check(id, balance, currency); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// The event keeps the account reconcilable with its transactions.
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repository.Update(ctx, id, balance, currency); err != nil {
+			return err
+		}
+
+		event, err := outbox.NewBalanceEvent(id, balance, currency)
+		if err != nil {
+			return err
+		}
 
-	err := s.repository.Update(ctx, id, balance, currency)
+		return s.outbox.Save(ctx, event)
+	})
 	if errors.Is(err, Errors.ErrAccountNotFound) {
 		return fmt.Errorf("%s: %w", op, Errors.ErrAccountNotFound)
 	}
@@ -90,3 +176,34 @@ func (s *Service) UpdateBalance(ctx context.Context, id uint64, balance float64,
 	return nil
 
 }
+
+// check rejects what no transport may store, whatever it validates
+// itself. NaN fails every comparison, so the range is checked the way that
+// rejects it.
+func check(id uint64, balance float64, currency string) error {
+	switch {
+	case id == 0:
+		return Errors.ErrIncorrectID
+	case !(balance >= 0 && balance <= maxBalance):
+		return Errors.ErrInvalidBalance
+	case !common.SupportedCurrency(currency):
+		return Errors.ErrInvalidCurrency
+	}
+
+	return nil
+}
+
+// checkEmail accepts a bare address of at most 255 bytes, the size of the
+// column, and no display name.
+func checkEmail(email string) error {
+	if len(email) > 255 {
+		return Errors.ErrInvalidEmail
+	}
+
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return Errors.ErrInvalidEmail
+	}
+
+	return nil
+}
@@ -1,10 +1,11 @@
 package dto
 
 type AccountDTO struct {
-	ID       uint64  `json:"id"`
-	Currency string  `json:"currency"`
-	Balance  float64 `json:"balance"`
-	Email    string  `json:"email"`
+	ID            uint64  `json:"id"`
+	Currency      string  `json:"currency"`
+	Balance       float64 `json:"balance"`
+	Email         string  `json:"email"`
+	EmailVerified bool    `json:"email_verified"`
 }
 
 type RegistrationCommand struct {
@@ -7,6 +7,7 @@ import (
 	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"task/common"
 	"task/internal/domain/Errors"
 	"task/internal/domain/account_dto/dto"
 )
@@ -39,7 +40,7 @@ func (r *PostgresRepository) UpdateBalance(ctx context.Context, account_id uint6
 		"balance": balance,
 	}
 
-	if _, err = r.db.Exec(ctx, query, args); err != nil {
+	if _, err = common.Conn(ctx, r.db).Exec(ctx, query, args); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -60,7 +61,33 @@ func (r *PostgresRepository) CheckExistsAccount(ctx context.Context, account_id
 
 	var accountDto dto.RegistrationCommand
 
-	if err := pgxscan.Get(ctx, r.db, &accountDto, query, args); err != nil {
+	if err := pgxscan.Get(ctx, common.Conn(ctx, r.db), &accountDto, query, args); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, Errors.ErrAccountNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return &accountDto, nil
+}
+
+// GetAccountForUpdate reads the account and locks its row until the
+// transaction carried by ctx ends.
+func (r *PostgresRepository) GetAccountForUpdate(ctx context.Context, account_id uint64) (*dto.RegistrationCommand, error) {
+	const op = "PostgresRepository.GetAccountForUpdate"
+
+	query := `
+		SELECT id, balance, currency FROM account
+		WHERE id = @id
+		FOR UPDATE
+	`
+
+	args := pgx.NamedArgs{
+		"id": account_id,
+	}
+
+	var accountDto dto.RegistrationCommand
+
+	if err := pgxscan.Get(ctx, common.Conn(ctx, r.db), &accountDto, query, args); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, Errors.ErrAccountNotFound
 		}
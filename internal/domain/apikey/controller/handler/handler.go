@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"task/internal/api/validate"
+	"task/internal/domain/Errors"
+	"task/internal/domain/apikey/controller/request"
+	"task/internal/domain/apikey/entity"
+	"task/internal/domain/apikey/service"
+	"task/internal/i18n"
+	"time"
+)
+
+// defaultOverlap is how long a rotated key keeps working when the request
+// does not say.
+const defaultOverlap = 24 * time.Hour
+
+type Handlers struct {
+	service *service.Service
+}
+
+func NewHandlers(service *service.Service) *Handlers {
+	return &Handlers{
+		service: service,
+	}
+}
+
+func (h *Handlers) Create(w http.ResponseWriter, r *http.Request) error {
+	const op = "apikey.Handlers.Create"
+	ctx := r.Context()
+
+	var req request.RequestCreate
+
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	key, plain, err := h.service.Create(ctx, &entity.Key{
+		Name:             req.Name,
+		AccountID:        req.AccountID,
+		Scopes:           req.Scopes,
+		AllowedIPs:       req.AllowedIPs,
+		ExpiresAt:        req.ExpiresAt,
+		RequireSignature: req.RequireSignature,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseCreatedOK(w, r, key, plain)
+
+	return nil
+}
+
+func (h *Handlers) List(w http.ResponseWriter, r *http.Request) error {
+	const op = "apikey.Handlers.List"
+	ctx := r.Context()
+
+	keys, err := h.service.List(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseKeysOK(w, r, keys)
+
+	return nil
+}
+
+func (h *Handlers) Get(w http.ResponseWriter, r *http.Request) error {
+	const op = "apikey.Handlers.Get"
+	ctx := r.Context()
+
+	id, err := GetIDFromRequest(r, "key_id")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	key, err := h.service.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseKeyOK(w, r, key)
+
+	return nil
+}
+
+func (h *Handlers) Rotate(w http.ResponseWriter, r *http.Request) error {
+	const op = "apikey.Handlers.Rotate"
+	ctx := r.Context()
+
+	id, err := GetIDFromRequest(r, "key_id")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var req request.RequestRotate
+
+	if err := validate.DecodeJSON(r, &req); err != nil && !errors.Is(err, Errors.ErrEmptyRequest) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	overlap := defaultOverlap
+	if req.Overlap != "" {
+		overlap, err = time.ParseDuration(req.Overlap)
+		if err != nil || overlap < 0 {
+			return fmt.Errorf("%s: %w", op, &validate.Error{Fields: []validate.FieldError{{
+				Field:   "overlap",
+				Rule:    "duration",
+				Message: i18n.T(ctx, "validation.duration", nil),
+			}}})
+		}
+	}
+
+	key, plain, err := h.service.Rotate(ctx, id, overlap)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseCreatedOK(w, r, key, plain)
+
+	return nil
+}
+
+func (h *Handlers) Revoke(w http.ResponseWriter, r *http.Request) error {
+	const op = "apikey.Handlers.Revoke"
+	ctx := r.Context()
+
+	id, err := GetIDFromRequest(r, "key_id")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := h.service.Revoke(ctx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseOK(w, r)
+
+	return nil
+}
+
+func GetIDFromRequest(r *http.Request, key string) (uint64, error) {
+	param := chi.URLParam(r, key)
+	if param == "" {
+		return 0, fmt.Errorf("%w: empty parameter %s", Errors.ErrIncorrectID, key)
+	}
+
+	value, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid parameter %s", Errors.ErrIncorrectID, key)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,85 @@
+package request
+
+import (
+	"net/http"
+	"task/internal/api/response"
+	"task/internal/domain/apikey/entity"
+	"time"
+)
+
+type RequestCreate struct {
+	Name             string     `json:"name" validate:"required,max=100"`
+	AccountID        *uint64    `json:"account_id" validate:"omitempty,gt=0"`
+	Scopes           []string   `json:"scopes" validate:"required,min=1,dive,required"`
+	AllowedIPs       []string   `json:"allowed_ips" validate:"dive,ip|cidr"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+	RequireSignature bool       `json:"require_signature"`
+}
+
+type RequestRotate struct {
+	// Overlap is how long the old key keeps working, e.g. "24h".
+	Overlap string `json:"overlap"`
+}
+
+type ResponseKey struct {
+	response.Response
+	APIKey        *entity.Key `json:"api_key"`
+	Key           string      `json:"key,omitempty"`
+	SigningSecret string      `json:"signing_secret,omitempty"`
+}
+
+type ResponseKeys struct {
+	response.Response
+	APIKeys []*entity.Key `json:"api_keys"`
+}
+
+// CreatedKey is an API key together with its secrets, as returned once on
+// creation.
+type CreatedKey struct {
+	*entity.Key
+	Plaintext     string `json:"key"`
+	SigningSecret string `json:"signing_secret,omitempty"`
+}
+
+// ResponseCreatedOK is the only response that carries the plaintext key and
+// its signing secret.
+func ResponseCreatedOK(w http.ResponseWriter, r *http.Request, key *entity.Key, plain string) {
+	created := CreatedKey{
+		Key:           key,
+		Plaintext:     plain,
+		SigningSecret: key.SigningSecret,
+	}
+
+	response.Render(w, r, created, ResponseKey{
+		Response: response.Response{
+			Status: response.StatusSuccess,
+		},
+		APIKey:        key,
+		Key:           plain,
+		SigningSecret: key.SigningSecret,
+	})
+}
+
+func ResponseKeyOK(w http.ResponseWriter, r *http.Request, key *entity.Key) {
+	response.Render(w, r, key, ResponseKey{
+		Response: response.Response{
+			Status: response.StatusSuccess,
+		},
+		APIKey: key,
+	})
+}
+
+func ResponseKeysOK(w http.ResponseWriter, r *http.Request, keys []*entity.Key) {
+	response.Render(w, r, keys, ResponseKeys{
+		Response: response.Response{
+			Status: response.StatusSuccess,
+		},
+		APIKeys: keys,
+	})
+}
+
+func ResponseOK(w http.ResponseWriter, r *http.Request) {
+	response.Render(w, r, nil, response.Response{
+		Status: response.StatusSuccess,
+	})
+}
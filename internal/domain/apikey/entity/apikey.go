@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"net"
+	"time"
+)
+
+// Key is an API key. Only its SHA-256 hash is stored; the plaintext key is
+// returned once, when the key is created or rotated. The signing secret has
+// to be kept to verify request signatures, and is likewise shown only once.
+// Keys with RequireSignature are rejected on unsigned requests.
+type Key struct {
+	ID               uint64     `json:"id"`
+	Name             string     `json:"name"`
+	Prefix           string     `json:"prefix"`
+	Hash             string     `json:"-"`
+	AccountID        *uint64    `json:"account_id,omitempty"`
+	Scopes           []string   `json:"scopes"`
+	AllowedIPs       []string   `json:"allowed_ips" db:"allowed_ips"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	RequireSignature bool       `json:"require_signature" db:"require_signature"`
+	SigningSecret    string     `json:"-" db:"signing_secret"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt       *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP       *string    `json:"last_used_ip,omitempty" db:"last_used_ip"`
+	ReplacedBy       *uint64    `json:"replaced_by,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// Active reports whether the key is neither revoked nor expired.
+func (k *Key) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// AllowsIP reports whether ip is inside the allowlist. An empty list
+// allows every address.
+func (k *Key) AllowsIP(ip net.IP) bool {
+	if len(k.AllowedIPs) == 0 {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+
+	for _, allowed := range k.AllowedIPs {
+		_, network, err := net.ParseCIDR(allowed)
+		if err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SignedRequest holds the parts of a request covered by its signature.
+type SignedRequest struct {
+	Method    string
+	URI       string
+	Timestamp int64
+	Nonce     string
+	Body      []byte
+	Signature string
+}
@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"task/common"
+	"task/internal/domain/Errors"
+	"task/internal/domain/apikey/entity"
+	"time"
+)
+
+// touchInterval limits how often last-used tracking writes to the row of a
+// busy key.
+const touchInterval = time.Minute
+
+const selectKeys = `
+	SELECT id, name, prefix, hash, account_id, scopes, allowed_ips, expires_at,
+		require_signature, signing_secret, revoked_at, last_used_at, last_used_ip,
+		replaced_by, created_at
+	FROM api_key
+`
+
+type PostgresRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{
+		db: pool,
+	}
+}
+
+func (r *PostgresRepository) Save(ctx context.Context, key *entity.Key) error {
+	const op = "domain/apikey.PostgresRepository.Save"
+
+	query := `
+		INSERT INTO api_key (
+			name, prefix, hash, account_id, scopes, allowed_ips, expires_at, require_signature, signing_secret
+		) VALUES (
+			@name, @prefix, @hash, @account_id, @scopes, @allowed_ips, @expires_at, @require_signature, @signing_secret
+		)
+		RETURNING id, created_at
+	`
+
+	args := pgx.NamedArgs{
+		"name":              key.Name,
+		"prefix":            key.Prefix,
+		"hash":              key.Hash,
+		"account_id":        key.AccountID,
+		"scopes":            key.Scopes,
+		"allowed_ips":       key.AllowedIPs,
+		"expires_at":        key.ExpiresAt,
+		"require_signature": key.RequireSignature,
+		"signing_secret":    key.SigningSecret,
+	}
+
+	if err := common.Conn(ctx, r.db).QueryRow(ctx, query, args).Scan(&key.ID, &key.CreatedAt); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) Get(ctx context.Context, id uint64) (*entity.Key, error) {
+	const op = "domain/apikey.PostgresRepository.Get"
+
+	query := selectKeys + `
+		WHERE id = @id
+	`
+
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	var key entity.Key
+
+	if err := pgxscan.Get(ctx, common.Conn(ctx, r.db), &key, query, args); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, Errors.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &key, nil
+}
+
+func (r *PostgresRepository) GetByPrefix(ctx context.Context, prefix string) (*entity.Key, error) {
+	const op = "domain/apikey.PostgresRepository.GetByPrefix"
+
+	query := selectKeys + `
+		WHERE prefix = @prefix
+	`
+
+	args := pgx.NamedArgs{
+		"prefix": prefix,
+	}
+
+	var key entity.Key
+
+	if err := pgxscan.Get(ctx, common.Conn(ctx, r.db), &key, query, args); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, Errors.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &key, nil
+}
+
+func (r *PostgresRepository) List(ctx context.Context) ([]*entity.Key, error) {
+	const op = "domain/apikey.PostgresRepository.List"
+
+	query := selectKeys + `
+		ORDER BY id
+	`
+
+	var keys []*entity.Key
+
+	if err := pgxscan.Select(ctx, common.Conn(ctx, r.db), &keys, query); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return keys, nil
+}
+
+func (r *PostgresRepository) Revoke(ctx context.Context, id uint64) error {
+	const op = "domain/apikey.PostgresRepository.Revoke"
+
+	query := `
+		UPDATE api_key
+		SET revoked_at = COALESCE(revoked_at, now())
+		WHERE id = @id
+	`
+
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	tag, err := common.Conn(ctx, r.db).Exec(ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return Errors.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// Replace points the old key at its successor and makes it expire at
+// expiresAt unless it already expires earlier.
+func (r *PostgresRepository) Replace(ctx context.Context, id uint64, replacedBy uint64, expiresAt time.Time) error {
+	const op = "domain/apikey.PostgresRepository.Replace"
+
+	query := `
+		UPDATE api_key
+		SET replaced_by = @replaced_by,
+			expires_at = LEAST(COALESCE(expires_at, @expires_at), @expires_at)
+		WHERE id = @id
+	`
+
+	args := pgx.NamedArgs{
+		"id":          id,
+		"replaced_by": replacedBy,
+		"expires_at":  expiresAt,
+	}
+
+	if _, err := common.Conn(ctx, r.db).Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) Touch(ctx context.Context, id uint64, ip string) error {
+	const op = "domain/apikey.PostgresRepository.Touch"
+
+	query := `
+		UPDATE api_key
+		SET last_used_at = now(),
+			last_used_ip = @ip
+		WHERE id = @id
+			AND (last_used_at IS NULL OR last_used_at < now() - make_interval(secs => @interval) OR last_used_ip IS DISTINCT FROM @ip)
+	`
+
+	args := pgx.NamedArgs{
+		"id":       id,
+		"ip":       ip,
+		"interval": touchInterval.Seconds(),
+	}
+
+	if _, err := common.Conn(ctx, r.db).Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UseNonce records a nonce of a signed request until expiresAt and reports
+// false when the key has already used it. Expired nonces of the key are
+// purged in the same statement.
+func (r *PostgresRepository) UseNonce(ctx context.Context, keyID uint64, nonce string, expiresAt time.Time) (bool, error) {
+	const op = "domain/apikey.PostgresRepository.UseNonce"
+
+	query := `
+		WITH purged AS (
+			DELETE FROM request_nonce
+			WHERE api_key_id = @api_key_id AND expires_at < now()
+		)
+		INSERT INTO request_nonce (api_key_id, nonce, expires_at)
+		VALUES (@api_key_id, @nonce, @expires_at)
+		ON CONFLICT (api_key_id, nonce) DO NOTHING
+		RETURNING api_key_id
+	`
+
+	args := pgx.NamedArgs{
+		"api_key_id": keyID,
+		"nonce":      nonce,
+		"expires_at": expiresAt,
+	}
+
+	var id uint64
+
+	if err := common.Conn(ctx, r.db).QueryRow(ctx, query, args).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return true, nil
+}
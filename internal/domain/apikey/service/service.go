@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"task/common"
+	"task/internal/domain/Errors"
+	account "task/internal/domain/account/entity"
+	accRep "task/internal/domain/account/repository"
+	"task/internal/domain/apikey/entity"
+	"task/internal/domain/apikey/repository"
+	auth "task/internal/domain/auth/entity"
+	"task/internal/domain/auth/token"
+	rbacRep "task/internal/domain/rbac/repository"
+	"task/pkg/signing"
+	"time"
+)
+
+// KeyPrefix starts every API key, so that the authentication middleware can
+// tell keys from access tokens.
+const KeyPrefix = "tk_"
+
+// SigningSecretPrefix starts the secret that signs requests made with a key.
+const SigningSecretPrefix = "tss_"
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Repository
+type Repository interface {
+	Save(ctx context.Context, key *entity.Key) error
+	Get(ctx context.Context, id uint64) (*entity.Key, error)
+	GetByPrefix(ctx context.Context, prefix string) (*entity.Key, error)
+	List(ctx context.Context) ([]*entity.Key, error)
+	Revoke(ctx context.Context, id uint64) error
+	Replace(ctx context.Context, id uint64, replacedBy uint64, expiresAt time.Time) error
+	Touch(ctx context.Context, id uint64, ip string) error
+	UseNonce(ctx context.Context, keyID uint64, nonce string, expiresAt time.Time) (bool, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Repository_rbac
+type Repository_rbac interface {
+	PermissionExists(ctx context.Context, name string) (bool, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Repository_account
+type Repository_account interface {
+	Get(ctx context.Context, id uint64) (*account.Account, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Transactor
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type Service struct {
+	repository Repository
+	repRBAC    Repository_rbac
+	repAccount Repository_account
+	transactor Transactor
+	skew       time.Duration
+	now        func() time.Time
+}
+
+func NewService(di *common.DependencyContainer) *Service {
+	return &Service{
+		repository: repository.NewPostgresRepository(di.Pool),
+		repRBAC:    rbacRep.NewPostgresRepository(di.Pool),
+		repAccount: accRep.NewPostgresRepository(di.Pool),
+		transactor: common.NewTransactor(di.Pool),
+		skew:       di.Config.Auth.SignatureSkew,
+		now:        time.Now,
+	}
+}
+
+// Create validates and stores a new key. The returned plaintext key is not
+// kept anywhere and cannot be shown again.
+func (s *Service) Create(ctx context.Context, key *entity.Key) (*entity.Key, string, error) {
+	const op = "domain/apikey.Service.Create"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	if err := s.validate(ctx, key); err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	plain, err := s.save(ctx, key)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return key, plain, nil
+}
+
+func (s *Service) Get(ctx context.Context, id uint64) (*entity.Key, error) {
+	const op = "domain/apikey.Service.Get"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	key, err := s.repository.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return key, nil
+}
+
+func (s *Service) List(ctx context.Context) ([]*entity.Key, error) {
+	const op = "domain/apikey.Service.List"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	keys, err := s.repository.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return keys, nil
+}
+
+func (s *Service) Revoke(ctx context.Context, id uint64) error {
+	const op = "domain/apikey.Service.Revoke"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	if err := s.repository.Revoke(ctx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Rotate issues a successor with the same name, account, scopes, allowlist
+// and expiry. The old key keeps working for overlap so that clients can
+// switch without downtime.
+func (s *Service) Rotate(ctx context.Context, id uint64, overlap time.Duration) (*entity.Key, string, error) {
+	const op = "domain/apikey.Service.Rotate"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	var next *entity.Key
+	var plain string
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		old, err := s.repository.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !old.Active(s.now()) {
+			return fmt.Errorf("%w: key is revoked or expired", Errors.ErrInvalidAPIKey)
+		}
+
+		next = &entity.Key{
+			Name:             old.Name,
+			AccountID:        old.AccountID,
+			Scopes:           old.Scopes,
+			AllowedIPs:       old.AllowedIPs,
+			ExpiresAt:        old.ExpiresAt,
+			RequireSignature: old.RequireSignature,
+		}
+
+		plain, err = s.save(ctx, next)
+		if err != nil {
+			return err
+		}
+
+		return s.repository.Replace(ctx, old.ID, next.ID, s.now().Add(overlap))
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return next, plain, nil
+}
+
+// Authenticate resolves the principal of an API key presented from
+// clientIP. The key acts as its account, if any, with its scopes as
+// permissions.
+func (s *Service) Authenticate(ctx context.Context, plain string, clientIP string) (*auth.Principal, error) {
+	const op = "domain/apikey.Service.Authenticate"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	prefix, secret, ok := parse(plain)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, Errors.ErrInvalidAPIKey)
+	}
+
+	key, err := s.repository.GetByPrefix(ctx, prefix)
+	if errors.Is(err, Errors.ErrAPIKeyNotFound) {
+		return nil, fmt.Errorf("%s: %w", op, Errors.ErrInvalidAPIKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(token.Hash(secret))) != 1 {
+		return nil, fmt.Errorf("%s: %w", op, Errors.ErrInvalidAPIKey)
+	}
+	if !key.Active(s.now()) {
+		return nil, fmt.Errorf("%s: %w: revoked or expired", op, Errors.ErrInvalidAPIKey)
+	}
+	if !key.AllowsIP(net.ParseIP(clientIP)) {
+		return nil, fmt.Errorf("%s: %w: address %s is not allowed", op, Errors.ErrInvalidAPIKey, clientIP)
+	}
+
+	if err := s.repository.Touch(ctx, key.ID, clientIP); err != nil {
+		common.FromContext(ctx).Warn("cannot track api key usage", "api_key_id", key.ID, "error", err.Error())
+	}
+
+	principal := &auth.Principal{
+		APIKeyID:          key.ID,
+		SignatureRequired: key.RequireSignature,
+		Permissions:       make(map[string]bool, len(key.Scopes)),
+	}
+	if key.AccountID != nil {
+		principal.AccountID = *key.AccountID
+	}
+	for _, scope := range key.Scopes {
+		principal.Permissions[scope] = true
+	}
+
+	return principal, nil
+}
+
+// VerifySignature checks a signed request made with the key: the HMAC of
+// its parts under the signing secret, the timestamp against the clock-skew
+// window and the nonce against the ones the key has used within it.
+func (s *Service) VerifySignature(ctx context.Context, keyID uint64, req *entity.SignedRequest) error {
+	const op = "domain/apikey.Service.VerifySignature"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	key, err := s.repository.Get(ctx, keyID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if key.SigningSecret == "" {
+		return fmt.Errorf("%s: %w: key has no signing secret", op, Errors.ErrInvalidSignature)
+	}
+
+	expected := signing.Sign(key.SigningSecret, req.Method, req.URI, req.Timestamp, req.Nonce, req.Body)
+	if !signing.Equal(req.Signature, expected) {
+		return fmt.Errorf("%s: %w", op, Errors.ErrInvalidSignature)
+	}
+
+	signedAt := time.Unix(req.Timestamp, 0)
+	now := s.now()
+	if signedAt.Before(now.Add(-s.skew)) || signedAt.After(now.Add(s.skew)) {
+		return fmt.Errorf("%s: %w: timestamp is outside the allowed skew", op, Errors.ErrInvalidSignature)
+	}
+
+	// The nonce is only recorded for authentic requests, so that nobody can
+	// burn nonces of a partner. It is kept until the timestamp leaves the
+	// window, after which the request is rejected anyway.
+	fresh, err := s.repository.UseNonce(ctx, key.ID, req.Nonce, signedAt.Add(s.skew))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !fresh {
+		return fmt.Errorf("%s: %w", op, Errors.ErrReplayedRequest)
+	}
+
+	return nil
+}
+
+func (s *Service) validate(ctx context.Context, key *entity.Key) error {
+	if strings.TrimSpace(key.Name) == "" {
+		return fmt.Errorf("%w: name is required", Errors.ErrInvalidAPIKey)
+	}
+
+	if len(key.Scopes) == 0 {
+		return fmt.Errorf("%w: at least one scope is required", Errors.ErrInvalidAPIKey)
+	}
+	for _, scope := range key.Scopes {
+		exists, err := s.repRBAC.PermissionExists(ctx, scope)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: %s", Errors.ErrPermissionNotFound, scope)
+		}
+	}
+
+	allowed, err := normalizeAllowlist(key.AllowedIPs)
+	if err != nil {
+		return err
+	}
+	key.AllowedIPs = allowed
+
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(s.now()) {
+		return fmt.Errorf("%w: expiry is in the past", Errors.ErrInvalidAPIKey)
+	}
+
+	if key.AccountID != nil {
+		if _, err := s.repAccount.Get(ctx, *key.AccountID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) save(ctx context.Context, key *entity.Key) (string, error) {
+	prefix := make([]byte, 6)
+	if _, err := rand.Read(prefix); err != nil {
+		return "", err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	signingSecret := make([]byte, 32)
+	if _, err := rand.Read(signingSecret); err != nil {
+		return "", err
+	}
+
+	key.Prefix = hex.EncodeToString(prefix)
+	encoded := base64.RawURLEncoding.EncodeToString(secret)
+	key.Hash = token.Hash(encoded)
+	key.SigningSecret = SigningSecretPrefix + base64.RawURLEncoding.EncodeToString(signingSecret)
+
+	if err := s.repository.Save(ctx, key); err != nil {
+		return "", err
+	}
+
+	return KeyPrefix + key.Prefix + "_" + encoded, nil
+}
+
+// normalizeAllowlist turns single addresses into host networks.
+func normalizeAllowlist(entries []string) ([]string, error) {
+	allowed := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("%w: %q", Errors.ErrInvalidIPAllowlist, entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				bits = 32
+			}
+			entry = fmt.Sprintf("%s/%d", ip, bits)
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", Errors.ErrInvalidIPAllowlist, entry)
+		}
+		allowed = append(allowed, network.String())
+	}
+
+	return allowed, nil
+}
+
+// parse splits "tk_<prefix>_<secret>".
+func parse(plain string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(plain, KeyPrefix)
+	if !ok {
+		return "", "", false
+	}
+
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || prefix == "" || secret == "" {
+		return "", "", false
+	}
+
+	return prefix, secret, true
+}
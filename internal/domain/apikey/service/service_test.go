@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"task/internal/domain/Errors"
+	"task/internal/domain/apikey/entity"
+	"task/internal/domain/apikey/service/mocks"
+	"task/pkg/signing"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Authenticate(t *testing.T) {
+	now := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+	accountID := uint64(7)
+
+	cases := []struct {
+		name      string
+		key       entity.Key
+		clientIP  string
+		tamper    bool
+		wantErr   error
+		wantTouch bool
+	}{
+		{
+			name:      "Valid key",
+			key:       entity.Key{AccountID: &accountID, Scopes: []string{"transactions:write"}},
+			clientIP:  "10.1.2.3",
+			wantTouch: true,
+		},
+		{
+			name:      "Allowed network",
+			key:       entity.Key{Scopes: []string{"accounts:read"}, AllowedIPs: []string{"10.0.0.0/8"}},
+			clientIP:  "10.1.2.3",
+			wantTouch: true,
+		},
+		{
+			name:     "Address outside allowlist",
+			key:      entity.Key{Scopes: []string{"accounts:read"}, AllowedIPs: []string{"10.0.0.0/8"}},
+			clientIP: "192.168.0.1",
+			wantErr:  Errors.ErrInvalidAPIKey,
+		},
+		{
+			name:     "Expired",
+			key:      entity.Key{Scopes: []string{"accounts:read"}, ExpiresAt: &past},
+			clientIP: "10.1.2.3",
+			wantErr:  Errors.ErrInvalidAPIKey,
+		},
+		{
+			name:     "Revoked",
+			key:      entity.Key{Scopes: []string{"accounts:read"}, RevokedAt: &past},
+			clientIP: "10.1.2.3",
+			wantErr:  Errors.ErrInvalidAPIKey,
+		},
+		{
+			name:     "Wrong secret",
+			key:      entity.Key{Scopes: []string{"accounts:read"}},
+			clientIP: "10.1.2.3",
+			tamper:   true,
+			wantErr:  Errors.ErrInvalidAPIKey,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			rep := mocks.NewRepository(t)
+
+			key := tc.key
+			key.ID = 1
+
+			rep.On("Save", ctx, mock.AnythingOfType("*entity.Key")).Return(nil)
+
+			s := &Service{repository: rep, now: func() time.Time { return now }}
+
+			plain, err := s.save(ctx, &key)
+			require.NoError(t, err)
+
+			if tc.tamper {
+				plain += "x"
+			}
+
+			rep.On("GetByPrefix", ctx, key.Prefix).Return(&key, nil)
+			if tc.wantTouch {
+				rep.On("Touch", ctx, uint64(1), tc.clientIP).Return(nil)
+			}
+
+			principal, err := s.Authenticate(ctx, plain, tc.clientIP)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, uint64(1), principal.APIKeyID)
+			for _, scope := range tc.key.Scopes {
+				require.True(t, principal.Can(scope))
+			}
+			if tc.key.AccountID != nil {
+				require.Equal(t, *tc.key.AccountID, principal.AccountID)
+			}
+		})
+	}
+}
+
+func TestService_Create(t *testing.T) {
+	ctx := context.Background()
+	rep := mocks.NewRepository(t)
+	rbac := mocks.NewRepository_rbac(t)
+
+	rbac.On("PermissionExists", ctx, "transactions:write").Return(true, nil)
+	rbac.On("PermissionExists", ctx, "transactions:teleport").Return(false, nil)
+	rep.On("Save", ctx, mock.MatchedBy(func(k *entity.Key) bool {
+		return len(k.Hash) == 64 && k.AllowedIPs[0] == "192.168.1.10/32" && k.AllowedIPs[1] == "10.0.0.0/8"
+	})).Return(nil)
+
+	s := &Service{repository: rep, repRBAC: rbac, now: time.Now}
+
+	key, plain, err := s.Create(ctx, &entity.Key{
+		Name:       "reconciliation job",
+		Scopes:     []string{"transactions:write"},
+		AllowedIPs: []string{"192.168.1.10", "10.0.0.0/8"},
+	})
+	require.NoError(t, err)
+	require.Contains(t, plain, KeyPrefix+key.Prefix+"_")
+	require.NotContains(t, key.Hash, plain)
+
+	_, _, err = s.Create(ctx, &entity.Key{Name: "bad", Scopes: []string{"transactions:teleport"}})
+	require.ErrorIs(t, err, Errors.ErrPermissionNotFound)
+
+	_, _, err = s.Create(ctx, &entity.Key{Name: "bad", Scopes: []string{"transactions:write"}, AllowedIPs: []string{"not-an-ip"}})
+	require.ErrorIs(t, err, Errors.ErrInvalidIPAllowlist)
+}
+
+func TestService_VerifySignature(t *testing.T) {
+	now := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"account_id":7,"amount":100}`)
+
+	cases := []struct {
+		name      string
+		signedAt  time.Time
+		secret    string
+		keySecret string
+		seen      bool
+		wantNonce bool
+		wantErr   error
+	}{
+		{
+			name:      "Valid signature",
+			signedAt:  now.Add(-time.Minute),
+			secret:    "tss_secret",
+			keySecret: "tss_secret",
+			wantNonce: true,
+		},
+		{
+			name:      "Replayed nonce",
+			signedAt:  now,
+			secret:    "tss_secret",
+			keySecret: "tss_secret",
+			seen:      true,
+			wantNonce: true,
+			wantErr:   Errors.ErrReplayedRequest,
+		},
+		{
+			name:      "Outside skew window",
+			signedAt:  now.Add(-10 * time.Minute),
+			secret:    "tss_secret",
+			keySecret: "tss_secret",
+			wantErr:   Errors.ErrInvalidSignature,
+		},
+		{
+			name:      "Wrong secret",
+			signedAt:  now,
+			secret:    "tss_other",
+			keySecret: "tss_secret",
+			wantErr:   Errors.ErrInvalidSignature,
+		},
+		{
+			name:     "Key without signing secret",
+			signedAt: now,
+			secret:   "",
+			wantErr:  Errors.ErrInvalidSignature,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			rep := mocks.NewRepository(t)
+
+			rep.On("Get", ctx, uint64(1)).Return(&entity.Key{ID: 1, SigningSecret: tc.keySecret}, nil)
+			if tc.wantNonce {
+				rep.On("UseNonce", ctx, uint64(1), "nonce-1", time.Unix(tc.signedAt.Unix(), 0).Add(5*time.Minute)).Return(!tc.seen, nil)
+			}
+
+			s := &Service{repository: rep, skew: 5 * time.Minute, now: func() time.Time { return now }}
+
+			timestamp := tc.signedAt.Unix()
+			err := s.VerifySignature(ctx, 1, &entity.SignedRequest{
+				Method:    "POST",
+				URI:       "/transaction/deposit",
+				Timestamp: timestamp,
+				Nonce:     "nonce-1",
+				Body:      body,
+				Signature: signing.Version + "=" + signing.Sign(tc.secret, "POST", "/transaction/deposit", timestamp, "nonce-1", body),
+			})
+
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
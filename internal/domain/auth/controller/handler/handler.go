@@ -0,0 +1,377 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"net/http"
+	"strconv"
+	mw "task/internal/api/middleware"
+	"task/internal/api/problem"
+	"task/internal/api/validate"
+	"task/internal/domain/Errors"
+	"task/internal/domain/auth/controller/request"
+	auth "task/internal/domain/auth/entity"
+	"task/internal/domain/auth/password"
+	"task/internal/domain/auth/service"
+	"task/internal/i18n"
+)
+
+type Handlers struct {
+	service *service.Service
+}
+
+func NewHandlers(service *service.Service) *Handlers {
+	return &Handlers{
+		service: service,
+	}
+}
+
+func (h *Handlers) Login(w http.ResponseWriter, r *http.Request) error {
+	const op = "auth.Handlers.Login"
+	ctx := r.Context()
+
+	var req request.RequestLogin
+
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	pair, err := h.service.Login(ctx, req.AccountID, req.Password, device(r))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseTokenOK(w, r, pair)
+
+	return nil
+}
+
+func (h *Handlers) Refresh(w http.ResponseWriter, r *http.Request) error {
+	const op = "auth.Handlers.Refresh"
+	ctx := r.Context()
+
+	var req request.RequestRefresh
+
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	pair, err := h.service.Refresh(ctx, req.RefreshToken, device(r))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseTokenOK(w, r, pair)
+
+	return nil
+}
+
+// ListSessions lists the active sessions of the caller's account.
+func (h *Handlers) ListSessions(w http.ResponseWriter, r *http.Request) error {
+	const op = "auth.Handlers.ListSessions"
+	ctx := r.Context()
+
+	accountID, ok := callerAccount(r)
+	if !ok {
+		return fmt.Errorf("%s: %w", op, Errors.ErrForbidden)
+	}
+
+	sessions, err := h.service.ListSessions(ctx, accountID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseSessionsOK(w, r, sessions)
+
+	return nil
+}
+
+func (h *Handlers) RevokeSession(w http.ResponseWriter, r *http.Request) error {
+	const op = "auth.Handlers.RevokeSession"
+	ctx := r.Context()
+
+	accountID, ok := callerAccount(r)
+	if !ok {
+		return fmt.Errorf("%s: %w", op, Errors.ErrForbidden)
+	}
+
+	id, err := GetIDFromRequest(r, "session_id")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = h.service.RevokeSession(ctx, accountID, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseOK(w, r)
+
+	return nil
+}
+
+// RevokeSessions logs the caller's account out of every session.
+func (h *Handlers) RevokeSessions(w http.ResponseWriter, r *http.Request) error {
+	const op = "auth.Handlers.RevokeSessions"
+	ctx := r.Context()
+
+	accountID, ok := callerAccount(r)
+	if !ok {
+		return fmt.Errorf("%s: %w", op, Errors.ErrForbidden)
+	}
+
+	revoked, err := h.service.RevokeSessions(ctx, accountID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseRevokedOK(w, r, revoked)
+
+	return nil
+}
+
+// SendVerification mails a new verification link to the caller's address.
+func (h *Handlers) SendVerification(w http.ResponseWriter, r *http.Request) error {
+	const op = "auth.Handlers.SendVerification"
+	ctx := r.Context()
+
+	accountID, ok := callerAccount(r)
+	if !ok {
+		return fmt.Errorf("%s: %w", op, Errors.ErrForbidden)
+	}
+
+	if err := h.service.SendVerification(ctx, accountID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseOK(w, r)
+
+	return nil
+}
+
+func (h *Handlers) VerifyEmail(w http.ResponseWriter, r *http.Request) error {
+	const op = "auth.Handlers.VerifyEmail"
+	ctx := r.Context()
+
+	var req request.RequestVerifyEmail
+
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err := h.service.VerifyEmail(ctx, req.Token)
+	if errors.Is(err, Errors.ErrInvalidToken) {
+		return fmt.Errorf("%s: %w", op, problem.Wrap(err, http.StatusBadRequest, "error.invalid_or_expired_token", nil))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseOK(w, r)
+
+	return nil
+}
+
+func (h *Handlers) ChangePassword(w http.ResponseWriter, r *http.Request) error {
+	const op = "auth.Handlers.ChangePassword"
+	ctx := r.Context()
+
+	accountID, ok := callerAccount(r)
+	if !ok {
+		return fmt.Errorf("%s: %w", op, Errors.ErrForbidden)
+	}
+
+	var req request.RequestChangePassword
+
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err := h.service.ChangePassword(ctx, accountID, req.CurrentPassword, req.NewPassword)
+	if errors.Is(err, Errors.ErrWeakPassword) {
+		return fmt.Errorf("%s: %w", op, problem.Wrap(err, 0, "error.password_policy", i18n.Args{"min": password.MinLength, "max": password.MaxLength}))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseOK(w, r)
+
+	return nil
+}
+
+// ForgotPassword answers the same way whether the address is known or not.
+func (h *Handlers) ForgotPassword(w http.ResponseWriter, r *http.Request) error {
+	const op = "auth.Handlers.ForgotPassword"
+	ctx := r.Context()
+
+	var req request.RequestForgotPassword
+
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := h.service.RequestPasswordReset(ctx, req.Email); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	render.Status(r, http.StatusAccepted)
+	request.ResponseOK(w, r)
+
+	return nil
+}
+
+func (h *Handlers) ResetPassword(w http.ResponseWriter, r *http.Request) error {
+	const op = "auth.Handlers.ResetPassword"
+	ctx := r.Context()
+
+	var req request.RequestResetPassword
+
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err := h.service.ResetPassword(ctx, req.Token, req.Password)
+	if errors.Is(err, Errors.ErrInvalidToken) {
+		return fmt.Errorf("%s: %w", op, problem.Wrap(err, http.StatusBadRequest, "error.invalid_or_expired_token", nil))
+	}
+	if errors.Is(err, Errors.ErrWeakPassword) {
+		return fmt.Errorf("%s: %w", op, problem.Wrap(err, 0, "error.password_policy", i18n.Args{"min": password.MinLength, "max": password.MaxLength}))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseOK(w, r)
+
+	return nil
+}
+
+// EnrollTOTP returns a new authenticator secret to confirm with a code.
+func (h *Handlers) EnrollTOTP(w http.ResponseWriter, r *http.Request) error {
+	const op = "auth.Handlers.EnrollTOTP"
+	ctx := r.Context()
+
+	accountID, ok := callerAccount(r)
+	if !ok {
+		return fmt.Errorf("%s: %w", op, Errors.ErrForbidden)
+	}
+
+	enrollment, err := h.service.EnrollTOTP(ctx, accountID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseEnrollmentOK(w, r, enrollment)
+
+	return nil
+}
+
+func (h *Handlers) ConfirmTOTP(w http.ResponseWriter, r *http.Request) error {
+	const op = "auth.Handlers.ConfirmTOTP"
+	ctx := r.Context()
+
+	accountID, ok := callerAccount(r)
+	if !ok {
+		return fmt.Errorf("%s: %w", op, Errors.ErrForbidden)
+	}
+
+	var req request.RequestOTP
+
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	codes, err := h.service.ConfirmTOTP(ctx, accountID, req.Code)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseRecoveryCodesOK(w, r, codes)
+
+	return nil
+}
+
+func (h *Handlers) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) error {
+	const op = "auth.Handlers.RegenerateRecoveryCodes"
+	ctx := r.Context()
+
+	accountID, ok := callerAccount(r)
+	if !ok {
+		return fmt.Errorf("%s: %w", op, Errors.ErrForbidden)
+	}
+
+	var req request.RequestOTP
+
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodes(ctx, accountID, req.Code)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseRecoveryCodesOK(w, r, codes)
+
+	return nil
+}
+
+func (h *Handlers) DisableTOTP(w http.ResponseWriter, r *http.Request) error {
+	const op = "auth.Handlers.DisableTOTP"
+	ctx := r.Context()
+
+	accountID, ok := callerAccount(r)
+	if !ok {
+		return fmt.Errorf("%s: %w", op, Errors.ErrForbidden)
+	}
+
+	var req request.RequestDisableTOTP
+
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err := h.service.DisableTOTP(ctx, accountID, req.Password, req.Code)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseOK(w, r)
+
+	return nil
+}
+
+func device(r *http.Request) auth.Device {
+	return auth.Device{
+		UserAgent: r.UserAgent(),
+		IP:        mw.ClientIP(r),
+	}
+}
+
+// callerAccount is the account of a caller logged in with a token. API
+// keys do not manage credentials of their account.
+func callerAccount(r *http.Request) (uint64, bool) {
+	principal, ok := auth.PrincipalFromContext(r.Context())
+	if !ok || principal.APIKeyID != 0 || principal.AccountID == 0 {
+		return 0, false
+	}
+
+	return principal.AccountID, true
+}
+
+func GetIDFromRequest(r *http.Request, key string) (uint64, error) {
+	param := chi.URLParam(r, key)
+	if param == "" {
+		return 0, fmt.Errorf("%w: empty parameter %s", Errors.ErrIncorrectID, key)
+	}
+
+	value, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid parameter %s", Errors.ErrIncorrectID, key)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,146 @@
+package request
+
+import (
+	"net/http"
+	"task/internal/api/response"
+	"task/internal/domain/auth/entity"
+	"task/internal/domain/auth/token"
+)
+
+const TokenTypeBearer = "Bearer"
+
+type RequestLogin struct {
+	AccountID uint64 `json:"account_id" validate:"required"`
+	Password  string `json:"password" validate:"required"`
+}
+
+type RequestRefresh struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type RequestVerifyEmail struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type RequestChangePassword struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,max=256"`
+}
+
+type RequestForgotPassword struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+type RequestResetPassword struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,max=256"`
+}
+
+type RequestOTP struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+type RequestDisableTOTP struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required,max=32"`
+}
+
+type ResponseEnrollment struct {
+	response.Response
+	*entity.Enrollment
+}
+
+type ResponseRecoveryCodes struct {
+	response.Response
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type ResponseSessions struct {
+	response.Response
+	Sessions []*entity.Session `json:"sessions"`
+}
+
+type Revoked struct {
+	Revoked int64 `json:"revoked"`
+}
+
+type ResponseRevoked struct {
+	response.Response
+	Revoked
+}
+
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type ResponseToken struct {
+	response.Response
+	Token
+}
+
+func ResponseTokenOK(w http.ResponseWriter, r *http.Request, pair *token.Pair) {
+	t := Token{
+		AccessToken:  pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		TokenType:    TokenTypeBearer,
+		ExpiresIn:    int64(pair.ExpiresIn.Seconds()),
+	}
+
+	response.Render(w, r, t, ResponseToken{
+		Response: response.Response{
+			Status: response.StatusSuccess,
+		},
+		Token: t,
+	})
+}
+
+func ResponseOK(w http.ResponseWriter, r *http.Request) {
+	response.Render(w, r, nil, response.Response{
+		Status: response.StatusSuccess,
+	})
+}
+
+func ResponseEnrollmentOK(w http.ResponseWriter, r *http.Request, enrollment *entity.Enrollment) {
+	response.Render(w, r, enrollment, ResponseEnrollment{
+		Response: response.Response{
+			Status: response.StatusSuccess,
+		},
+		Enrollment: enrollment,
+	})
+}
+
+// ResponseRecoveryCodesOK is the only response that carries the recovery
+// codes.
+func ResponseRecoveryCodesOK(w http.ResponseWriter, r *http.Request, codes []string) {
+	response.Render(w, r, codes, ResponseRecoveryCodes{
+		Response: response.Response{
+			Status: response.StatusSuccess,
+		},
+		RecoveryCodes: codes,
+	})
+}
+
+func ResponseSessionsOK(w http.ResponseWriter, r *http.Request, sessions []*entity.Session) {
+	if sessions == nil {
+		sessions = []*entity.Session{}
+	}
+
+	response.Render(w, r, sessions, ResponseSessions{
+		Response: response.Response{
+			Status: response.StatusSuccess,
+		},
+		Sessions: sessions,
+	})
+}
+
+func ResponseRevokedOK(w http.ResponseWriter, r *http.Request, revoked int64) {
+	response.Render(w, r, Revoked{Revoked: revoked}, ResponseRevoked{
+		Response: response.Response{
+			Status: response.StatusSuccess,
+		},
+		Revoked: Revoked{Revoked: revoked},
+	})
+}
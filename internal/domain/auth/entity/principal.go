@@ -0,0 +1,39 @@
+package entity
+
+import "context"
+
+// Principal is the authenticated caller of a request: an account logged in
+// with a token, or an API key acting with its scopes.
+type Principal struct {
+	AccountID   uint64
+	APIKeyID    uint64
+	Roles       []string
+	Permissions map[string]bool
+	// SignatureRequired is set for API keys that may only make signed
+	// requests.
+	SignatureRequired bool
+	// SessionID is the session of a token; zero for API keys.
+	SessionID uint64
+}
+
+// Can reports whether one of the principal's roles grants the permission.
+func (p *Principal) Can(permission string) bool {
+	return p.Permissions[permission]
+}
+
+// CanAccess reports whether the principal may act on the account: its own
+// one, or any account when anyPermission is granted.
+func (p *Principal) CanAccess(accountID uint64, anyPermission string) bool {
+	return p.AccountID == accountID || p.Can(anyPermission)
+}
+
+type principalCtxKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalCtxKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalCtxKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
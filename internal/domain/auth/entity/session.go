@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// Session is one login of an account, kept alive by rotating its refresh
+// token. RefreshID is the id of the only refresh token the session still
+// accepts; an older one being presented means it was copied.
+type Session struct {
+	ID         uint64     `json:"id"`
+	AccountID  uint64     `json:"account_id"`
+	RefreshID  string     `json:"-"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	Current    bool       `json:"current" db:"-"`
+}
+
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// Device describes the client a session was created or last refreshed
+// from.
+type Device struct {
+	UserAgent string
+	IP        string
+}
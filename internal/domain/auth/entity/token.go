@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+const (
+	PurposeVerifyEmail   = "verify_email"
+	PurposeResetPassword = "reset_password"
+)
+
+// AccountToken is a single-use token sent by email. Only its SHA-256 hash
+// is stored.
+type AccountToken struct {
+	ID        uint64     `json:"id"`
+	AccountID uint64     `json:"account_id"`
+	Purpose   string     `json:"purpose"`
+	Hash      string     `json:"-"`
+	Email     string     `json:"email"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"context"
+	"time"
+)
+
+// TOTP is the authenticator of an account. It protects nothing until it is
+// confirmed with a first code. LastStep is the time step of the last
+// accepted code, which is never accepted again.
+type TOTP struct {
+	AccountID   uint64     `json:"account_id"`
+	Secret      string     `json:"-"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty"`
+	LastStep    int64      `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (t *TOTP) Enabled() bool {
+	return t.ConfirmedAt != nil
+}
+
+// Enrollment is shown once, when an authenticator is enrolled.
+type Enrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type otpCtxKey struct{}
+
+// WithOTP stores the one-time code presented with a request, for the
+// step-up check of high-value operations.
+func WithOTP(ctx context.Context, code string) context.Context {
+	return context.WithValue(ctx, otpCtxKey{}, code)
+}
+
+func OTPFromContext(ctx context.Context) string {
+	code, _ := ctx.Value(otpCtxKey{}).(string)
+	return code
+}
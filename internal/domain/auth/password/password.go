@@ -0,0 +1,155 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"task/internal/domain/Errors"
+)
+
+// Argon2id parameters for new hashes. Hashes made with other parameters
+// still verify and are reported for rehashing.
+const (
+	argonTime    uint32 = 3
+	argonMemory  uint32 = 64 * 1024
+	argonThreads uint8  = 2
+	argonKeyLen  uint32 = 32
+	saltLen             = 16
+)
+
+const argonPrefix = "$argon2id$"
+
+// Bounds for new passwords. Passwords stored before the policy existed keep
+// working.
+const (
+	MinLength = 8
+	MaxLength = 256
+)
+
+var ErrMalformedHash = errors.New("malformed password hash")
+
+// Hash returns the argon2id hash of plain in PHC string format.
+func Hash(plain string) (string, error) {
+	const op = "domain/auth/password.Hash"
+
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	key := argon2.IDKey([]byte(plain), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+
+	return fmt.Sprintf(
+		"%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argonPrefix,
+		argon2.Version,
+		argonMemory,
+		argonTime,
+		argonThreads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Validate checks a new password against the policy.
+func Validate(plain string) error {
+	switch n := len([]rune(plain)); {
+	case n < MinLength:
+		return fmt.Errorf("%w: at least %d characters are required", Errors.ErrWeakPassword, MinLength)
+	case n > MaxLength:
+		return fmt.Errorf("%w: at most %d characters are allowed", Errors.ErrWeakPassword, MaxLength)
+	case strings.TrimSpace(plain) == "":
+		return fmt.Errorf("%w: blank password", Errors.ErrWeakPassword)
+	}
+
+	return nil
+}
+
+// Verify reports whether plain matches the stored value and whether the
+// stored value should be replaced by a fresh Hash. Besides argon2id it
+// accepts bcrypt hashes and legacy plaintext rows, both of which always
+// need a rehash.
+func Verify(stored string, plain string) (ok bool, rehash bool, err error) {
+	const op = "domain/auth/password.Verify"
+
+	switch {
+	case strings.HasPrefix(stored, argonPrefix):
+		ok, rehash, err = verifyArgon(stored, plain)
+		if err != nil {
+			return false, false, fmt.Errorf("%s: %w", op, err)
+		}
+		return ok, rehash, nil
+
+	case isBcrypt(stored):
+		err = bcrypt.CompareHashAndPassword([]byte(stored), []byte(plain))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		if err != nil {
+			return false, false, fmt.Errorf("%s: %w", op, err)
+		}
+		return true, true, nil
+
+	default:
+		ok = subtle.ConstantTimeCompare([]byte(stored), []byte(plain)) == 1
+		return ok, ok, nil
+	}
+}
+
+// IsHashed reports whether stored is a hash rather than a plaintext password.
+func IsHashed(stored string) bool {
+	return strings.HasPrefix(stored, argonPrefix) || isBcrypt(stored)
+}
+
+func verifyArgon(stored string, plain string) (bool, bool, error) {
+	// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>
+	parts := strings.Split(stored, "$")
+	if len(parts) != 6 {
+		return false, false, ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false, ErrMalformedHash
+	}
+
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false, false, ErrMalformedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, ErrMalformedHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false, false, ErrMalformedHash
+	}
+
+	actual := argon2.IDKey([]byte(plain), salt, time, memory, threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, actual) != 1 {
+		return false, false, nil
+	}
+
+	rehash := memory != argonMemory ||
+		time != argonTime ||
+		threads != argonThreads ||
+		uint32(len(key)) != argonKeyLen ||
+		len(salt) != saltLen
+
+	return true, rehash, nil
+}
+
+func isBcrypt(stored string) bool {
+	return strings.HasPrefix(stored, "$2a$") ||
+		strings.HasPrefix(stored, "$2b$") ||
+		strings.HasPrefix(stored, "$2y$")
+}
@@ -0,0 +1,72 @@
+package password
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHash(t *testing.T) {
+	hash, err := Hash("qwerty1")
+	require.NoError(t, err)
+	require.True(t, IsHashed(hash))
+
+	other, err := Hash("qwerty1")
+	require.NoError(t, err)
+	require.NotEqual(t, hash, other)
+}
+
+func TestVerify(t *testing.T) {
+	argon, err := Hash("qwerty1")
+	require.NoError(t, err)
+
+	salt := []byte("somesaltsomesalt")
+	weak := fmt.Sprintf(
+		"$argon2id$v=19$m=1024,t=1,p=1$%s$%s",
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(argon2.IDKey([]byte("qwerty1"), salt, 1, 1024, 1, 32)),
+	)
+
+	bcrypted, err := bcrypt.GenerateFromPassword([]byte("qwerty1"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name       string
+		stored     string
+		plain      string
+		wantOK     bool
+		wantRehash bool
+		wantErr    bool
+	}{
+		{name: "Argon2id", stored: argon, plain: "qwerty1", wantOK: true},
+		{name: "Argon2id mismatch", stored: argon, plain: "qwerty2"},
+		{name: "Argon2id outdated parameters", stored: weak, plain: "qwerty1", wantOK: true, wantRehash: true},
+		{name: "Bcrypt", stored: string(bcrypted), plain: "qwerty1", wantOK: true, wantRehash: true},
+		{name: "Bcrypt mismatch", stored: string(bcrypted), plain: "qwerty2"},
+		{name: "Plaintext", stored: "qwerty1", plain: "qwerty1", wantOK: true, wantRehash: true},
+		{name: "Plaintext mismatch", stored: "qwerty1", plain: "qwerty2"},
+		{name: "Malformed", stored: "$argon2id$v=19$broken", plain: "qwerty1", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ok, rehash, err := Verify(tc.stored, tc.plain)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.wantOK, ok)
+			require.Equal(t, tc.wantRehash, rehash)
+		})
+	}
+}
@@ -0,0 +1,450 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"task/common"
+	"task/internal/domain/Errors"
+	"task/internal/domain/auth/entity"
+)
+
+type PostgresRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{
+		db: pool,
+	}
+}
+
+func (r *PostgresRepository) SaveToken(ctx context.Context, token *entity.AccountToken) error {
+	const op = "domain/auth.PostgresRepository.SaveToken"
+
+	query := `
+		INSERT INTO account_token (account_id, purpose, hash, email, expires_at)
+		VALUES (@account_id, @purpose, @hash, @email, @expires_at)
+		RETURNING id, created_at
+	`
+
+	args := pgx.NamedArgs{
+		"account_id": token.AccountID,
+		"purpose":    token.Purpose,
+		"hash":       token.Hash,
+		"email":      token.Email,
+		"expires_at": token.ExpiresAt,
+	}
+
+	if err := common.Conn(ctx, r.db).QueryRow(ctx, query, args).Scan(&token.ID, &token.CreatedAt); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ConsumeToken marks an unused, unexpired token as used and returns it.
+// Concurrent consumers race on the update, so only one of them wins.
+func (r *PostgresRepository) ConsumeToken(ctx context.Context, purpose string, hash string) (*entity.AccountToken, error) {
+	const op = "domain/auth.PostgresRepository.ConsumeToken"
+
+	query := `
+		UPDATE account_token
+		SET used_at = now()
+		WHERE purpose = @purpose
+			AND hash = @hash
+			AND used_at IS NULL
+			AND expires_at > now()
+		RETURNING id, account_id, purpose, hash, email, expires_at, used_at, created_at
+	`
+
+	args := pgx.NamedArgs{
+		"purpose": purpose,
+		"hash":    hash,
+	}
+
+	var token entity.AccountToken
+
+	if err := pgxscan.Get(ctx, common.Conn(ctx, r.db), &token, query, args); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, Errors.ErrInvalidToken
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &token, nil
+}
+
+// RevokeTokens uses up the outstanding tokens of the account, e.g. every
+// reset link once the password has changed.
+func (r *PostgresRepository) RevokeTokens(ctx context.Context, accountID uint64, purpose string) error {
+	const op = "domain/auth.PostgresRepository.RevokeTokens"
+
+	query := `
+		UPDATE account_token
+		SET used_at = now()
+		WHERE account_id = @account_id
+			AND purpose = @purpose
+			AND used_at IS NULL
+	`
+
+	args := pgx.NamedArgs{
+		"account_id": accountID,
+		"purpose":    purpose,
+	}
+
+	if _, err := common.Conn(ctx, r.db).Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// SaveTOTP stores a pending authenticator, replacing a pending one. A
+// confirmed authenticator has to be disabled first.
+func (r *PostgresRepository) SaveTOTP(ctx context.Context, totp *entity.TOTP) error {
+	const op = "domain/auth.PostgresRepository.SaveTOTP"
+
+	query := `
+		INSERT INTO account_totp (account_id, secret)
+		VALUES (@account_id, @secret)
+		ON CONFLICT (account_id) DO UPDATE
+			SET secret = EXCLUDED.secret,
+				last_step = 0,
+				created_at = now()
+			WHERE account_totp.confirmed_at IS NULL
+		RETURNING created_at
+	`
+
+	args := pgx.NamedArgs{
+		"account_id": totp.AccountID,
+		"secret":     totp.Secret,
+	}
+
+	if err := common.Conn(ctx, r.db).QueryRow(ctx, query, args).Scan(&totp.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Errors.ErrTOTPEnabled
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) GetTOTP(ctx context.Context, accountID uint64) (*entity.TOTP, error) {
+	const op = "domain/auth.PostgresRepository.GetTOTP"
+
+	query := `
+		SELECT account_id, secret, confirmed_at, last_step, created_at FROM account_totp
+		WHERE account_id = @account_id
+	`
+
+	args := pgx.NamedArgs{
+		"account_id": accountID,
+	}
+
+	var totp entity.TOTP
+
+	if err := pgxscan.Get(ctx, common.Conn(ctx, r.db), &totp, query, args); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, Errors.ErrTOTPNotEnabled
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &totp, nil
+}
+
+func (r *PostgresRepository) ConfirmTOTP(ctx context.Context, accountID uint64, step int64) error {
+	const op = "domain/auth.PostgresRepository.ConfirmTOTP"
+
+	query := `
+		UPDATE account_totp
+		SET confirmed_at = now(),
+			last_step = @step
+		WHERE account_id = @account_id AND confirmed_at IS NULL
+	`
+
+	args := pgx.NamedArgs{
+		"account_id": accountID,
+		"step":       step,
+	}
+
+	tag, err := common.Conn(ctx, r.db).Exec(ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return Errors.ErrTOTPEnabled
+	}
+
+	return nil
+}
+
+// UseTOTPStep records the step of an accepted code and reports false when
+// that step or a later one was used already.
+func (r *PostgresRepository) UseTOTPStep(ctx context.Context, accountID uint64, step int64) (bool, error) {
+	const op = "domain/auth.PostgresRepository.UseTOTPStep"
+
+	query := `
+		UPDATE account_totp
+		SET last_step = @step
+		WHERE account_id = @account_id AND last_step < @step
+	`
+
+	args := pgx.NamedArgs{
+		"account_id": accountID,
+		"step":       step,
+	}
+
+	tag, err := common.Conn(ctx, r.db).Exec(ctx, query, args)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *PostgresRepository) DeleteTOTP(ctx context.Context, accountID uint64) error {
+	const op = "domain/auth.PostgresRepository.DeleteTOTP"
+
+	query := `
+		WITH codes AS (
+			DELETE FROM account_recovery_code WHERE account_id = @account_id
+		)
+		DELETE FROM account_totp
+		WHERE account_id = @account_id
+	`
+
+	args := pgx.NamedArgs{
+		"account_id": accountID,
+	}
+
+	if _, err := common.Conn(ctx, r.db).Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes drops the codes of the account, used or not, and
+// stores the new hashes.
+func (r *PostgresRepository) ReplaceRecoveryCodes(ctx context.Context, accountID uint64, hashes []string) error {
+	const op = "domain/auth.PostgresRepository.ReplaceRecoveryCodes"
+
+	query := `
+		WITH codes AS (
+			DELETE FROM account_recovery_code WHERE account_id = @account_id
+		)
+		INSERT INTO account_recovery_code (account_id, hash)
+		SELECT @account_id, unnest(@hashes::text[])
+	`
+
+	args := pgx.NamedArgs{
+		"account_id": accountID,
+		"hashes":     hashes,
+	}
+
+	if _, err := common.Conn(ctx, r.db).Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks an unused code as used and reports whether there
+// was one.
+func (r *PostgresRepository) UseRecoveryCode(ctx context.Context, accountID uint64, hash string) (bool, error) {
+	const op = "domain/auth.PostgresRepository.UseRecoveryCode"
+
+	query := `
+		UPDATE account_recovery_code
+		SET used_at = now()
+		WHERE account_id = @account_id AND hash = @hash AND used_at IS NULL
+	`
+
+	args := pgx.NamedArgs{
+		"account_id": accountID,
+		"hash":       hash,
+	}
+
+	tag, err := common.Conn(ctx, r.db).Exec(ctx, query, args)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *PostgresRepository) CreateSession(ctx context.Context, session *entity.Session) error {
+	const op = "domain/auth.PostgresRepository.CreateSession"
+
+	query := `
+		INSERT INTO session (account_id, refresh_id, user_agent, ip, expires_at)
+		VALUES (@account_id, @refresh_id, @user_agent, @ip, @expires_at)
+		RETURNING id, created_at, last_used_at
+	`
+
+	args := pgx.NamedArgs{
+		"account_id": session.AccountID,
+		"refresh_id": session.RefreshID,
+		"user_agent": session.UserAgent,
+		"ip":         session.IP,
+		"expires_at": session.ExpiresAt,
+	}
+
+	row := common.Conn(ctx, r.db).QueryRow(ctx, query, args)
+	if err := row.Scan(&session.ID, &session.CreatedAt, &session.LastUsedAt); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RotateSession moves an active session from the refresh token previousID
+// to session.RefreshID and records the device and new expiry. It reports
+// false when previousID is not the latest token of an active session, so
+// concurrent refreshes with one token have a single winner.
+func (r *PostgresRepository) RotateSession(ctx context.Context, session *entity.Session, previousID string) (bool, error) {
+	const op = "domain/auth.PostgresRepository.RotateSession"
+
+	query := `
+		UPDATE session
+		SET refresh_id = @refresh_id,
+			user_agent = @user_agent,
+			ip = @ip,
+			expires_at = @expires_at,
+			last_used_at = now()
+		WHERE id = @id
+			AND account_id = @account_id
+			AND refresh_id = @previous_id
+			AND revoked_at IS NULL
+			AND expires_at > now()
+	`
+
+	args := pgx.NamedArgs{
+		"id":          session.ID,
+		"account_id":  session.AccountID,
+		"refresh_id":  session.RefreshID,
+		"previous_id": previousID,
+		"user_agent":  session.UserAgent,
+		"ip":          session.IP,
+		"expires_at":  session.ExpiresAt,
+	}
+
+	tag, err := common.Conn(ctx, r.db).Exec(ctx, query, args)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *PostgresRepository) GetSession(ctx context.Context, id uint64) (*entity.Session, error) {
+	const op = "domain/auth.PostgresRepository.GetSession"
+
+	query := `
+		SELECT id, account_id, refresh_id, user_agent, ip, created_at, last_used_at, expires_at, revoked_at FROM session
+		WHERE id = @id
+	`
+
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	var session entity.Session
+
+	if err := pgxscan.Get(ctx, common.Conn(ctx, r.db), &session, query, args); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, Errors.ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &session, nil
+}
+
+// ListSessions returns the active sessions of the account, most recently
+// used first.
+func (r *PostgresRepository) ListSessions(ctx context.Context, accountID uint64) ([]*entity.Session, error) {
+	const op = "domain/auth.PostgresRepository.ListSessions"
+
+	query := `
+		SELECT id, account_id, refresh_id, user_agent, ip, created_at, last_used_at, expires_at, revoked_at FROM session
+		WHERE account_id = @account_id
+			AND revoked_at IS NULL
+			AND expires_at > now()
+		ORDER BY last_used_at DESC, id DESC
+	`
+
+	args := pgx.NamedArgs{
+		"account_id": accountID,
+	}
+
+	var sessions []*entity.Session
+
+	if err := pgxscan.Select(ctx, common.Conn(ctx, r.db), &sessions, query, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession ends an active session of the account.
+func (r *PostgresRepository) RevokeSession(ctx context.Context, accountID uint64, id uint64) error {
+	const op = "domain/auth.PostgresRepository.RevokeSession"
+
+	query := `
+		UPDATE session
+		SET revoked_at = now()
+		WHERE id = @id
+			AND account_id = @account_id
+			AND revoked_at IS NULL
+			AND expires_at > now()
+	`
+
+	args := pgx.NamedArgs{
+		"id":         id,
+		"account_id": accountID,
+	}
+
+	tag, err := common.Conn(ctx, r.db).Exec(ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return Errors.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RevokeSessions ends every active session of the account but exceptID and
+// returns how many there were.
+func (r *PostgresRepository) RevokeSessions(ctx context.Context, accountID uint64, exceptID uint64) (int64, error) {
+	const op = "domain/auth.PostgresRepository.RevokeSessions"
+
+	query := `
+		UPDATE session
+		SET revoked_at = now()
+		WHERE account_id = @account_id
+			AND id <> @except_id
+			AND revoked_at IS NULL
+			AND expires_at > now()
+	`
+
+	args := pgx.NamedArgs{
+		"account_id": accountID,
+		"except_id":  exceptID,
+	}
+
+	tag, err := common.Conn(ctx, r.db).Exec(ctx, query, args)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tag.RowsAffected(), nil
+}
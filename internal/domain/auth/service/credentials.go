@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"task/common"
+	"task/internal/domain/Errors"
+	"task/internal/domain/account/entity"
+	auth "task/internal/domain/auth/entity"
+	"task/internal/domain/auth/password"
+	"task/internal/domain/auth/token"
+	mail "task/internal/domain/mail/entity"
+	"task/internal/i18n"
+	"time"
+)
+
+// SendVerification mails a link that verifies the address of the account.
+// Verified accounts are left alone.
+func (s *Service) SendVerification(ctx context.Context, accountID uint64) error {
+	const op = "domain/auth.Service.SendVerification"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	account, err := s.repository.Get(ctx, accountID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if account.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	plain, err := s.issueToken(ctx, account, auth.PurposeVerifyEmail, s.cfg.VerificationTTL)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = s.mailer.Send(ctx, &mail.Message{
+		To:      account.Email,
+		Subject: i18n.T(ctx, "mail.verify_email.subject", nil),
+		Body: i18n.T(ctx, "mail.verify_email.body", i18n.Args{
+			"account_id": account.ID,
+			"ttl":        s.cfg.VerificationTTL,
+			"link":       s.link("/verify-email", plain),
+		}),
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// VerifyEmail consumes a verification token. The token only verifies the
+// address it was sent to.
+func (s *Service) VerifyEmail(ctx context.Context, plain string) error {
+	const op = "domain/auth.Service.VerifyEmail"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	t, err := s.repToken.ConsumeToken(ctx, auth.PurposeVerifyEmail, token.Hash(plain))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = s.repository.MarkEmailVerified(ctx, t.AccountID, t.Email)
+	if errors.Is(err, Errors.ErrAccountNotFound) {
+		return fmt.Errorf("%s: %w: address has changed", op, Errors.ErrInvalidToken)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ChangePassword replaces the password of a logged-in account after
+// checking the current one. Outstanding reset links and the other sessions
+// of the account stop working.
+func (s *Service) ChangePassword(ctx context.Context, accountID uint64, current string, next string) error {
+	const op = "domain/auth.Service.ChangePassword"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	account, err := s.repository.Get(ctx, accountID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	ok, _, err := password.Verify(account.Password, current)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !ok {
+		return fmt.Errorf("%s: %w", op, Errors.ErrInvalidCredentials)
+	}
+
+	var keep uint64
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		keep = principal.SessionID
+	}
+
+	if err := s.setPassword(ctx, account.ID, next, keep); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.notifyPasswordChanged(ctx, account)
+
+	return nil
+}
+
+// RequestPasswordReset mails a reset link to every account registered with
+// the address. It succeeds for unknown addresses too, so that callers
+// cannot probe which addresses have accounts.
+func (s *Service) RequestPasswordReset(ctx context.Context, email string) error {
+	const op = "domain/auth.Service.RequestPasswordReset"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	accounts, err := s.repository.ListByEmail(ctx, email)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	logger := common.FromContext(ctx)
+
+	for _, account := range accounts {
+		plain, err := s.issueToken(ctx, account, auth.PurposeResetPassword, s.cfg.ResetTTL)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = s.mailer.Send(ctx, &mail.Message{
+			To:      account.Email,
+			Subject: i18n.T(ctx, "mail.reset_password.subject", nil),
+			Body: i18n.T(ctx, "mail.reset_password.body", i18n.Args{
+				"account_id": account.ID,
+				"ttl":        s.cfg.ResetTTL,
+				"link":       s.link("/reset-password", plain),
+			}),
+		})
+		if err != nil {
+			logger.Error("cannot send password reset", "account_id", account.ID, "error", err.Error())
+		}
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password with a reset token and ends every
+// session. Following the link proves control of the address, so it is
+// marked verified as well.
+func (s *Service) ResetPassword(ctx context.Context, plain string, next string) error {
+	const op = "domain/auth.Service.ResetPassword"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	var account *entity.Account
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		t, err := s.repToken.ConsumeToken(ctx, auth.PurposeResetPassword, token.Hash(plain))
+		if err != nil {
+			return err
+		}
+
+		account, err = s.repository.Get(ctx, t.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.setPassword(ctx, account.ID, next, 0); err != nil {
+			return err
+		}
+
+		err = s.repository.MarkEmailVerified(ctx, account.ID, t.Email)
+		if err != nil && !errors.Is(err, Errors.ErrAccountNotFound) {
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	s.notifyPasswordChanged(ctx, account)
+
+	return nil
+}
+
+// setPassword validates and stores a new password, uses up the reset links
+// of the account and revokes its sessions but keepSession.
+func (s *Service) setPassword(ctx context.Context, accountID uint64, next string, keepSession uint64) error {
+	if err := password.Validate(next); err != nil {
+		return err
+	}
+
+	hash, err := password.Hash(next)
+	if err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repository.UpdatePassword(ctx, accountID, hash); err != nil {
+			return err
+		}
+
+		if err := s.repToken.RevokeTokens(ctx, accountID, auth.PurposeResetPassword); err != nil {
+			return err
+		}
+
+		_, err := s.repSession.RevokeSessions(ctx, accountID, keepSession)
+		return err
+	})
+}
+
+// notifyPasswordChanged tells the owner, so that a change they did not make
+// does not go unnoticed. The password is already changed, so a failure is
+// only logged.
+func (s *Service) notifyPasswordChanged(ctx context.Context, account *entity.Account) {
+	err := s.mailer.Send(ctx, &mail.Message{
+		To:      account.Email,
+		Subject: i18n.T(ctx, "mail.password_changed.subject", nil),
+		Body: i18n.T(ctx, "mail.password_changed.body", i18n.Args{
+			"account_id": account.ID,
+			"time":       s.now().UTC().Format(time.RFC1123),
+		}),
+	})
+	if err != nil {
+		common.FromContext(ctx).Warn("cannot send password change notice", "account_id", account.ID, "error", err.Error())
+	}
+}
+
+func (s *Service) issueToken(ctx context.Context, account *entity.Account, purpose string, ttl time.Duration) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	plain := base64.RawURLEncoding.EncodeToString(secret)
+
+	err := s.repToken.SaveToken(ctx, &auth.AccountToken{
+		AccountID: account.ID,
+		Purpose:   purpose,
+		Hash:      token.Hash(plain),
+		Email:     account.Email,
+		ExpiresAt: s.now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return plain, nil
+}
+
+func (s *Service) link(path string, plain string) string {
+	return s.baseURL + path + "?token=" + url.QueryEscape(plain)
+}
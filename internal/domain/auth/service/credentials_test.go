@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"task/common"
+	"task/internal/domain/Errors"
+	"task/internal/domain/account/entity"
+	auth "task/internal/domain/auth/entity"
+	"task/internal/domain/auth/password"
+	"task/internal/domain/auth/service/mocks"
+	"task/internal/domain/auth/token"
+	"task/internal/domain/mail/mailer"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type inlineTransactor struct{}
+
+func (inlineTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func newCredentialsService(rep *mocks.Repository, repToken *mocks.Repository_token, repSession *mocks.Repository_session, mail *mailer.Memory) *Service {
+	return &Service{
+		repository: rep,
+		repToken:   repToken,
+		repSession: repSession,
+		mailer:     mail,
+		transactor: inlineTransactor{},
+		cfg:        common.AuthConfig{VerificationTTL: 48 * time.Hour, ResetTTL: time.Hour},
+		baseURL:    "https://bank.example",
+		now:        time.Now,
+	}
+}
+
+func TestService_ChangePassword(t *testing.T) {
+	hashed, err := password.Hash("old password")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name       string
+		current    string
+		next       string
+		wantUpdate bool
+		wantErr    error
+	}{
+		{
+			name:       "Changed",
+			current:    "old password",
+			next:       "new password",
+			wantUpdate: true,
+		},
+		{
+			name:    "Wrong current password",
+			current: "guess",
+			next:    "new password",
+			wantErr: Errors.ErrInvalidCredentials,
+		},
+		{
+			name:    "Weak new password",
+			current: "old password",
+			next:    "short",
+			wantErr: Errors.ErrWeakPassword,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := auth.WithPrincipal(context.Background(), &auth.Principal{AccountID: 1, SessionID: 4})
+			rep := mocks.NewRepository(t)
+			repToken := mocks.NewRepository_token(t)
+			repSession := mocks.NewRepository_session(t)
+			mail := mailer.NewMemory()
+
+			rep.On("Get", ctx, uint64(1)).Return(&entity.Account{ID: 1, Password: hashed, Email: "owner@example.com"}, nil)
+			if tc.wantUpdate {
+				rep.On("UpdatePassword", ctx, uint64(1), mock.MatchedBy(func(hash string) bool {
+					ok, _, _ := password.Verify(hash, tc.next)
+					return ok
+				})).Return(nil)
+				repToken.On("RevokeTokens", ctx, uint64(1), auth.PurposeResetPassword).Return(nil)
+				repSession.On("RevokeSessions", ctx, uint64(1), uint64(4)).Return(int64(2), nil)
+			}
+
+			err := newCredentialsService(rep, repToken, repSession, mail).ChangePassword(ctx, 1, tc.current, tc.next)
+
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				require.Empty(t, mail.Messages())
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, mail.Messages(), 1)
+			require.Equal(t, "Your password was changed", mail.Messages()[0].Subject)
+		})
+	}
+}
+
+func TestService_PasswordReset(t *testing.T) {
+	ctx := context.Background()
+	rep := mocks.NewRepository(t)
+	repToken := mocks.NewRepository_token(t)
+	repSession := mocks.NewRepository_session(t)
+	mail := mailer.NewMemory()
+	s := newCredentialsService(rep, repToken, repSession, mail)
+
+	rep.On("ListByEmail", ctx, "owner@example.com").Return([]*entity.Account{
+		{ID: 1, Email: "owner@example.com"},
+	}, nil)
+
+	var saved *auth.AccountToken
+	repToken.On("SaveToken", ctx, mock.AnythingOfType("*entity.AccountToken")).Run(func(args mock.Arguments) {
+		saved = args.Get(1).(*auth.AccountToken)
+	}).Return(nil)
+
+	require.NoError(t, s.RequestPasswordReset(ctx, "owner@example.com"))
+	require.Len(t, mail.Messages(), 1)
+	require.Equal(t, auth.PurposeResetPassword, saved.Purpose)
+
+	link := mail.Messages()[0].Body[strings.Index(mail.Messages()[0].Body, "https://"):]
+	u, err := url.Parse(strings.Fields(link)[0])
+	require.NoError(t, err)
+	require.Equal(t, "/reset-password", u.Path)
+
+	plain := u.Query().Get("token")
+	require.Equal(t, saved.Hash, token.Hash(plain))
+
+	repToken.On("ConsumeToken", ctx, auth.PurposeResetPassword, saved.Hash).Return(&auth.AccountToken{
+		AccountID: 1,
+		Purpose:   auth.PurposeResetPassword,
+		Email:     "owner@example.com",
+	}, nil).Once()
+	repToken.On("ConsumeToken", ctx, auth.PurposeResetPassword, saved.Hash).Return(nil, Errors.ErrInvalidToken)
+	rep.On("Get", ctx, uint64(1)).Return(&entity.Account{ID: 1, Email: "owner@example.com"}, nil)
+	rep.On("UpdatePassword", ctx, uint64(1), mock.AnythingOfType("string")).Return(nil)
+	rep.On("MarkEmailVerified", ctx, uint64(1), "owner@example.com").Return(nil)
+	repToken.On("RevokeTokens", ctx, uint64(1), auth.PurposeResetPassword).Return(nil)
+	repSession.On("RevokeSessions", ctx, uint64(1), uint64(0)).Return(int64(1), nil)
+
+	require.NoError(t, s.ResetPassword(ctx, plain, "brand new password"))
+	require.ErrorIs(t, s.ResetPassword(ctx, plain, "another new password"), Errors.ErrInvalidToken)
+}
+
+func TestService_RequestPasswordReset_UnknownEmail(t *testing.T) {
+	ctx := context.Background()
+	rep := mocks.NewRepository(t)
+	mail := mailer.NewMemory()
+
+	rep.On("ListByEmail", ctx, "nobody@example.com").Return(nil, nil)
+
+	require.NoError(t, newCredentialsService(rep, mocks.NewRepository_token(t), mocks.NewRepository_session(t), mail).RequestPasswordReset(ctx, "nobody@example.com"))
+	require.Empty(t, mail.Messages())
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"task/common"
+	"task/internal/domain/Errors"
+	"task/internal/domain/account/entity"
+	"task/internal/domain/account/repository"
+	apikey "task/internal/domain/apikey/service"
+	auth "task/internal/domain/auth/entity"
+	"task/internal/domain/auth/password"
+	authRep "task/internal/domain/auth/repository"
+	"task/internal/domain/auth/token"
+	mail "task/internal/domain/mail/entity"
+	rbac "task/internal/domain/rbac/entity"
+	rbacRep "task/internal/domain/rbac/repository"
+	"time"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Repository
+type Repository interface {
+	Get(ctx context.Context, id uint64) (*entity.Account, error)
+	UpdatePassword(ctx context.Context, id uint64, hash string) error
+	ListByEmail(ctx context.Context, email string) ([]*entity.Account, error)
+	MarkEmailVerified(ctx context.Context, id uint64, email string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Repository_token
+type Repository_token interface {
+	SaveToken(ctx context.Context, token *auth.AccountToken) error
+	ConsumeToken(ctx context.Context, purpose string, hash string) (*auth.AccountToken, error)
+	RevokeTokens(ctx context.Context, accountID uint64, purpose string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Repository_session
+type Repository_session interface {
+	CreateSession(ctx context.Context, session *auth.Session) error
+	RotateSession(ctx context.Context, session *auth.Session, previousID string) (bool, error)
+	GetSession(ctx context.Context, id uint64) (*auth.Session, error)
+	ListSessions(ctx context.Context, accountID uint64) ([]*auth.Session, error)
+	RevokeSession(ctx context.Context, accountID uint64, id uint64) error
+	RevokeSessions(ctx context.Context, accountID uint64, exceptID uint64) (int64, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Repository_rbac
+type Repository_rbac interface {
+	ListGrants(ctx context.Context, accountID uint64) ([]*rbac.Grant, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=APIKeys
+type APIKeys interface {
+	Authenticate(ctx context.Context, key string, clientIP string) (*auth.Principal, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Repository_totp
+type Repository_totp interface {
+	SaveTOTP(ctx context.Context, totp *auth.TOTP) error
+	GetTOTP(ctx context.Context, accountID uint64) (*auth.TOTP, error)
+	ConfirmTOTP(ctx context.Context, accountID uint64, step int64) error
+	UseTOTPStep(ctx context.Context, accountID uint64, step int64) (bool, error)
+	DeleteTOTP(ctx context.Context, accountID uint64) error
+	ReplaceRecoveryCodes(ctx context.Context, accountID uint64, hashes []string) error
+	UseRecoveryCode(ctx context.Context, accountID uint64, hash string) (bool, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Mailer
+type Mailer interface {
+	Send(ctx context.Context, message *mail.Message) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Transactor
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type Service struct {
+	repository Repository
+	repRBAC    Repository_rbac
+	repToken   Repository_token
+	repTOTP    Repository_totp
+	repSession Repository_session
+	apiKeys    APIKeys
+	mailer     Mailer
+	transactor Transactor
+	issuer     *token.Issuer
+	cfg        common.AuthConfig
+	baseURL    string
+	now        func() time.Time
+}
+
+func NewService(di *common.DependencyContainer, mailer Mailer) *Service {
+	return &Service{
+		repository: repository.NewPostgresRepository(di.Pool),
+		repRBAC:    rbacRep.NewPostgresRepository(di.Pool),
+		repToken:   authRep.NewPostgresRepository(di.Pool),
+		repTOTP:    authRep.NewPostgresRepository(di.Pool),
+		repSession: authRep.NewPostgresRepository(di.Pool),
+		apiKeys:    apikey.NewService(di),
+		mailer:     mailer,
+		transactor: common.NewTransactor(di.Pool),
+		issuer:     token.NewIssuer(di.Config.Auth),
+		cfg:        di.Config.Auth,
+		baseURL:    strings.TrimSuffix(di.Config.Mail.BaseURL, "/"),
+		now:        time.Now,
+	}
+}
+
+// dummyHash is verified against when the account does not exist, so that
+// unknown ids take as long as wrong passwords.
+var dummyHash, _ = password.Hash("dummy password")
+
+// Login checks the account password and starts a session on the device.
+// Passwords stored as plaintext, bcrypt or with outdated parameters are
+// rehashed.
+func (s *Service) Login(ctx context.Context, accountID uint64, plain string, device auth.Device) (*token.Pair, error) {
+	const op = "domain/auth.Service.Login"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	account, err := s.repository.Get(ctx, accountID)
+	if errors.Is(err, Errors.ErrAccountNotFound) {
+		_, _, _ = password.Verify(dummyHash, plain)
+		return nil, fmt.Errorf("%s: %w", op, Errors.ErrInvalidCredentials)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ok, rehash, err := password.Verify(account.Password, plain)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, Errors.ErrInvalidCredentials)
+	}
+
+	if rehash {
+		s.rehash(ctx, account.ID, plain)
+	}
+
+	pair, err := s.startSession(ctx, account.ID, device)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pair, nil
+}
+
+// Refresh exchanges the latest refresh token of a session for a new token
+// pair. A refresh token is good for one use: presenting it again means it
+// leaked, and the session is revoked for whoever holds its successor too.
+func (s *Service) Refresh(ctx context.Context, refreshToken string, device auth.Device) (*token.Pair, error) {
+	const op = "domain/auth.Service.Refresh"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	claims, err := s.issuer.Parse(refreshToken, token.Refresh)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	accountID, _ := claims.AccountID()
+
+	if _, err := s.repository.Get(ctx, accountID); err != nil {
+		if errors.Is(err, Errors.ErrAccountNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, Errors.ErrInvalidToken)
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	pair, err := s.rotateSession(ctx, accountID, claims, device)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pair, nil
+}
+
+// Authenticate resolves the caller of an access token or an API key. The
+// session and roles are read on every call so that revoking either takes
+// effect before the token expires.
+func (s *Service) Authenticate(ctx context.Context, credential string, clientIP string) (*auth.Principal, error) {
+	const op = "domain/auth.Service.Authenticate"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	if strings.HasPrefix(credential, apikey.KeyPrefix) {
+		principal, err := s.apiKeys.Authenticate(ctx, credential, clientIP)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		return principal, nil
+	}
+
+	claims, err := s.issuer.Parse(credential, token.Access)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	accountID, _ := claims.AccountID()
+
+	session, err := s.repSession.GetSession(ctx, claims.SessionID)
+	if errors.Is(err, Errors.ErrSessionNotFound) {
+		return nil, fmt.Errorf("%s: %w", op, Errors.ErrInvalidToken)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if session.AccountID != accountID || !session.Active(s.now()) {
+		return nil, fmt.Errorf("%s: %w: session ended", op, Errors.ErrInvalidToken)
+	}
+
+	principal, err := s.principal(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	principal.SessionID = session.ID
+
+	return principal, nil
+}
+
+func (s *Service) principal(ctx context.Context, accountID uint64) (*auth.Principal, error) {
+	grants, err := s.repRBAC.ListGrants(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	principal := &auth.Principal{
+		AccountID:   accountID,
+		Permissions: make(map[string]bool),
+	}
+
+	seen := make(map[string]bool)
+	for _, grant := range grants {
+		if !seen[grant.Role] {
+			seen[grant.Role] = true
+			principal.Roles = append(principal.Roles, grant.Role)
+		}
+		if grant.Permission != nil {
+			principal.Permissions[*grant.Permission] = true
+		}
+	}
+
+	return principal, nil
+}
+
+// rehash upgrades the stored password. A failure only costs another
+// rehash on the next login, so it does not fail the login itself.
+func (s *Service) rehash(ctx context.Context, accountID uint64, plain string) {
+	logger := common.FromContext(ctx)
+
+	hash, err := password.Hash(plain)
+	if err != nil {
+		logger.Warn("cannot rehash password", "account_id", accountID, "error", err.Error())
+		return
+	}
+
+	if err := s.repository.UpdatePassword(ctx, accountID, hash); err != nil {
+		logger.Warn("cannot store rehashed password", "account_id", accountID, "error", err.Error())
+	}
+}
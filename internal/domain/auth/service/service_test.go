@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"task/common"
+	"task/internal/domain/Errors"
+	"task/internal/domain/account/entity"
+	auth "task/internal/domain/auth/entity"
+	"task/internal/domain/auth/password"
+	"task/internal/domain/auth/service/mocks"
+	"task/internal/domain/auth/token"
+	rbac "task/internal/domain/rbac/entity"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newIssuer() *token.Issuer {
+	return token.NewIssuer(common.AuthConfig{
+		Secret:     "test-secret",
+		Issuer:     "task",
+		AccessTTL:  time.Minute,
+		RefreshTTL: time.Hour,
+	})
+}
+
+func TestService_Login(t *testing.T) {
+	hashed, err := password.Hash("qwerty1")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name       string
+		stored     string
+		getErr     error
+		plain      string
+		wantRehash bool
+		wantErr    error
+	}{
+		{
+			name:   "Hashed password",
+			stored: hashed,
+			plain:  "qwerty1",
+		},
+		{
+			name:       "Plaintext password is rehashed",
+			stored:     "qwerty1",
+			plain:      "qwerty1",
+			wantRehash: true,
+		},
+		{
+			name:    "Wrong password",
+			stored:  hashed,
+			plain:   "qwerty2",
+			wantErr: Errors.ErrInvalidCredentials,
+		},
+		{
+			name:    "Unknown account",
+			getErr:  Errors.ErrAccountNotFound,
+			plain:   "qwerty1",
+			wantErr: Errors.ErrInvalidCredentials,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			rep := mocks.NewRepository(t)
+
+			if tc.getErr != nil {
+				rep.On("Get", ctx, uint64(1)).Return(nil, tc.getErr)
+			} else {
+				rep.On("Get", ctx, uint64(1)).Return(&entity.Account{ID: 1, Password: tc.stored}, nil)
+			}
+
+			if tc.wantRehash {
+				rep.On("UpdatePassword", ctx, uint64(1), mock.MatchedBy(func(hash string) bool {
+					ok, rehash, err := password.Verify(hash, tc.plain)
+					return ok && !rehash && err == nil
+				})).Return(nil)
+			}
+
+			repSession := mocks.NewRepository_session(t)
+			if tc.wantErr == nil {
+				repSession.On("CreateSession", ctx, mock.MatchedBy(func(session *auth.Session) bool {
+					return session.AccountID == 1 && session.RefreshID != "" && session.UserAgent == "curl/8.0" && session.IP == "127.0.0.1"
+				})).Run(func(args mock.Arguments) {
+					args.Get(1).(*auth.Session).ID = 7
+				}).Return(nil)
+			}
+
+			issuer := newIssuer()
+			s := &Service{repository: rep, repSession: repSession, issuer: issuer, now: time.Now}
+
+			pair, err := s.Login(ctx, 1, tc.plain, auth.Device{UserAgent: "curl/8.0", IP: "127.0.0.1"})
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			claims, err := issuer.Parse(pair.AccessToken, token.Access)
+			require.NoError(t, err)
+			require.Equal(t, "1", claims.Subject)
+			require.Equal(t, uint64(7), claims.SessionID)
+
+			_, err = issuer.Parse(pair.AccessToken, token.Refresh)
+			require.ErrorIs(t, err, Errors.ErrInvalidToken)
+		})
+	}
+}
+
+func TestService_Refresh(t *testing.T) {
+	issuer := newIssuer()
+
+	pair, err := issuer.Issue(1, 7, "first")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name       string
+		raw        string
+		rotated    bool
+		revokeErr  error
+		wantErr    error
+		wantRevoke bool
+	}{
+		{
+			name:    "Rotated",
+			raw:     pair.RefreshToken,
+			rotated: true,
+		},
+		{
+			name:    "Access token",
+			raw:     pair.AccessToken,
+			wantErr: Errors.ErrInvalidToken,
+		},
+		{
+			name:       "Reused token revokes the session",
+			raw:        pair.RefreshToken,
+			wantRevoke: true,
+			wantErr:    Errors.ErrInvalidToken,
+		},
+		{
+			name:       "Session already ended",
+			raw:        pair.RefreshToken,
+			revokeErr:  Errors.ErrSessionNotFound,
+			wantRevoke: true,
+			wantErr:    Errors.ErrInvalidToken,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			rep := mocks.NewRepository(t)
+			repSession := mocks.NewRepository_session(t)
+
+			if tc.raw == pair.RefreshToken {
+				rep.On("Get", ctx, uint64(1)).Return(&entity.Account{ID: 1}, nil)
+				repSession.On("RotateSession", ctx, mock.MatchedBy(func(session *auth.Session) bool {
+					return session.ID == 7 && session.AccountID == 1 && session.RefreshID != "first" && session.IP == "10.0.0.2"
+				}), "first").Return(tc.rotated, nil)
+			}
+			if tc.wantRevoke {
+				repSession.On("RevokeSession", ctx, uint64(1), uint64(7)).Return(tc.revokeErr)
+			}
+
+			s := &Service{repository: rep, repSession: repSession, issuer: issuer, now: time.Now}
+
+			refreshed, err := s.Refresh(ctx, tc.raw, auth.Device{IP: "10.0.0.2"})
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			claims, err := issuer.Parse(refreshed.RefreshToken, token.Refresh)
+			require.NoError(t, err)
+			require.Equal(t, uint64(7), claims.SessionID)
+			require.NotEqual(t, "first", claims.ID)
+		})
+	}
+}
+
+func TestService_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	rep := mocks.NewRepository_rbac(t)
+
+	settle := rbac.PermTransactionsSettle
+	read := rbac.PermTransactionsRead
+
+	rep.On("ListGrants", ctx, uint64(3)).Return([]*rbac.Grant{
+		{Role: rbac.RoleOperator, Permission: &settle},
+		{Role: rbac.RoleOperator, Permission: &read},
+		{Role: "auditor"},
+	}, nil)
+
+	now := time.Now()
+	revoked := now.Add(-time.Minute)
+
+	repSession := mocks.NewRepository_session(t)
+	repSession.On("GetSession", ctx, uint64(7)).Return(&auth.Session{ID: 7, AccountID: 3, ExpiresAt: now.Add(time.Hour)}, nil)
+	repSession.On("GetSession", ctx, uint64(8)).Return(&auth.Session{ID: 8, AccountID: 3, ExpiresAt: now.Add(time.Hour), RevokedAt: &revoked}, nil)
+
+	issuer := newIssuer()
+	s := &Service{repRBAC: rep, repSession: repSession, issuer: issuer, now: func() time.Time { return now }}
+
+	pair, err := issuer.Issue(3, 7, "refresh")
+	require.NoError(t, err)
+
+	_, err = s.Authenticate(ctx, pair.RefreshToken, "127.0.0.1")
+	require.ErrorIs(t, err, Errors.ErrInvalidToken)
+
+	ended, err := issuer.Issue(3, 8, "refresh")
+	require.NoError(t, err)
+
+	_, err = s.Authenticate(ctx, ended.AccessToken, "127.0.0.1")
+	require.ErrorIs(t, err, Errors.ErrInvalidToken)
+
+	principal, err := s.Authenticate(ctx, pair.AccessToken, "127.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), principal.AccountID)
+	require.Equal(t, uint64(7), principal.SessionID)
+	require.Equal(t, []string{rbac.RoleOperator, "auditor"}, principal.Roles)
+	require.True(t, principal.Can(rbac.PermTransactionsSettle))
+	require.False(t, principal.Can(rbac.PermTransactionsDelete))
+}
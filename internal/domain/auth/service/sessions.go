@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"task/common"
+	"task/internal/domain/Errors"
+	auth "task/internal/domain/auth/entity"
+	"task/internal/domain/auth/token"
+)
+
+// maxUserAgent bounds what is stored of the User-Agent header.
+const maxUserAgent = 512
+
+// ListSessions returns the active sessions of the account. The one the
+// caller is using is marked current.
+func (s *Service) ListSessions(ctx context.Context, accountID uint64) ([]*auth.Session, error) {
+	const op = "domain/auth.Service.ListSessions"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	sessions, err := s.repSession.ListSessions(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		for _, session := range sessions {
+			session.Current = session.ID == principal.SessionID
+		}
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession logs the account out of one session. Its access tokens stop
+// working at once and its refresh token cannot be used.
+func (s *Service) RevokeSession(ctx context.Context, accountID uint64, sessionID uint64) error {
+	const op = "domain/auth.Service.RevokeSession"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	if err := s.repSession.RevokeSession(ctx, accountID, sessionID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RevokeSessions logs the account out everywhere, including the session of
+// the caller.
+func (s *Service) RevokeSessions(ctx context.Context, accountID uint64) (int64, error) {
+	const op = "domain/auth.Service.RevokeSessions"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	revoked, err := s.repSession.RevokeSessions(ctx, accountID, 0)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return revoked, nil
+}
+
+func (s *Service) startSession(ctx context.Context, accountID uint64, device auth.Device) (*token.Pair, error) {
+	refreshID, err := token.NewID()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &auth.Session{
+		AccountID: accountID,
+		RefreshID: refreshID,
+		UserAgent: truncate(device.UserAgent, maxUserAgent),
+		IP:        device.IP,
+		ExpiresAt: s.now().Add(s.cfg.RefreshTTL),
+	}
+
+	if err := s.repSession.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return s.issuer.Issue(accountID, session.ID, refreshID)
+}
+
+// rotateSession moves the session of a refresh token on to a new one. When
+// the token is not the latest of its session, the session is revoked:
+// either the client and an attacker both hold the token, or the session has
+// ended already.
+func (s *Service) rotateSession(ctx context.Context, accountID uint64, claims *token.Claims, device auth.Device) (*token.Pair, error) {
+	refreshID, err := token.NewID()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &auth.Session{
+		ID:        claims.SessionID,
+		AccountID: accountID,
+		RefreshID: refreshID,
+		UserAgent: truncate(device.UserAgent, maxUserAgent),
+		IP:        device.IP,
+		ExpiresAt: s.now().Add(s.cfg.RefreshTTL),
+	}
+
+	rotated, err := s.repSession.RotateSession(ctx, session, claims.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !rotated {
+		err := s.repSession.RevokeSession(ctx, accountID, claims.SessionID)
+		if err == nil {
+			common.FromContext(ctx).Warn("refresh token reused, session revoked",
+				"account_id", accountID, "session_id", claims.SessionID, "ip", device.IP)
+		} else if !errors.Is(err, Errors.ErrSessionNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: refresh token was used already", Errors.ErrInvalidToken)
+	}
+
+	return s.issuer.Issue(accountID, session.ID, refreshID)
+}
+
+func truncate(value string, max int) string {
+	if len(value) <= max {
+		return value
+	}
+
+	return strings.ToValidUTF8(value[:max], "")
+}
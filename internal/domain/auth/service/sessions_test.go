@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	auth "task/internal/domain/auth/entity"
+	"task/internal/domain/auth/service/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ListSessions(t *testing.T) {
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{AccountID: 1, SessionID: 8})
+	repSession := mocks.NewRepository_session(t)
+
+	repSession.On("ListSessions", ctx, uint64(1)).Return([]*auth.Session{
+		{ID: 9, AccountID: 1, UserAgent: "Mozilla/5.0"},
+		{ID: 8, AccountID: 1, UserAgent: "curl/8.0"},
+	}, nil)
+
+	s := &Service{repSession: repSession}
+
+	sessions, err := s.ListSessions(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	require.False(t, sessions[0].Current)
+	require.True(t, sessions[1].Current)
+}
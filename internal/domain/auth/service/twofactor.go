@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"task/common"
+	"task/internal/domain/Errors"
+	auth "task/internal/domain/auth/entity"
+	"task/internal/domain/auth/password"
+	"task/internal/domain/auth/token"
+	"task/internal/domain/auth/totp"
+)
+
+const (
+	recoveryCodes = 10
+
+	// totpSkew accepts codes of the neighbouring time steps, for phones
+	// whose clocks drift.
+	totpSkew = 1
+)
+
+// recoveryAlphabet leaves out characters that are easy to misread.
+const recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+// EnrollTOTP starts enrolment with a new secret. It takes effect once
+// confirmed with a code from the authenticator.
+func (s *Service) EnrollTOTP(ctx context.Context, accountID uint64) (*auth.Enrollment, error) {
+	const op = "domain/auth.Service.EnrollTOTP"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	account, err := s.repository.Get(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.repTOTP.SaveTOTP(ctx, &auth.TOTP{AccountID: account.ID, Secret: secret}); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	label := account.Email
+	if label == "" {
+		label = fmt.Sprint(account.ID)
+	}
+
+	return &auth.Enrollment{
+		Secret: secret,
+		URI:    totp.URI(s.cfg.Issuer, label, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables the pending authenticator and returns fresh recovery
+// codes. They are not stored in plaintext and cannot be shown again.
+func (s *Service) ConfirmTOTP(ctx context.Context, accountID uint64, code string) ([]string, error) {
+	const op = "domain/auth.Service.ConfirmTOTP"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	pending, err := s.repTOTP.GetTOTP(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if pending.Enabled() {
+		return nil, fmt.Errorf("%s: %w", op, Errors.ErrTOTPEnabled)
+	}
+
+	step, ok, err := totp.Validate(pending.Secret, code, s.now(), totpSkew)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, Errors.ErrInvalidOTP)
+	}
+
+	var codes []string
+
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repTOTP.ConfirmTOTP(ctx, accountID, step); err != nil {
+			return err
+		}
+
+		codes, err = s.replaceRecoveryCodes(ctx, accountID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes. It takes a code from
+// the authenticator, since losing the old recovery codes is the usual
+// reason to call it.
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, accountID uint64, code string) ([]string, error) {
+	const op = "domain/auth.Service.RegenerateRecoveryCodes"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	current, err := s.enabledTOTP(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.useTOTPCode(ctx, current, code); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return codes, nil
+}
+
+// DisableTOTP removes the authenticator and the recovery codes. It takes
+// the password and a one-time or recovery code, so that a stolen session
+// alone cannot turn the protection off.
+func (s *Service) DisableTOTP(ctx context.Context, accountID uint64, plain string, code string) error {
+	const op = "domain/auth.Service.DisableTOTP"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	account, err := s.repository.Get(ctx, accountID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	ok, _, err := password.Verify(account.Password, plain)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !ok {
+		return fmt.Errorf("%s: %w", op, Errors.ErrInvalidCredentials)
+	}
+
+	if err := s.verifySecondFactor(ctx, accountID, code); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.repTOTP.DeleteTOTP(ctx, accountID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// CheckStepUp guards high-value operations: from the configured threshold
+// of the currency on, the caller has to present a fresh one-time or
+// recovery code with the request. Accounts without an authenticator cannot
+// make such operations. API keys have no authenticator, so only keys that
+// sign every request pass. Calls without a principal come from inside the
+// process and are not checked.
+func (s *Service) CheckStepUp(ctx context.Context, amount float64, currency string) error {
+	const op = "domain/auth.Service.CheckStepUp"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	threshold, ok := s.cfg.StepUpThresholds[strings.ToUpper(currency)]
+	if !ok || amount < threshold {
+		return nil
+	}
+
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	if principal.APIKeyID != 0 {
+		if principal.SignatureRequired {
+			return nil
+		}
+		return fmt.Errorf("%s: %w: api key does not require signed requests", op, Errors.ErrStepUpRequired)
+	}
+
+	if _, err := s.enabledTOTP(ctx, principal.AccountID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	code := auth.OTPFromContext(ctx)
+	if code == "" {
+		return fmt.Errorf("%s: %w", op, Errors.ErrStepUpRequired)
+	}
+
+	if err := s.verifySecondFactor(ctx, principal.AccountID, code); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// verifySecondFactor accepts a code from the authenticator or an unused
+// recovery code.
+func (s *Service) verifySecondFactor(ctx context.Context, accountID uint64, code string) error {
+	current, err := s.enabledTOTP(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return s.useTOTPCode(ctx, current, code)
+	}
+
+	used, err := s.repTOTP.UseRecoveryCode(ctx, accountID, token.Hash(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return Errors.ErrInvalidOTP
+	}
+
+	return nil
+}
+
+// useTOTPCode accepts a code once: a code seen on the wire cannot be
+// replayed within its time step.
+func (s *Service) useTOTPCode(ctx context.Context, current *auth.TOTP, code string) error {
+	step, ok, err := totp.Validate(current.Secret, code, s.now(), totpSkew)
+	if err != nil {
+		return err
+	}
+	if !ok || step <= current.LastStep {
+		return Errors.ErrInvalidOTP
+	}
+
+	fresh, err := s.repTOTP.UseTOTPStep(ctx, current.AccountID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return Errors.ErrInvalidOTP
+	}
+
+	return nil
+}
+
+func (s *Service) enabledTOTP(ctx context.Context, accountID uint64) (*auth.TOTP, error) {
+	current, err := s.repTOTP.GetTOTP(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	if !current.Enabled() {
+		return nil, Errors.ErrTOTPNotEnabled
+	}
+
+	return current, nil
+}
+
+func (s *Service) replaceRecoveryCodes(ctx context.Context, accountID uint64) ([]string, error) {
+	codes := make([]string, recoveryCodes)
+	hashes := make([]string, recoveryCodes)
+
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = token.Hash(normalizeRecoveryCode(code))
+	}
+
+	if err := s.repTOTP.ReplaceRecoveryCodes(ctx, accountID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// newRecoveryCode returns a code like "k7mq4-xw2pa". Bytes past the last
+// whole multiple of the alphabet are skipped, so every character is
+// equally likely.
+func newRecoveryCode() (string, error) {
+	const length = 10
+	limit := 256 - 256%len(recoveryAlphabet)
+
+	var b strings.Builder
+	random := make([]byte, 1)
+
+	for n := 0; n < length; {
+		if _, err := rand.Read(random); err != nil {
+			return "", err
+		}
+		if int(random[0]) >= limit {
+			continue
+		}
+		if n == length/2 {
+			b.WriteByte('-')
+		}
+		b.WriteByte(recoveryAlphabet[int(random[0])%len(recoveryAlphabet)])
+		n++
+	}
+
+	return b.String(), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"task/common"
+	"task/internal/domain/Errors"
+	auth "task/internal/domain/auth/entity"
+	"task/internal/domain/auth/service/mocks"
+	"task/internal/domain/auth/token"
+	"task/internal/domain/auth/totp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_CheckStepUp(t *testing.T) {
+	now := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+	confirmed := now.Add(-time.Hour)
+
+	secret, err := totp.NewSecret()
+	require.NoError(t, err)
+	code, err := totp.Generate(secret, totp.Step(now))
+	require.NoError(t, err)
+
+	customer := &auth.Principal{AccountID: 1}
+
+	cases := []struct {
+		name      string
+		amount    float64
+		principal *auth.Principal
+		code      string
+		totp      *auth.TOTP
+		recovery  bool
+		wantErr   error
+	}{
+		{
+			name:      "Below threshold",
+			amount:    999,
+			principal: customer,
+		},
+		{
+			name:   "Internal call",
+			amount: 5000,
+		},
+		{
+			name:      "Signing api key",
+			amount:    5000,
+			principal: &auth.Principal{APIKeyID: 3, SignatureRequired: true},
+		},
+		{
+			name:      "Api key without signing",
+			amount:    5000,
+			principal: &auth.Principal{APIKeyID: 3},
+			wantErr:   Errors.ErrStepUpRequired,
+		},
+		{
+			name:      "Pending authenticator",
+			amount:    5000,
+			principal: customer,
+			totp:      &auth.TOTP{AccountID: 1, Secret: secret},
+			code:      code,
+			wantErr:   Errors.ErrTOTPNotEnabled,
+		},
+		{
+			name:      "Missing code",
+			amount:    5000,
+			principal: customer,
+			totp:      &auth.TOTP{AccountID: 1, Secret: secret, ConfirmedAt: &confirmed},
+			wantErr:   Errors.ErrStepUpRequired,
+		},
+		{
+			name:      "Fresh code",
+			amount:    1000,
+			principal: customer,
+			totp:      &auth.TOTP{AccountID: 1, Secret: secret, ConfirmedAt: &confirmed},
+			code:      code,
+		},
+		{
+			name:      "Code already used",
+			amount:    5000,
+			principal: customer,
+			totp:      &auth.TOTP{AccountID: 1, Secret: secret, ConfirmedAt: &confirmed, LastStep: totp.Step(now)},
+			code:      code,
+			wantErr:   Errors.ErrInvalidOTP,
+		},
+		{
+			name:      "Recovery code",
+			amount:    5000,
+			principal: customer,
+			totp:      &auth.TOTP{AccountID: 1, Secret: secret, ConfirmedAt: &confirmed},
+			code:      "K7MQ4-XW2PA",
+			recovery:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			if tc.principal != nil {
+				ctx = auth.WithPrincipal(ctx, tc.principal)
+			}
+			if tc.code != "" {
+				ctx = auth.WithOTP(ctx, tc.code)
+			}
+
+			repTOTP := mocks.NewRepository_totp(t)
+			if tc.totp != nil {
+				repTOTP.On("GetTOTP", ctx, uint64(1)).Return(tc.totp, nil)
+			}
+			if tc.totp != nil && tc.wantErr == nil {
+				if tc.recovery {
+					repTOTP.On("UseRecoveryCode", ctx, uint64(1), token.Hash("k7mq4xw2pa")).Return(true, nil)
+				} else {
+					repTOTP.On("UseTOTPStep", ctx, uint64(1), totp.Step(now)).Return(true, nil)
+				}
+			}
+
+			s := &Service{
+				repTOTP: repTOTP,
+				cfg:     common.AuthConfig{StepUpThresholds: map[string]float64{"USD": 1000}},
+				now:     func() time.Time { return now },
+			}
+
+			err := s.CheckStepUp(ctx, tc.amount, "usd")
+
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestService_ConfirmTOTP(t *testing.T) {
+	now := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	secret, err := totp.NewSecret()
+	require.NoError(t, err)
+	code, err := totp.Generate(secret, totp.Step(now))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	repTOTP := mocks.NewRepository_totp(t)
+
+	repTOTP.On("GetTOTP", ctx, uint64(1)).Return(&auth.TOTP{AccountID: 1, Secret: secret}, nil)
+	repTOTP.On("ConfirmTOTP", ctx, uint64(1), totp.Step(now)).Return(nil)
+
+	var hashes []string
+	repTOTP.On("ReplaceRecoveryCodes", ctx, uint64(1), mock.AnythingOfType("[]string")).Run(func(args mock.Arguments) {
+		hashes = args.Get(2).([]string)
+	}).Return(nil)
+
+	s := &Service{repTOTP: repTOTP, transactor: inlineTransactor{}, now: func() time.Time { return now }}
+
+	_, err = s.ConfirmTOTP(ctx, 1, "000000")
+	require.ErrorIs(t, err, Errors.ErrInvalidOTP)
+
+	codes, err := s.ConfirmTOTP(ctx, 1, code)
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodes)
+
+	seen := make(map[string]bool)
+	for i, c := range codes {
+		require.Regexp(t, `^[a-z2-9]{5}-[a-z2-9]{5}$`, c)
+		require.Equal(t, hashes[i], token.Hash(normalizeRecoveryCode(c)))
+		require.False(t, seen[c])
+		seen[c] = true
+	}
+}
@@ -0,0 +1,54 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test vectors of RFC 6238, appendix B, truncated to 6 digits.
+func TestGenerate(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	cases := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+	}
+
+	for _, tc := range cases {
+		code, err := Generate(secret, Step(time.Unix(tc.unix, 0)))
+		require.NoError(t, err)
+		require.Equal(t, tc.code, code, "time %d", tc.unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+
+	now := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	previous, err := Generate(secret, Step(now)-1)
+	require.NoError(t, err)
+
+	step, ok, err := Validate(secret, previous, now, 1)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, Step(now)-1, step)
+
+	_, ok, err = Validate(secret, previous, now.Add(2*Period), 1)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, ok, err = Validate(secret, "12345", now, 1)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// Message is a plain-text email.
+type Message struct {
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	SentAt  time.Time `json:"sent_at"`
+}
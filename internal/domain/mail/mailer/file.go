@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"task/internal/domain/mail/entity"
+	"time"
+)
+
+// File appends every message as a JSON line to a local file, so that links
+// in emails can be followed during local development.
+type File struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+func NewFile(path string) (*File, error) {
+	const op = "domain/mail.mailer.NewFile"
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &File{
+		file: file,
+		enc:  json.NewEncoder(file),
+	}, nil
+}
+
+func (f *File) Send(_ context.Context, message *entity.Message) error {
+	const op = "domain/mail.mailer.File.Send"
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	message.SentAt = time.Now()
+
+	if err := f.enc.Encode(message); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := f.file.Sync(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (f *File) Close() error {
+	return f.file.Close()
+}
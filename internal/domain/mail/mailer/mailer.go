@@ -0,0 +1,38 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"task/common"
+	"task/internal/domain/mail/entity"
+)
+
+const (
+	TypeMemory = "memory"
+	TypeFile   = "file"
+	TypeSMTP   = "smtp"
+)
+
+type Mailer interface {
+	Send(ctx context.Context, message *entity.Message) error
+	Close() error
+}
+
+func New(cfg common.MailConfig) (Mailer, error) {
+	const op = "domain/mail.mailer.New"
+
+	switch cfg.Mailer {
+	case TypeMemory:
+		return NewMemory(), nil
+	case TypeFile:
+		file, err := NewFile(cfg.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		return file, nil
+	case TypeSMTP:
+		return NewSMTP(cfg), nil
+	default:
+		return nil, fmt.Errorf("%s: unknown mailer %q", op, cfg.Mailer)
+	}
+}
@@ -0,0 +1,43 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+	"task/internal/domain/mail/entity"
+	"time"
+)
+
+// Memory keeps sent messages in process. It is meant for local runs and
+// tests.
+type Memory struct {
+	mu       sync.Mutex
+	messages []*entity.Message
+}
+
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (m *Memory) Send(_ context.Context, message *entity.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	message.SentAt = time.Now()
+	m.messages = append(m.messages, message)
+
+	return nil
+}
+
+func (m *Memory) Messages() []*entity.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := make([]*entity.Message, len(m.messages))
+	copy(messages, m.messages)
+
+	return messages
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
@@ -0,0 +1,79 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"task/common"
+	"task/internal/domain/mail/entity"
+	"time"
+)
+
+// SMTP sends messages through a relay. net/smtp upgrades the connection
+// with STARTTLS when the server offers it, and PLAIN auth refuses to send
+// credentials over an unencrypted connection to a remote host.
+type SMTP struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTP(cfg common.MailConfig) *SMTP {
+	s := &SMTP{
+		addr: net.JoinHostPort(cfg.SMTP.Host, strconv.Itoa(cfg.SMTP.Port)),
+		from: cfg.From,
+	}
+
+	if cfg.SMTP.Username != "" {
+		s.auth = smtp.PlainAuth("", cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.Host)
+	}
+
+	return s
+}
+
+// Send does not observe ctx while talking to the relay: net/smtp has no
+// context support.
+func (s *SMTP) Send(ctx context.Context, message *entity.Message) error {
+	const op = "domain/mail.mailer.SMTP.Send"
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	from, err := mail.ParseAddress(s.from)
+	if err != nil {
+		return fmt.Errorf("%s: from: %w", op, err)
+	}
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return fmt.Errorf("%s: to: %w", op, err)
+	}
+
+	message.SentAt = time.Now()
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", to.String())
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", message.SentAt.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\r\n")
+	fmt.Fprintf(&msg, "Content-Transfer-Encoding: 8bit\r\n")
+	fmt.Fprintf(&msg, "\r\n%s\r\n", message.Body)
+
+	if err := smtp.SendMail(s.addr, s.auth, from.Address, []string{to.Address}, msg.Bytes()); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Close is a no-op: every message uses its own connection.
+func (s *SMTP) Close() error {
+	return nil
+}
@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"encoding/json"
+	"task/internal/domain/transaction/entity"
+	"time"
+)
+
+const (
+	EventTransactionCreated  = "transaction.created"
+	EventTransactionSettled  = "transaction.settled"
+	EventTransactionFailed   = "transaction.failed"
+	EventTransactionReversed = "transaction.reversed"
+)
+
+// EventAccountBalanceSet records a balance set directly rather than by a
+// transaction, so that the events of an account add up to its balance.
+const EventAccountBalanceSet = "account.balance_set"
+
+const (
+	AggregateTransaction = "transaction"
+	AggregateAccount     = "account"
+)
+
+type Event struct {
+	ID            uint64          `json:"id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uint64          `json:"aggregate_id"`
+	AccountID     uint64          `json:"account_id"`
+	Type          string          `json:"type" db:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+	// Attempts is how often publishing the event failed so far.
+	Attempts int `json:"-"`
+}
+
+type TransactionPayload struct {
+	Kind        string             `json:"kind"`
+	Transaction entity.Transaction `json:"transaction"`
+	Balance     *float64           `json:"balance,omitempty"`
+	Reason      string             `json:"reason,omitempty"`
+}
+
+func NewTransactionEvent(eventType string, transaction *entity.Transaction, balance *float64, reason string) (*Event, error) {
+	payload, err := json.Marshal(TransactionPayload{
+		Kind:        transaction.Kind(),
+		Transaction: *transaction,
+		Balance:     balance,
+		Reason:      reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Event{
+		AggregateType: AggregateTransaction,
+		AggregateID:   transaction.ID,
+		AccountID:     transaction.AccountID,
+		Type:          eventType,
+		Payload:       payload,
+	}, nil
+}
+
+type BalancePayload struct {
+	AccountID uint64  `json:"account_id"`
+	Balance   float64 `json:"balance"`
+	Currency  string  `json:"currency"`
+}
+
+func NewBalanceEvent(accountID uint64, balance float64, currency string) (*Event, error) {
+	payload, err := json.Marshal(BalancePayload{
+		AccountID: accountID,
+		Balance:   balance,
+		Currency:  currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Event{
+		AggregateType: AggregateAccount,
+		AggregateID:   accountID,
+		AccountID:     accountID,
+		Type:          EventAccountBalanceSet,
+		Payload:       payload,
+	}, nil
+}
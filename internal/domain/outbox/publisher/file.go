@@ -0,0 +1,52 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"task/internal/domain/outbox/entity"
+)
+
+// File appends every event as a JSON line to a local file.
+type File struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+func NewFile(path string) (*File, error) {
+	const op = "domain/outbox.publisher.NewFile"
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &File{
+		file: file,
+		enc:  json.NewEncoder(file),
+	}, nil
+}
+
+func (f *File) Publish(_ context.Context, event *entity.Event) error {
+	const op = "domain/outbox.publisher.File.Publish"
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.enc.Encode(event); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := f.file.Sync(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (f *File) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,51 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"github.com/segmentio/kafka-go"
+	"strconv"
+	"task/internal/domain/outbox/entity"
+	"time"
+)
+
+// Kafka publishes events keyed by account id, so all events of one account
+// land in the same partition and keep their order.
+type Kafka struct {
+	writer *kafka.Writer
+}
+
+func NewKafka(brokers []string, topic string) *Kafka {
+	return &Kafka{
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			BatchTimeout: 10 * time.Millisecond,
+		},
+	}
+}
+
+func (k *Kafka) Publish(ctx context.Context, event *entity.Event) error {
+	const op = "domain/outbox.publisher.Kafka.Publish"
+
+	msg := kafka.Message{
+		Key:   []byte(strconv.FormatUint(event.AccountID, 10)),
+		Value: event.Payload,
+		Headers: []kafka.Header{
+			{Key: "Event-Id", Value: []byte(strconv.FormatUint(event.ID, 10))},
+			{Key: "Event-Type", Value: []byte(event.Type)},
+		},
+	}
+
+	if err := k.writer.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (k *Kafka) Close() error {
+	return k.writer.Close()
+}
@@ -0,0 +1,41 @@
+package publisher
+
+import (
+	"context"
+	"sync"
+	"task/internal/domain/outbox/entity"
+)
+
+// Memory keeps published events in process. It is meant for local runs and
+// tests.
+type Memory struct {
+	mu     sync.Mutex
+	events []*entity.Event
+}
+
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (m *Memory) Publish(_ context.Context, event *entity.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.events = append(m.events, event)
+
+	return nil
+}
+
+func (m *Memory) Events() []*entity.Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	events := make([]*entity.Event, len(m.events))
+	copy(events, m.events)
+
+	return events
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
@@ -0,0 +1,40 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"task/internal/domain/outbox/entity"
+	"task/internal/domain/outbox/service"
+)
+
+// Multi fans an event out to several publishers. The event counts as
+// published only when all of them succeed; on retry the ones that already
+// succeeded see it again, which is fine under at-least-once delivery.
+type Multi struct {
+	publishers []service.Publisher
+}
+
+func NewMulti(publishers ...service.Publisher) *Multi {
+	return &Multi{
+		publishers: publishers,
+	}
+}
+
+func (m *Multi) Publish(ctx context.Context, event *entity.Event) error {
+	for _, publisher := range m.publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Multi) Close() error {
+	var errs []error
+	for _, publisher := range m.publishers {
+		errs = append(errs, publisher.Close())
+	}
+
+	return errors.Join(errs...)
+}
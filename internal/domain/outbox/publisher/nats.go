@@ -0,0 +1,59 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"github.com/nats-io/nats.go"
+	"strconv"
+	"task/internal/domain/outbox/entity"
+)
+
+// NATS publishes events to JetStream on "<subject>.<event type>". The
+// outbox id is sent as Nats-Msg-Id so the stream drops redeliveries that
+// fall inside its duplicate window.
+type NATS struct {
+	conn    *nats.Conn
+	js      nats.JetStreamContext
+	subject string
+}
+
+func NewNATS(url string, subject string) (*NATS, error) {
+	const op = "domain/outbox.publisher.NewNATS"
+
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &NATS{
+		conn:    conn,
+		js:      js,
+		subject: subject,
+	}, nil
+}
+
+func (n *NATS) Publish(ctx context.Context, event *entity.Event) error {
+	const op = "domain/outbox.publisher.NATS.Publish"
+
+	msg := nats.NewMsg(n.subject + "." + event.Type)
+	msg.Data = event.Payload
+	msg.Header.Set(nats.MsgIdHdr, strconv.FormatUint(event.ID, 10))
+	msg.Header.Set("Event-Type", event.Type)
+	msg.Header.Set("Account-Id", strconv.FormatUint(event.AccountID, 10))
+
+	if _, err := n.js.PublishMsg(msg, nats.Context(ctx)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (n *NATS) Close() error {
+	return n.conn.Drain()
+}
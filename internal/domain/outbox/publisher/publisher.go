@@ -0,0 +1,39 @@
+package publisher
+
+import (
+	"fmt"
+	"task/common"
+	"task/internal/domain/outbox/service"
+)
+
+const (
+	TypeMemory = "memory"
+	TypeFile   = "file"
+	TypeNATS   = "nats"
+	TypeKafka  = "kafka"
+)
+
+func New(cfg common.OutboxConfig) (service.Publisher, error) {
+	const op = "domain/outbox.publisher.New"
+
+	switch cfg.Publisher {
+	case TypeMemory:
+		return NewMemory(), nil
+	case TypeFile:
+		file, err := NewFile(cfg.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		return file, nil
+	case TypeNATS:
+		nats, err := NewNATS(cfg.NATS.URL, cfg.NATS.Subject)
+		if err != nil {
+			return nil, err
+		}
+		return nats, nil
+	case TypeKafka:
+		return NewKafka(cfg.Kafka.Brokers, cfg.Kafka.Topic), nil
+	default:
+		return nil, fmt.Errorf("%s: unknown publisher %q", op, cfg.Publisher)
+	}
+}
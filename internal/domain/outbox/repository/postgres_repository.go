@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"strconv"
+	"task/common"
+	"task/internal/domain/outbox/entity"
+	"time"
+)
+
+// relayLockKey is the advisory lock held while a relay claims a batch, so
+// that two instances never claim events of the same account and publish
+// them out of order.
+const relayLockKey = 7_301_026
+
+// notifyChannel is where the outbox_notify trigger sends the id of every
+// event inserted into the outbox.
+const notifyChannel = "outbox"
+
+type PostgresRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{
+		db: pool,
+	}
+}
+
+func (r *PostgresRepository) Save(ctx context.Context, event *entity.Event) error {
+	const op = "domain/outbox.PostgresRepository.Save"
+
+	query := `
+		INSERT INTO outbox (
+			aggregate_type,
+			aggregate_id,
+			account_id,
+			event_type,
+			payload
+		) VALUES (
+			@aggregate_type,
+			@aggregate_id,
+			@account_id,
+			@event_type,
+			@payload
+		)
+		RETURNING id, created_at
+	`
+
+	args := pgx.NamedArgs{
+		"aggregate_type": event.AggregateType,
+		"aggregate_id":   event.AggregateID,
+		"account_id":     event.AccountID,
+		"event_type":     event.Type,
+		"payload":        event.Payload,
+	}
+
+	if err := common.Conn(ctx, r.db).QueryRow(ctx, query, args).Scan(&event.ID, &event.CreatedAt); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) TryLock(ctx context.Context) (bool, error) {
+	const op = "domain/outbox.PostgresRepository.TryLock"
+
+	query := `SELECT pg_try_advisory_xact_lock(@key)`
+
+	args := pgx.NamedArgs{
+		"key": relayLockKey,
+	}
+
+	var locked bool
+
+	if err := common.Conn(ctx, r.db).QueryRow(ctx, query, args).Scan(&locked); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return locked, nil
+}
+
+// ClaimUnpublished claims the first pending events for lease, leaving out
+// parked events, events waiting to be retried and accounts whose earlier
+// events another relay still has claimed or are waiting to be retried.
+func (r *PostgresRepository) ClaimUnpublished(ctx context.Context, limit int, lease time.Duration) ([]*entity.Event, error) {
+	const op = "domain/outbox.PostgresRepository.ClaimUnpublished"
+
+	query := `
+		WITH claimed AS (
+			UPDATE outbox
+			SET claimed_until = now() + @lease::interval
+			WHERE id IN (
+				SELECT o.id FROM outbox o
+				WHERE o.published_at IS NULL
+					AND o.parked_at IS NULL
+					AND (o.claimed_until IS NULL OR o.claimed_until <= now())
+					AND (o.next_attempt_at IS NULL OR o.next_attempt_at <= now())
+					AND NOT EXISTS (
+						SELECT 1 FROM outbox c
+						WHERE c.account_id = o.account_id
+							AND c.published_at IS NULL
+							AND c.parked_at IS NULL
+							AND (c.claimed_until > now() OR (c.id < o.id AND c.next_attempt_at > now()))
+					)
+				ORDER BY o.id
+				LIMIT @limit
+			)
+			RETURNING id, aggregate_type, aggregate_id, account_id, event_type, payload, created_at, attempts
+		)
+		SELECT id, aggregate_type, aggregate_id, account_id, event_type, payload, created_at, attempts FROM claimed
+		ORDER BY id
+	`
+
+	args := pgx.NamedArgs{
+		"limit": limit,
+		"lease": lease,
+	}
+
+	var events []*entity.Event
+
+	if err := pgxscan.Select(ctx, common.Conn(ctx, r.db), &events, query, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return events, nil
+}
+
+// Release gives up the claim on events that were not published, so that
+// they are claimed again by the next batch.
+func (r *PostgresRepository) Release(ctx context.Context, ids []uint64) error {
+	const op = "domain/outbox.PostgresRepository.Release"
+
+	query := `
+		UPDATE outbox
+		SET claimed_until = NULL
+		WHERE id = ANY(@ids) AND published_at IS NULL
+	`
+
+	args := pgx.NamedArgs{
+		"ids": ids,
+	}
+
+	if _, err := common.Conn(ctx, r.db).Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) MarkPublished(ctx context.Context, id uint64) error {
+	const op = "domain/outbox.PostgresRepository.MarkPublished"
+
+	query := `
+		UPDATE outbox
+		SET published_at = now(),
+			attempts = attempts + 1,
+			last_error = NULL
+		WHERE id = @id
+	`
+
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	if _, err := common.Conn(ctx, r.db).Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// MarkFailed gives up the claim on an event that could not be published
+// and holds it back for retryIn.
+func (r *PostgresRepository) MarkFailed(ctx context.Context, id uint64, reason string, retryIn time.Duration) error {
+	const op = "domain/outbox.PostgresRepository.MarkFailed"
+
+	query := `
+		UPDATE outbox
+		SET attempts = attempts + 1,
+			last_error = @last_error,
+			claimed_until = NULL,
+			next_attempt_at = now() + @retry_in::interval
+		WHERE id = @id
+	`
+
+	args := pgx.NamedArgs{
+		"id":         id,
+		"last_error": reason,
+		"retry_in":   retryIn,
+	}
+
+	if _, err := common.Conn(ctx, r.db).Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Park records the last failure of an event that ran out of attempts and
+// stops relaying it.
+func (r *PostgresRepository) Park(ctx context.Context, id uint64, reason string) error {
+	const op = "domain/outbox.PostgresRepository.Park"
+
+	query := `
+		UPDATE outbox
+		SET attempts = attempts + 1,
+			last_error = @last_error,
+			claimed_until = NULL,
+			parked_at = now()
+		WHERE id = @id
+	`
+
+	args := pgx.NamedArgs{
+		"id":         id,
+		"last_error": reason,
+	}
+
+	if _, err := common.Conn(ctx, r.db).Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) ListByAccountSince(ctx context.Context, accountID uint64, afterID uint64, limit int) ([]*entity.Event, error) {
+	const op = "domain/outbox.PostgresRepository.ListByAccountSince"
+
+	query := `
+		SELECT id, aggregate_type, aggregate_id, account_id, event_type, payload, created_at FROM outbox
+		WHERE account_id = @account_id AND id > @after_id
+		ORDER BY id
+		LIMIT @limit
+	`
+
+	args := pgx.NamedArgs{
+		"account_id": accountID,
+		"after_id":   afterID,
+		"limit":      limit,
+	}
+
+	var events []*entity.Event
+
+	if err := pgxscan.Select(ctx, common.Conn(ctx, r.db), &events, query, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return events, nil
+}
+
+func (r *PostgresRepository) GetByID(ctx context.Context, id uint64) (*entity.Event, error) {
+	const op = "domain/outbox.PostgresRepository.GetByID"
+
+	query := `
+		SELECT id, aggregate_type, aggregate_id, account_id, event_type, payload, created_at FROM outbox
+		WHERE id = @id
+	`
+
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	var event entity.Event
+
+	if err := pgxscan.Get(ctx, common.Conn(ctx, r.db), &event, query, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &event, nil
+}
+
+func (r *PostgresRepository) ListSince(ctx context.Context, afterID uint64, limit int) ([]*entity.Event, error) {
+	const op = "domain/outbox.PostgresRepository.ListSince"
+
+	query := `
+		SELECT id, aggregate_type, aggregate_id, account_id, event_type, payload, created_at FROM outbox
+		WHERE id > @after_id
+		ORDER BY id
+		LIMIT @limit
+	`
+
+	args := pgx.NamedArgs{
+		"after_id": afterID,
+		"limit":    limit,
+	}
+
+	var events []*entity.Event
+
+	if err := pgxscan.Select(ctx, common.Conn(ctx, r.db), &events, query, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return events, nil
+}
+
+// Listen calls listening once it listens for new events, then notify with
+// the id of every event committed afterwards, until ctx is cancelled or a
+// callback fails. It holds a connection of its own, which is closed rather
+// than returned to the pool.
+func (r *PostgresRepository) Listen(ctx context.Context, listening func(ctx context.Context) error, notify func(ctx context.Context, id uint64) error) error {
+	const op = "domain/outbox.PostgresRepository.Listen"
+
+	pooled, err := r.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background()) //nolint:errcheck
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{notifyChannel}.Sanitize()); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := listening(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		id, err := strconv.ParseUint(notification.Payload, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: invalid event id %q", op, notification.Payload)
+		}
+
+		if err := notify(ctx, id); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+}
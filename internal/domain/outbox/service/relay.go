@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"golang.org/x/exp/slog"
+	"task/common"
+	"task/internal/domain/outbox/entity"
+	"task/internal/domain/outbox/repository"
+	"task/internal/metrics"
+	"time"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Repository
+type Repository interface {
+	TryLock(ctx context.Context) (bool, error)
+	ClaimUnpublished(ctx context.Context, limit int, lease time.Duration) ([]*entity.Event, error)
+	MarkPublished(ctx context.Context, id uint64) error
+	MarkFailed(ctx context.Context, id uint64, reason string, retryIn time.Duration) error
+	Park(ctx context.Context, id uint64, reason string) error
+	Release(ctx context.Context, ids []uint64) error
+}
+
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Publisher
+type Publisher interface {
+	Publish(ctx context.Context, event *entity.Event) error
+	Close() error
+}
+
+// Relay moves events from the outbox table to a Publisher. Delivery is
+// at-least-once: an event is marked published only after Publish succeeds.
+type Relay struct {
+	repository Repository
+	transactor Transactor
+	publisher  Publisher
+	interval   time.Duration
+	batchSize  int
+	lease      time.Duration
+
+	maxAttempts int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+}
+
+func NewRelay(di *common.DependencyContainer, publisher Publisher) *Relay {
+	return &Relay{
+		repository: repository.NewPostgresRepository(di.Pool),
+		transactor: common.NewTransactor(di.Pool),
+		publisher:  publisher,
+		interval:   di.Config.Outbox.PollInterval,
+		batchSize:  di.Config.Outbox.BatchSize,
+		lease:      di.Config.Outbox.Lease,
+
+		maxAttempts: di.Config.Outbox.MaxAttempts,
+		backoffBase: di.Config.Outbox.BackoffBase,
+		backoffMax:  di.Config.Outbox.BackoffMax,
+	}
+}
+
+// Run polls the outbox until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) error {
+	const op = "domain/outbox.Relay.Run"
+
+	logger := common.FromContext(ctx)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.ProcessBatch(ctx); err != nil {
+			logger.Error("failed to relay outbox events", slog.String("op", op), slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch claims a batch of pending events and publishes it in id
+// order, outside of any database transaction. Once an event of an account
+// fails, the rest of that account's events are released, and wait for it
+// to be retried, so that per-account ordering is preserved. An event that
+// runs out of attempts is parked instead, and no longer holds them up.
+// Events left claimed by a relay that stopped are claimed again when the
+// lease runs out.
+func (r *Relay) ProcessBatch(ctx context.Context) (int, error) {
+	const op = "domain/outbox.Relay.ProcessBatch"
+
+	var events []*entity.Event
+
+	err := r.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		locked, err := r.repository.TryLock(ctx)
+		if err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		events, err = r.repository.ClaimUnpublished(ctx, r.batchSize, r.lease)
+
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var published int
+	blocked := make(map[uint64]bool)
+	var released []uint64
+
+	for _, event := range events {
+		if blocked[event.AccountID] {
+			released = append(released, event.ID)
+			continue
+		}
+
+		if err := r.publisher.Publish(ctx, event); err != nil {
+			if err := r.fail(ctx, event, err); err != nil {
+				return published, fmt.Errorf("%s: %w", op, err)
+			}
+			// A parked event no longer holds up its account.
+			blocked[event.AccountID] = event.Attempts < r.maxAttempts
+			continue
+		}
+
+		if err := r.repository.MarkPublished(ctx, event.ID); err != nil {
+			return published, fmt.Errorf("%s: %w", op, err)
+		}
+		published++
+	}
+
+	if len(released) > 0 {
+		if err := r.repository.Release(ctx, released); err != nil {
+			return published, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return published, nil
+}
+
+// fail schedules the retry of an event that could not be published, or
+// parks it when it has run out of attempts.
+func (r *Relay) fail(ctx context.Context, event *entity.Event, publishErr error) error {
+	event.Attempts++
+
+	if event.Attempts < r.maxAttempts {
+		return r.repository.MarkFailed(ctx, event.ID, publishErr.Error(), r.backoff(event.Attempts))
+	}
+
+	common.FromContext(ctx).Error("outbox event parked",
+		slog.Uint64("event_id", event.ID),
+		slog.Uint64("account_id", event.AccountID),
+		slog.String("event_type", event.Type),
+		slog.Int("attempts", event.Attempts),
+		slog.String("error", publishErr.Error()),
+	)
+	metrics.OutboxEventParked(event.Type)
+
+	return r.repository.Park(ctx, event.ID, publishErr.Error())
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.backoffBase
+	for i := 1; i < attempts && delay < r.backoffMax; i++ {
+		delay *= 2
+	}
+	if delay > r.backoffMax {
+		delay = r.backoffMax
+	}
+
+	return delay
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"task/internal/domain/outbox/entity"
+	"task/internal/domain/outbox/service/mocks"
+	"testing"
+	"time"
+)
+
+type inlineTransactor struct{}
+
+func (inlineTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestRelay_ProcessBatch(t *testing.T) {
+	cases := []struct {
+		name          string
+		locked        bool
+		attempts      map[uint64]int
+		failing       map[uint64]bool
+		wantPublished []uint64
+		wantFailed    map[uint64]time.Duration
+		wantParked    []uint64
+		wantReleased  []uint64
+	}{
+		{
+			name:          "Publishes all events in order",
+			locked:        true,
+			wantPublished: []uint64{1, 2, 3, 4},
+		},
+		{
+			name:          "Failed event blocks later events of the same account",
+			locked:        true,
+			failing:       map[uint64]bool{1: true},
+			wantPublished: []uint64{2, 4},
+			wantFailed:    map[uint64]time.Duration{1: time.Second},
+			wantReleased:  []uint64{3},
+		},
+		{
+			name:         "Retries back off",
+			locked:       true,
+			attempts:     map[uint64]int{1: 2, 2: 3},
+			failing:      map[uint64]bool{1: true, 2: true},
+			wantFailed:   map[uint64]time.Duration{1: 4 * time.Second, 2: 5 * time.Second},
+			wantReleased: []uint64{3, 4},
+		},
+		{
+			name:          "Event out of attempts is parked and unblocks its account",
+			locked:        true,
+			attempts:      map[uint64]int{1: 4},
+			failing:       map[uint64]bool{1: true},
+			wantPublished: []uint64{2, 3, 4},
+			wantParked:    []uint64{1},
+		},
+		{
+			name:   "Another instance holds the lock",
+			locked: false,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			rep := mocks.NewRepository(t)
+			pub := mocks.NewPublisher(t)
+
+			events := []*entity.Event{
+				{ID: 1, AccountID: 1, Type: entity.EventTransactionCreated},
+				{ID: 2, AccountID: 2, Type: entity.EventTransactionCreated},
+				{ID: 3, AccountID: 1, Type: entity.EventTransactionSettled},
+				{ID: 4, AccountID: 2, Type: entity.EventTransactionSettled},
+			}
+			for _, event := range events {
+				event.Attempts = tc.attempts[event.ID]
+			}
+
+			rep.On("TryLock", ctx).Return(tc.locked, nil)
+
+			if tc.locked {
+				rep.On("ClaimUnpublished", ctx, 10, time.Minute).Return(events, nil)
+				pub.On("Publish", ctx, mock.Anything).Return(func(_ context.Context, event *entity.Event) error {
+					if tc.failing[event.ID] {
+						return errors.New("broker unavailable")
+					}
+					return nil
+				}).Maybe()
+			}
+
+			var published []uint64
+			for _, id := range tc.wantPublished {
+				id := id
+				rep.On("MarkPublished", ctx, id).Run(func(mock.Arguments) {
+					published = append(published, id)
+				}).Return(nil).Once()
+			}
+			for id, retryIn := range tc.wantFailed {
+				rep.On("MarkFailed", ctx, id, "broker unavailable", retryIn).Return(nil).Once()
+			}
+			for _, id := range tc.wantParked {
+				rep.On("Park", ctx, id, "broker unavailable").Return(nil).Once()
+			}
+			if tc.wantReleased != nil {
+				rep.On("Release", ctx, tc.wantReleased).Return(nil).Once()
+			}
+
+			relay := &Relay{
+				repository: rep,
+				transactor: inlineTransactor{},
+				publisher:  pub,
+				batchSize:  10,
+				lease:      time.Minute,
+
+				maxAttempts: 5,
+				backoffBase: time.Second,
+				backoffMax:  5 * time.Second,
+			}
+
+			n, err := relay.ProcessBatch(ctx)
+			require.NoError(t, err)
+			require.Equal(t, len(tc.wantPublished), n)
+			require.Equal(t, tc.wantPublished, published)
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"task/internal/api/validate"
+	"task/internal/domain/Errors"
+	"task/internal/domain/rbac/controller/request"
+	"task/internal/domain/rbac/entity"
+	"task/internal/domain/rbac/service"
+)
+
+type Handlers struct {
+	service *service.Service
+}
+
+func NewHandlers(service *service.Service) *Handlers {
+	return &Handlers{
+		service: service,
+	}
+}
+
+func (h *Handlers) ListPermissions(w http.ResponseWriter, r *http.Request) error {
+	const op = "rbac.Handlers.ListPermissions"
+	ctx := r.Context()
+
+	permissions, err := h.service.ListPermissions(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponsePermissionsOK(w, r, permissions)
+
+	return nil
+}
+
+func (h *Handlers) ListRoles(w http.ResponseWriter, r *http.Request) error {
+	const op = "rbac.Handlers.ListRoles"
+	ctx := r.Context()
+
+	roles, err := h.service.ListRoles(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseRolesOK(w, r, roles)
+
+	return nil
+}
+
+func (h *Handlers) GetRole(w http.ResponseWriter, r *http.Request) error {
+	const op = "rbac.Handlers.GetRole"
+	ctx := r.Context()
+
+	role, err := h.service.GetRole(ctx, chi.URLParam(r, "role"))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseRoleOK(w, r, role)
+
+	return nil
+}
+
+func (h *Handlers) CreateRole(w http.ResponseWriter, r *http.Request) error {
+	const op = "rbac.Handlers.CreateRole"
+	ctx := r.Context()
+
+	var req request.RequestRole
+
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	role, err := h.service.CreateRole(ctx, &entity.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseRoleOK(w, r, role)
+
+	return nil
+}
+
+func (h *Handlers) DeleteRole(w http.ResponseWriter, r *http.Request) error {
+	const op = "rbac.Handlers.DeleteRole"
+	ctx := r.Context()
+
+	if err := h.service.DeleteRole(ctx, chi.URLParam(r, "role")); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseOK(w, r)
+
+	return nil
+}
+
+func (h *Handlers) GrantPermission(w http.ResponseWriter, r *http.Request) error {
+	const op = "rbac.Handlers.GrantPermission"
+	ctx := r.Context()
+
+	var req request.RequestPermission
+
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	role, err := h.service.GrantPermission(ctx, chi.URLParam(r, "role"), req.Permission)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseRoleOK(w, r, role)
+
+	return nil
+}
+
+func (h *Handlers) RevokePermission(w http.ResponseWriter, r *http.Request) error {
+	const op = "rbac.Handlers.RevokePermission"
+	ctx := r.Context()
+
+	role, err := h.service.RevokePermission(ctx, chi.URLParam(r, "role"), chi.URLParam(r, "permission"))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseRoleOK(w, r, role)
+
+	return nil
+}
+
+func (h *Handlers) ListAccountRoles(w http.ResponseWriter, r *http.Request) error {
+	const op = "rbac.Handlers.ListAccountRoles"
+	ctx := r.Context()
+
+	id, err := GetIDFromRequest(r, "account_id")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	roles, err := h.service.ListAccountRoles(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseAccountRolesOK(w, r, id, roles)
+
+	return nil
+}
+
+func (h *Handlers) AssignRole(w http.ResponseWriter, r *http.Request) error {
+	const op = "rbac.Handlers.AssignRole"
+	ctx := r.Context()
+
+	id, err := GetIDFromRequest(r, "account_id")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	roles, err := h.service.AssignRole(ctx, id, chi.URLParam(r, "role"))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseAccountRolesOK(w, r, id, roles)
+
+	return nil
+}
+
+func (h *Handlers) UnassignRole(w http.ResponseWriter, r *http.Request) error {
+	const op = "rbac.Handlers.UnassignRole"
+	ctx := r.Context()
+
+	id, err := GetIDFromRequest(r, "account_id")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	roles, err := h.service.UnassignRole(ctx, id, chi.URLParam(r, "role"))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseAccountRolesOK(w, r, id, roles)
+
+	return nil
+}
+
+func GetIDFromRequest(r *http.Request, key string) (uint64, error) {
+	param := chi.URLParam(r, key)
+	if param == "" {
+		return 0, fmt.Errorf("%w: empty parameter %s", Errors.ErrIncorrectID, key)
+	}
+
+	value, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid parameter %s", Errors.ErrIncorrectID, key)
+	}
+
+	return value, nil
+}
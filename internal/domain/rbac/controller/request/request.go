@@ -0,0 +1,87 @@
+package request
+
+import (
+	"net/http"
+	"task/internal/api/response"
+	"task/internal/domain/rbac/entity"
+)
+
+type RequestRole struct {
+	Name        string   `json:"name" validate:"required,max=50"`
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" validate:"dive,required"`
+}
+
+type RequestPermission struct {
+	Permission string `json:"permission" validate:"required"`
+}
+
+type ResponsePermissions struct {
+	response.Response
+	Permissions []*entity.Permission `json:"permissions"`
+}
+
+type ResponseRole struct {
+	response.Response
+	Role *entity.Role `json:"role"`
+}
+
+type ResponseRoles struct {
+	response.Response
+	Roles []*entity.Role `json:"roles"`
+}
+
+type AccountRoles struct {
+	AccountID uint64   `json:"account_id"`
+	Roles     []string `json:"roles"`
+}
+
+type ResponseAccountRoles struct {
+	response.Response
+	AccountRoles
+}
+
+func ResponsePermissionsOK(w http.ResponseWriter, r *http.Request, permissions []*entity.Permission) {
+	response.Render(w, r, permissions, ResponsePermissions{
+		Response: response.Response{
+			Status: response.StatusSuccess,
+		},
+		Permissions: permissions,
+	})
+}
+
+func ResponseRoleOK(w http.ResponseWriter, r *http.Request, role *entity.Role) {
+	response.Render(w, r, role, ResponseRole{
+		Response: response.Response{
+			Status: response.StatusSuccess,
+		},
+		Role: role,
+	})
+}
+
+func ResponseRolesOK(w http.ResponseWriter, r *http.Request, roles []*entity.Role) {
+	response.Render(w, r, roles, ResponseRoles{
+		Response: response.Response{
+			Status: response.StatusSuccess,
+		},
+		Roles: roles,
+	})
+}
+
+func ResponseAccountRolesOK(w http.ResponseWriter, r *http.Request, accountID uint64, roles []string) {
+	response.Render(w, r, AccountRoles{AccountID: accountID, Roles: roles}, ResponseAccountRoles{
+		Response: response.Response{
+			Status: response.StatusSuccess,
+		},
+		AccountRoles: AccountRoles{
+			AccountID: accountID,
+			Roles:     roles,
+		},
+	})
+}
+
+func ResponseOK(w http.ResponseWriter, r *http.Request) {
+	response.Render(w, r, nil, response.Response{
+		Status: response.StatusSuccess,
+	})
+}
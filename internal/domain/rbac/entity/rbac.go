@@ -0,0 +1,54 @@
+package entity
+
+const (
+	RoleCustomer = "customer"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+)
+
+// Permissions checked by the API. They are rows of the permission table;
+// the constants only name the ones the code refers to.
+const (
+	PermAccountsRead       = "accounts:read"
+	PermAccountsWrite      = "accounts:write"
+	PermAccountsDelete     = "accounts:delete"
+	PermAccountsAny        = "accounts:any"
+	PermTransactionsRead   = "transactions:read"
+	PermTransactionsWrite  = "transactions:write"
+	PermTransactionsSettle = "transactions:settle"
+	PermTransactionsDelete = "transactions:delete"
+	PermWebhooksRead       = "webhooks:read"
+	PermWebhooksWrite      = "webhooks:write"
+	PermRolesManage        = "roles:manage"
+	PermAPIKeysManage      = "apikeys:manage"
+)
+
+// CustomerForbidden lists permissions the customer role can never be
+// granted, whatever the admin API is asked to do.
+var CustomerForbidden = []string{
+	PermTransactionsSettle,
+	PermTransactionsDelete,
+	PermAccountsWrite,
+	PermAccountsDelete,
+	PermAccountsAny,
+	PermRolesManage,
+	PermAPIKeysManage,
+}
+
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type Role struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Builtin     bool     `json:"builtin"`
+	Permissions []string `json:"permissions"`
+}
+
+// Grant is one permission reached through one role of an account.
+type Grant struct {
+	Role       string  `db:"role"`
+	Permission *string `db:"permission"`
+}
@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"task/common"
+	"task/internal/domain/Errors"
+	"task/internal/domain/rbac/entity"
+)
+
+type PostgresRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{
+		db: pool,
+	}
+}
+
+const selectRoles = `
+	SELECT r.name, r.description, r.builtin,
+		COALESCE(array_agg(rp.permission ORDER BY rp.permission) FILTER (WHERE rp.permission IS NOT NULL), '{}') AS permissions
+	FROM role r
+	LEFT JOIN role_permission rp ON rp.role = r.name
+`
+
+func (r *PostgresRepository) ListPermissions(ctx context.Context) ([]*entity.Permission, error) {
+	const op = "domain/rbac.PostgresRepository.ListPermissions"
+
+	query := `SELECT name, description FROM permission ORDER BY name`
+
+	var permissions []*entity.Permission
+
+	if err := pgxscan.Select(ctx, common.Conn(ctx, r.db), &permissions, query); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return permissions, nil
+}
+
+func (r *PostgresRepository) PermissionExists(ctx context.Context, name string) (bool, error) {
+	const op = "domain/rbac.PostgresRepository.PermissionExists"
+
+	query := `SELECT EXISTS (SELECT 1 FROM permission WHERE name = @name)`
+
+	args := pgx.NamedArgs{
+		"name": name,
+	}
+
+	var exists bool
+
+	if err := common.Conn(ctx, r.db).QueryRow(ctx, query, args).Scan(&exists); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return exists, nil
+}
+
+func (r *PostgresRepository) ListRoles(ctx context.Context) ([]*entity.Role, error) {
+	const op = "domain/rbac.PostgresRepository.ListRoles"
+
+	query := selectRoles + `
+		GROUP BY r.name
+		ORDER BY r.name
+	`
+
+	var roles []*entity.Role
+
+	if err := pgxscan.Select(ctx, common.Conn(ctx, r.db), &roles, query); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return roles, nil
+}
+
+func (r *PostgresRepository) GetRole(ctx context.Context, name string) (*entity.Role, error) {
+	const op = "domain/rbac.PostgresRepository.GetRole"
+
+	query := selectRoles + `
+		WHERE r.name = @name
+		GROUP BY r.name
+	`
+
+	args := pgx.NamedArgs{
+		"name": name,
+	}
+
+	var role entity.Role
+
+	if err := pgxscan.Get(ctx, common.Conn(ctx, r.db), &role, query, args); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, Errors.ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &role, nil
+}
+
+func (r *PostgresRepository) CreateRole(ctx context.Context, role *entity.Role) error {
+	const op = "domain/rbac.PostgresRepository.CreateRole"
+
+	query := `
+		INSERT INTO role (name, description)
+		VALUES (@name, @description)
+		ON CONFLICT (name) DO NOTHING
+	`
+
+	args := pgx.NamedArgs{
+		"name":        role.Name,
+		"description": role.Description,
+	}
+
+	tag, err := common.Conn(ctx, r.db).Exec(ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return Errors.ErrRoleExists
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) DeleteRole(ctx context.Context, name string) error {
+	const op = "domain/rbac.PostgresRepository.DeleteRole"
+
+	query := `
+		DELETE FROM role
+		WHERE name = @name
+	`
+
+	args := pgx.NamedArgs{
+		"name": name,
+	}
+
+	tag, err := common.Conn(ctx, r.db).Exec(ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return Errors.ErrRoleNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) GrantPermission(ctx context.Context, role string, permission string) error {
+	const op = "domain/rbac.PostgresRepository.GrantPermission"
+
+	query := `
+		INSERT INTO role_permission (role, permission)
+		VALUES (@role, @permission)
+		ON CONFLICT DO NOTHING
+	`
+
+	args := pgx.NamedArgs{
+		"role":       role,
+		"permission": permission,
+	}
+
+	if _, err := common.Conn(ctx, r.db).Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) RevokePermission(ctx context.Context, role string, permission string) error {
+	const op = "domain/rbac.PostgresRepository.RevokePermission"
+
+	query := `
+		DELETE FROM role_permission
+		WHERE role = @role AND permission = @permission
+	`
+
+	args := pgx.NamedArgs{
+		"role":       role,
+		"permission": permission,
+	}
+
+	if _, err := common.Conn(ctx, r.db).Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) ListAccountRoles(ctx context.Context, accountID uint64) ([]string, error) {
+	const op = "domain/rbac.PostgresRepository.ListAccountRoles"
+
+	query := `
+		SELECT role FROM account_role
+		WHERE account_id = @account_id
+		ORDER BY role
+	`
+
+	args := pgx.NamedArgs{
+		"account_id": accountID,
+	}
+
+	roles := []string{}
+
+	if err := pgxscan.Select(ctx, common.Conn(ctx, r.db), &roles, query, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return roles, nil
+}
+
+func (r *PostgresRepository) AssignRole(ctx context.Context, accountID uint64, role string) error {
+	const op = "domain/rbac.PostgresRepository.AssignRole"
+
+	query := `
+		INSERT INTO account_role (account_id, role)
+		VALUES (@account_id, @role)
+		ON CONFLICT DO NOTHING
+	`
+
+	args := pgx.NamedArgs{
+		"account_id": accountID,
+		"role":       role,
+	}
+
+	if _, err := common.Conn(ctx, r.db).Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) UnassignRole(ctx context.Context, accountID uint64, role string) error {
+	const op = "domain/rbac.PostgresRepository.UnassignRole"
+
+	query := `
+		DELETE FROM account_role
+		WHERE account_id = @account_id AND role = @role
+	`
+
+	args := pgx.NamedArgs{
+		"account_id": accountID,
+		"role":       role,
+	}
+
+	if _, err := common.Conn(ctx, r.db).Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ListGrants returns the permissions of an account through its roles. An
+// account without roles gets the customer role.
+func (r *PostgresRepository) ListGrants(ctx context.Context, accountID uint64) ([]*entity.Grant, error) {
+	const op = "domain/rbac.PostgresRepository.ListGrants"
+
+	query := `
+		WITH assigned AS (
+			SELECT role FROM account_role
+			WHERE account_id = @account_id
+			UNION ALL
+			SELECT @default_role::text
+			WHERE NOT EXISTS (SELECT 1 FROM account_role WHERE account_id = @account_id)
+		)
+		SELECT a.role, rp.permission FROM assigned a
+		LEFT JOIN role_permission rp ON rp.role = a.role
+	`
+
+	args := pgx.NamedArgs{
+		"account_id":   accountID,
+		"default_role": entity.RoleCustomer,
+	}
+
+	var grants []*entity.Grant
+
+	if err := pgxscan.Select(ctx, common.Conn(ctx, r.db), &grants, query, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return grants, nil
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"task/common"
+	"task/internal/domain/Errors"
+	account "task/internal/domain/account/entity"
+	accRep "task/internal/domain/account/repository"
+	"task/internal/domain/rbac/entity"
+	"task/internal/domain/rbac/repository"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Repository
+type Repository interface {
+	ListPermissions(ctx context.Context) ([]*entity.Permission, error)
+	PermissionExists(ctx context.Context, name string) (bool, error)
+	ListRoles(ctx context.Context) ([]*entity.Role, error)
+	GetRole(ctx context.Context, name string) (*entity.Role, error)
+	CreateRole(ctx context.Context, role *entity.Role) error
+	DeleteRole(ctx context.Context, name string) error
+	GrantPermission(ctx context.Context, role string, permission string) error
+	RevokePermission(ctx context.Context, role string, permission string) error
+	ListAccountRoles(ctx context.Context, accountID uint64) ([]string, error)
+	AssignRole(ctx context.Context, accountID uint64, role string) error
+	UnassignRole(ctx context.Context, accountID uint64, role string) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Repository_account
+type Repository_account interface {
+	Get(ctx context.Context, id uint64) (*account.Account, error)
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Transactor
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+var roleName = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+
+type Service struct {
+	repository Repository
+	repAccount Repository_account
+	transactor Transactor
+}
+
+func NewService(di *common.DependencyContainer) *Service {
+	return &Service{
+		repository: repository.NewPostgresRepository(di.Pool),
+		repAccount: accRep.NewPostgresRepository(di.Pool),
+		transactor: common.NewTransactor(di.Pool),
+	}
+}
+
+func (s *Service) ListPermissions(ctx context.Context) ([]*entity.Permission, error) {
+	const op = "domain/rbac.Service.ListPermissions"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	permissions, err := s.repository.ListPermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return permissions, nil
+}
+
+func (s *Service) ListRoles(ctx context.Context) ([]*entity.Role, error) {
+	const op = "domain/rbac.Service.ListRoles"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	roles, err := s.repository.ListRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return roles, nil
+}
+
+func (s *Service) GetRole(ctx context.Context, name string) (*entity.Role, error) {
+	const op = "domain/rbac.Service.GetRole"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	role, err := s.repository.GetRole(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return role, nil
+}
+
+// CreateRole stores a custom role together with its initial permissions.
+func (s *Service) CreateRole(ctx context.Context, role *entity.Role) (*entity.Role, error) {
+	const op = "domain/rbac.Service.CreateRole"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	if !roleName.MatchString(role.Name) {
+		return nil, fmt.Errorf("%s: %w", op, Errors.ErrInvalidRoleName)
+	}
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repository.CreateRole(ctx, role); err != nil {
+			return err
+		}
+
+		for _, permission := range role.Permissions {
+			if err := s.grant(ctx, role.Name, permission); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s.GetRole(ctx, role.Name)
+}
+
+func (s *Service) DeleteRole(ctx context.Context, name string) error {
+	const op = "domain/rbac.Service.DeleteRole"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	role, err := s.repository.GetRole(ctx, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if role.Builtin {
+		return fmt.Errorf("%s: %w", op, Errors.ErrBuiltinRole)
+	}
+
+	if err := s.repository.DeleteRole(ctx, name); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Service) GrantPermission(ctx context.Context, role string, permission string) (*entity.Role, error) {
+	const op = "domain/rbac.Service.GrantPermission"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	if _, err := s.repository.GetRole(ctx, role); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.grant(ctx, role, permission); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s.GetRole(ctx, role)
+}
+
+func (s *Service) RevokePermission(ctx context.Context, role string, permission string) (*entity.Role, error) {
+	const op = "domain/rbac.Service.RevokePermission"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	if _, err := s.repository.GetRole(ctx, role); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.repository.RevokePermission(ctx, role, permission); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s.GetRole(ctx, role)
+}
+
+func (s *Service) ListAccountRoles(ctx context.Context, accountID uint64) ([]string, error) {
+	const op = "domain/rbac.Service.ListAccountRoles"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	if _, err := s.repAccount.Get(ctx, accountID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	roles, err := s.repository.ListAccountRoles(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return roles, nil
+}
+
+func (s *Service) AssignRole(ctx context.Context, accountID uint64, role string) ([]string, error) {
+	const op = "domain/rbac.Service.AssignRole"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	if _, err := s.repAccount.Get(ctx, accountID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := s.repository.GetRole(ctx, role); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.repository.AssignRole(ctx, accountID, role); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s.ListAccountRoles(ctx, accountID)
+}
+
+func (s *Service) UnassignRole(ctx context.Context, accountID uint64, role string) ([]string, error) {
+	const op = "domain/rbac.Service.UnassignRole"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	if _, err := s.repAccount.Get(ctx, accountID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.repository.UnassignRole(ctx, accountID, role); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s.ListAccountRoles(ctx, accountID)
+}
+
+// grant enforces the default policy: the customer role never gets
+// operator permissions such as settling or deleting transactions.
+func (s *Service) grant(ctx context.Context, role string, permission string) error {
+	if role == entity.RoleCustomer {
+		for _, forbidden := range entity.CustomerForbidden {
+			if permission == forbidden {
+				return fmt.Errorf("%w: %s", Errors.ErrPolicyViolation, permission)
+			}
+		}
+	}
+
+	exists, err := s.repository.PermissionExists(ctx, permission)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s", Errors.ErrPermissionNotFound, permission)
+	}
+
+	return s.repository.GrantPermission(ctx, role, permission)
+}
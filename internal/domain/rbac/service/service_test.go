@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"task/internal/domain/Errors"
+	"task/internal/domain/rbac/entity"
+	"task/internal/domain/rbac/service/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_GrantPermission(t *testing.T) {
+	cases := []struct {
+		name       string
+		role       string
+		permission string
+		exists     bool
+		wantErr    error
+	}{
+		{
+			name:       "Operator settles",
+			role:       entity.RoleOperator,
+			permission: entity.PermTransactionsSettle,
+			exists:     true,
+		},
+		{
+			name:       "Customer never settles",
+			role:       entity.RoleCustomer,
+			permission: entity.PermTransactionsSettle,
+			wantErr:    Errors.ErrPolicyViolation,
+		},
+		{
+			name:       "Customer never deletes transactions",
+			role:       entity.RoleCustomer,
+			permission: entity.PermTransactionsDelete,
+			wantErr:    Errors.ErrPolicyViolation,
+		},
+		{
+			name:       "Unknown permission",
+			role:       entity.RoleOperator,
+			permission: "transactions:teleport",
+			wantErr:    Errors.ErrPermissionNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			rep := mocks.NewRepository(t)
+
+			rep.On("GetRole", ctx, tc.role).Return(&entity.Role{Name: tc.role, Builtin: true}, nil)
+
+			if tc.wantErr != Errors.ErrPolicyViolation {
+				rep.On("PermissionExists", ctx, tc.permission).Return(tc.exists, nil)
+			}
+			if tc.wantErr == nil {
+				rep.On("GrantPermission", ctx, tc.role, tc.permission).Return(nil)
+			}
+
+			s := &Service{repository: rep}
+
+			_, err := s.GrantPermission(ctx, tc.role, tc.permission)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestService_DeleteRole(t *testing.T) {
+	ctx := context.Background()
+	rep := mocks.NewRepository(t)
+
+	rep.On("GetRole", ctx, entity.RoleCustomer).Return(&entity.Role{Name: entity.RoleCustomer, Builtin: true}, nil)
+	rep.On("GetRole", ctx, "auditor").Return(&entity.Role{Name: "auditor"}, nil)
+	rep.On("DeleteRole", ctx, "auditor").Return(nil)
+
+	s := &Service{repository: rep}
+
+	require.ErrorIs(t, s.DeleteRole(ctx, entity.RoleCustomer), Errors.ErrBuiltinRole)
+	require.NoError(t, s.DeleteRole(ctx, "auditor"))
+}
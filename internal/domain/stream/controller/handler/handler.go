@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"task/common"
+	"task/internal/domain/Errors"
+	"task/internal/domain/outbox/entity"
+	"task/internal/domain/stream/service"
+	"time"
+)
+
+const (
+	EventBalance      = "balance"
+	HeaderLastEventID = "Last-Event-ID"
+	heartbeatInterval = 15 * time.Second
+)
+
+type Handlers struct {
+	service *service.Service
+}
+
+func NewHandlers(di *common.DependencyContainer, broadcaster *service.Broadcaster) *Handlers {
+	return &Handlers{
+		service: service.NewService(di, broadcaster),
+	}
+}
+
+// Stream pushes account events as Server-Sent Events. It starts with a
+// balance snapshot, replays events after Last-Event-ID (header or
+// last_event_id query parameter) and then streams live events.
+func (h *Handlers) Stream(w http.ResponseWriter, r *http.Request) error {
+	const op = "stream.Handlers.Stream"
+	ctx := r.Context()
+
+	id, err := GetIDFromRequest(r, "account_id")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	lastEventID, err := getLastEventID(r)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	sub, account, err := h.service.Open(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer h.service.Close(sub)
+
+	// The server WriteTimeout would cut the stream, so lift it for this
+	// response only. The controller unwraps the middleware writers.
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	logger := common.FromRequest(r)
+
+	if err := writeEvent(w, "", EventBalance, account); err != nil {
+		logger.Debug("stream closed", "error", err.Error())
+		return nil
+	}
+
+	if lastEventID > 0 {
+		var writeErr error
+		err := h.service.Replay(ctx, id, lastEventID, func(event *entity.Event) error {
+			if writeErr = writeEvent(w, strconv.FormatUint(event.ID, 10), event.Type, event.Payload); writeErr != nil {
+				return writeErr
+			}
+			lastEventID = event.ID
+			return nil
+		})
+		if writeErr != nil {
+			logger.Debug("stream closed", "error", writeErr.Error())
+			return nil
+		}
+		// The response has started, so the client only sees the stream end
+		// and reconnects from the last event it got.
+		if err != nil {
+			logger.Error("failed to replay events", "op", op, "error", err.Error())
+			return nil
+		}
+	}
+
+	if err := rc.Flush(); err != nil {
+		return nil
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		var event *entity.Event
+		var ok bool
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return nil
+			}
+			if err := rc.Flush(); err != nil {
+				return nil
+			}
+			continue
+		case event, ok = <-sub.Events():
+			if !ok {
+				return nil
+			}
+		}
+
+		if event.ID <= lastEventID {
+			continue
+		}
+
+		if err := writeEvent(w, strconv.FormatUint(event.ID, 10), event.Type, event.Payload); err != nil {
+			logger.Debug("stream closed", "error", err.Error())
+			return nil
+		}
+		if err := rc.Flush(); err != nil {
+			return nil
+		}
+		lastEventID = event.ID
+	}
+}
+
+func writeEvent(w http.ResponseWriter, id string, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+
+	return err
+}
+
+func getLastEventID(r *http.Request) (uint64, error) {
+	value := r.Header.Get(HeaderLastEventID)
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid last event id %q", Errors.ErrIncorrectID, value)
+	}
+
+	return id, nil
+}
+
+func GetIDFromRequest(r *http.Request, key string) (uint64, error) {
+	param := chi.URLParam(r, key)
+	if param == "" {
+		return 0, fmt.Errorf("%w: empty parameter %s", Errors.ErrIncorrectID, key)
+	}
+
+	value, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid parameter %s", Errors.ErrIncorrectID, key)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"task/internal/domain/outbox/entity"
+)
+
+const subscriptionBuffer = 64
+
+type Subscription struct {
+	accountID uint64
+	events    chan *entity.Event
+}
+
+func (s *Subscription) Events() <-chan *entity.Event {
+	return s.events
+}
+
+// Broadcaster fans outbox events out to in-process subscribers of an
+// account. Each instance has its own, fed by a Listener. A subscriber that
+// cannot keep up is dropped; its client reconnects with Last-Event-ID and
+// resumes.
+type Broadcaster struct {
+	mu          sync.Mutex
+	subscribers map[uint64]map[*Subscription]struct{}
+	closed      bool
+}
+
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{
+		subscribers: make(map[uint64]map[*Subscription]struct{}),
+	}
+}
+
+func (b *Broadcaster) Subscribe(accountID uint64) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := &Subscription{
+		accountID: accountID,
+		events:    make(chan *entity.Event, subscriptionBuffer),
+	}
+
+	// Streams opened while the server shuts down end right away.
+	if b.closed {
+		close(sub.events)
+		return sub
+	}
+
+	if b.subscribers[accountID] == nil {
+		b.subscribers[accountID] = make(map[*Subscription]struct{})
+	}
+	b.subscribers[accountID][sub] = struct{}{}
+
+	return sub
+}
+
+func (b *Broadcaster) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(sub)
+}
+
+func (b *Broadcaster) Publish(_ context.Context, event *entity.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers[event.AccountID] {
+		select {
+		case sub.events <- event:
+		default:
+			b.remove(sub)
+		}
+	}
+
+	return nil
+}
+
+// Close ends every stream, and those opened later. Open streams would
+// otherwise keep the HTTP server from shutting down.
+func (b *Broadcaster) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+
+	for _, subs := range b.subscribers {
+		for sub := range subs {
+			b.remove(sub)
+		}
+	}
+
+	return nil
+}
+
+func (b *Broadcaster) remove(sub *Subscription) {
+	subs, ok := b.subscribers[sub.accountID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	close(sub.events)
+
+	if len(subs) == 0 {
+		delete(b.subscribers, sub.accountID)
+	}
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"task/internal/domain/outbox/entity"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcaster_Publish(t *testing.T) {
+	ctx := context.Background()
+	b := NewBroadcaster()
+
+	first := b.Subscribe(1)
+	other := b.Subscribe(2)
+
+	require.NoError(t, b.Publish(ctx, &entity.Event{ID: 1, AccountID: 1}))
+
+	event := <-first.Events()
+	require.Equal(t, uint64(1), event.ID)
+	require.Len(t, other.Events(), 0)
+
+	for i := 0; i <= subscriptionBuffer; i++ {
+		require.NoError(t, b.Publish(ctx, &entity.Event{ID: uint64(i + 2), AccountID: 1}))
+	}
+
+	for range first.Events() {
+	}
+	_, ok := <-first.Events()
+	require.False(t, ok, "slow subscriber must be dropped")
+
+	b.Unsubscribe(first)
+	require.NoError(t, b.Close())
+
+	_, ok = <-other.Events()
+	require.False(t, ok)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"golang.org/x/exp/slog"
+	"task/common"
+	"task/internal/domain/outbox/entity"
+	"task/internal/domain/outbox/repository"
+	"time"
+)
+
+type Repository_listener interface {
+	Listen(ctx context.Context, listening func(ctx context.Context) error, notify func(ctx context.Context, id uint64) error) error
+	GetByID(ctx context.Context, id uint64) (*entity.Event, error)
+	ListSince(ctx context.Context, afterID uint64, limit int) ([]*entity.Event, error)
+}
+
+// Listener feeds the broadcaster of this instance with the events committed
+// to the outbox, whichever instance wrote them and whichever relays them.
+type Listener struct {
+	broadcaster *Broadcaster
+	repository  Repository_listener
+	retry       time.Duration
+	batchSize   int
+	lastID      uint64
+}
+
+func NewListener(di *common.DependencyContainer, broadcaster *Broadcaster) *Listener {
+	return &Listener{
+		broadcaster: broadcaster,
+		repository:  repository.NewPostgresRepository(di.Pool),
+		retry:       di.Config.Outbox.PollInterval,
+		batchSize:   di.Config.Outbox.BatchSize,
+	}
+}
+
+// Run listens until ctx is cancelled. When the connection is lost it listens
+// again, and first catches up with the events committed in the meantime.
+func (l *Listener) Run(ctx context.Context) error {
+	const op = "domain/stream.Listener.Run"
+
+	logger := common.FromContext(ctx)
+
+	for {
+		err := l.repository.Listen(ctx, l.catchUp, l.notify)
+		if ctx.Err() != nil {
+			return nil
+		}
+		logger.Error("failed to listen for outbox events", slog.String("op", op), slog.String("error", err.Error()))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(l.retry):
+		}
+	}
+}
+
+func (l *Listener) notify(ctx context.Context, id uint64) error {
+	event, err := l.repository.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	l.publish(ctx, event)
+
+	return nil
+}
+
+// catchUp publishes the events after the last one published. Nothing
+// precedes the first connection: streams replay their own backlog.
+func (l *Listener) catchUp(ctx context.Context) error {
+	if l.lastID == 0 {
+		return nil
+	}
+
+	for {
+		events, err := l.repository.ListSince(ctx, l.lastID, l.batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			l.publish(ctx, event)
+		}
+
+		if len(events) < l.batchSize {
+			return nil
+		}
+	}
+}
+
+func (l *Listener) publish(ctx context.Context, event *entity.Event) {
+	l.broadcaster.Publish(ctx, event) //nolint:errcheck
+
+	if event.ID > l.lastID {
+		l.lastID = event.ID
+	}
+}
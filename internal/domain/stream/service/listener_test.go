@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"task/internal/domain/outbox/entity"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// outbox holds events by id and hands out notifications, one connection at
+// a time.
+type outbox struct {
+	events      map[uint64]*entity.Event
+	connections [][]uint64
+}
+
+func (o *outbox) Listen(ctx context.Context, listening func(ctx context.Context) error, notify func(ctx context.Context, id uint64) error) error {
+	if len(o.connections) == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	notifications := o.connections[0]
+	o.connections = o.connections[1:]
+
+	if err := listening(ctx); err != nil {
+		return err
+	}
+	for _, id := range notifications {
+		if err := notify(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return errors.New("connection lost")
+}
+
+func (o *outbox) GetByID(_ context.Context, id uint64) (*entity.Event, error) {
+	return o.events[id], nil
+}
+
+func (o *outbox) ListSince(_ context.Context, afterID uint64, limit int) ([]*entity.Event, error) {
+	var events []*entity.Event
+	for id := afterID + 1; len(events) < limit; id++ {
+		event, ok := o.events[id]
+		if !ok {
+			break
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func TestListener_Run(t *testing.T) {
+	events := make(map[uint64]*entity.Event)
+	for id := uint64(1); id <= 7; id++ {
+		events[id] = &entity.Event{ID: id, AccountID: 1}
+	}
+
+	broadcaster := NewBroadcaster()
+	sub := broadcaster.Subscribe(1)
+
+	listener := &Listener{
+		broadcaster: broadcaster,
+		// Events 3 to 6 are committed while the listener reconnects.
+		repository: &outbox{events: events, connections: [][]uint64{{1, 2}, {7}}},
+		batchSize:  2,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- listener.Run(ctx) }()
+
+	var got []uint64
+	for len(got) < 7 {
+		got = append(got, (<-sub.Events()).ID)
+	}
+	require.Equal(t, []uint64{1, 2, 3, 4, 5, 6, 7}, got)
+
+	cancel()
+	require.NoError(t, <-done)
+}
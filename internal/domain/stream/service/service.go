@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"task/common"
+	"task/internal/domain/Errors"
+	"task/internal/domain/account_dto/dto"
+	accRep "task/internal/domain/account_dto/repository"
+	"task/internal/domain/outbox/entity"
+	"task/internal/domain/outbox/repository"
+)
+
+// backlogPage is how many backlog events are read at a time.
+const backlogPage = 1000
+
+type Repository_outbox interface {
+	ListByAccountSince(ctx context.Context, accountID uint64, afterID uint64, limit int) ([]*entity.Event, error)
+}
+
+type Repository_acc_dto interface {
+	CheckExistsAccount(ctx context.Context, account_id uint64) (*dto.RegistrationCommand, error)
+}
+
+type Service struct {
+	broadcaster *Broadcaster
+	repOutbox   Repository_outbox
+	repAccDto   Repository_acc_dto
+}
+
+func NewService(di *common.DependencyContainer, broadcaster *Broadcaster) *Service {
+	return &Service{
+		broadcaster: broadcaster,
+		repOutbox:   repository.NewPostgresRepository(di.Pool),
+		repAccDto:   accRep.NewPostgresRepository(di.Pool),
+	}
+}
+
+// Open subscribes to live events of an account and returns its current
+// balance. The subscription is taken before the caller replays the backlog
+// so nothing falls between backlog and live events; callers skip live
+// events they have already seen in the backlog.
+func (s *Service) Open(ctx context.Context, accountID uint64) (*Subscription, *dto.RegistrationCommand, error) {
+	const op = "domain/stream.Service.Open"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	account, err := s.repAccDto.CheckExistsAccount(ctx, accountID)
+	if errors.Is(err, Errors.ErrAccountNotFound) {
+		return nil, nil, fmt.Errorf("%s: %w", op, Errors.ErrAccountNotFound)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s.broadcaster.Subscribe(accountID), account, nil
+}
+
+// Replay calls fn with every event of an account recorded after
+// lastEventID, in order, a page at a time.
+func (s *Service) Replay(ctx context.Context, accountID uint64, lastEventID uint64, fn func(event *entity.Event) error) error {
+	const op = "domain/stream.Service.Replay"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	for {
+		events, err := s.repOutbox.ListByAccountSince(ctx, accountID, lastEventID, backlogPage)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		for _, event := range events {
+			if err := fn(event); err != nil {
+				return err
+			}
+			lastEventID = event.ID
+		}
+
+		if len(events) < backlogPage {
+			return nil
+		}
+	}
+}
+
+func (s *Service) Close(sub *Subscription) {
+	s.broadcaster.Unsubscribe(sub)
+}
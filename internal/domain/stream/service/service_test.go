@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"task/internal/domain/outbox/entity"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type accountOutbox []*entity.Event
+
+func (o accountOutbox) ListByAccountSince(_ context.Context, accountID uint64, afterID uint64, limit int) ([]*entity.Event, error) {
+	var events []*entity.Event
+	for _, event := range o {
+		if event.AccountID == accountID && event.ID > afterID && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func TestService_Replay(t *testing.T) {
+	var events accountOutbox
+	for id := uint64(1); id <= 2*backlogPage+10; id++ {
+		events = append(events, &entity.Event{ID: id, AccountID: 1 + id%2})
+	}
+
+	s := &Service{repOutbox: events}
+
+	var got []uint64
+	err := s.Replay(context.Background(), 1, 100, func(event *entity.Event) error {
+		got = append(got, event.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, got, backlogPage+5-50)
+	require.Equal(t, uint64(102), got[0])
+	require.Equal(t, uint64(2*backlogPage+10), got[len(got)-1])
+
+	stop := errors.New("client gone")
+	err = s.Replay(context.Background(), 1, 0, func(event *entity.Event) error {
+		return stop
+	})
+	require.ErrorIs(t, err, stop)
+}
@@ -4,12 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/render"
-	"io"
 	"net/http"
 	"strconv"
-	"task/common"
-	"task/internal/api/response"
+	"task/internal/api/problem"
+	"task/internal/api/validate"
+	"task/internal/domain/Errors"
 	"task/internal/domain/transaction/controller/request"
 
 	//"task/internal/domain/account/controller/handler/request"
@@ -21,9 +20,9 @@ type Handlers struct {
 	service *service.Service
 }
 
-func NewHandlers(di *common.DependencyContainer) *Handlers {
+func NewHandlers(service *service.Service) *Handlers {
 	return &Handlers{
-		service: service.NewService(di),
+		service: service,
 	}
 }
 
@@ -31,22 +30,20 @@ func (h *Handlers) Deposit(w http.ResponseWriter, r *http.Request) error {
 	const op = "transaction.Handlers.Deposit"
 	ctx := r.Context()
 
-	var transaction entity.Transaction
+	var req request.RequestDeposit
 
-	err := render.DecodeJSON(r.Body, &transaction)
-
-	if errors.Is(err, io.EOF) {
-		render.JSON(w, r, response.Response{Error: "empty request", Status: "error"})
+	if err := validate.DecodeJSON(r, &req); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	if err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to decode request", Status: "error"})
-		return fmt.Errorf("%s: %w", op, err)
+	transaction := entity.Transaction{
+		ID:        req.ID,
+		AccountID: req.AccountID,
+		Amount:    req.Amount,
+		Currency:  req.Currency,
 	}
 
 	if _, err := h.service.CreateDepositTransaction(ctx, &transaction); err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to create deposit transaction", Status: "error"})
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -59,22 +56,25 @@ func (h *Handlers) Withdraw(w http.ResponseWriter, r *http.Request) error {
 	const op = "transaction.Handlers.Withdraw"
 	ctx := r.Context()
 
-	var transaction entity.Transaction
+	var req request.RequestWithdraw
 
-	err := render.DecodeJSON(r.Body, &transaction)
-
-	if errors.Is(err, io.EOF) {
-		render.JSON(w, r, response.Response{Error: "empty request", Status: "error"})
+	if err := validate.DecodeJSON(r, &req); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	if err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to decode request", Status: "error"})
-		return fmt.Errorf("%s: %w", op, err)
+	transaction := entity.Transaction{
+		ID:        req.ID,
+		AccountID: req.AccountID,
+		Amount:    req.Amount,
+		Currency:  req.Currency,
+		ToAccount: req.ToAccount,
 	}
 
-	if _, err := h.service.CreateWithdrawTransaction(ctx, &transaction); err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to create withdraw transaction", Status: "error"})
+	_, err := h.service.CreateWithdrawTransaction(ctx, &transaction)
+	if errors.Is(err, Errors.ErrStepUpRequired) || errors.Is(err, Errors.ErrInvalidOTP) || errors.Is(err, Errors.ErrTOTPNotEnabled) {
+		return fmt.Errorf("%s: %w", op, problem.Wrap(err, http.StatusForbidden, stepUpMessage(err), nil))
+	}
+	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -89,13 +89,11 @@ func (h *Handlers) GetTransactionByID(w http.ResponseWriter, r *http.Request) er
 
 	id, err := GetIDFromRequest(r, "transaction_id")
 	if err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to decode request", Status: "error"})
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	transaction, err := h.service.GetTransactionByID(ctx, id)
 	if err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to get transaction by id", Status: "error"})
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -112,13 +110,11 @@ func (h *Handlers) UpdateTransactionStatus(w http.ResponseWriter, r *http.Reques
 
 	id, err := GetIDFromRequest(r, "transaction_id")
 	if err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to decode request", Status: "error"})
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	err = h.service.UpdateTransactionStatus(ctx, id)
 	if err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to update status of transaction", Status: "error"})
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -133,13 +129,11 @@ func (h *Handlers) DeleteTransactionByID(w http.ResponseWriter, r *http.Request)
 
 	id, err := GetIDFromRequest(r, "transaction_id")
 	if err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to decode request", Status: "error"})
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	err = h.service.DeleteTransactionByID(ctx, id)
 	if err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to delete transaction", Status: "error"})
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -156,13 +150,11 @@ func (h *Handlers) GetFrozenBalanceByID(w http.ResponseWriter, r *http.Request)
 
 	id, err := GetIDFromRequest(r, "account_id")
 	if err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to decode request", Status: "error"})
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
 	accountDto, err := h.service.GetFrozenBalanceByAccountID(ctx, id)
 	if err != nil {
-		render.JSON(w, r, response.Response{Error: "failed to get frozen balance", Status: "error"})
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -171,15 +163,28 @@ func (h *Handlers) GetFrozenBalanceByID(w http.ResponseWriter, r *http.Request)
 	return nil
 }
 
+// stepUpMessage is the message key that tells the client what the step-up
+// check of a large withdrawal is missing.
+func stepUpMessage(err error) string {
+	switch {
+	case errors.Is(err, Errors.ErrTOTPNotEnabled):
+		return "error.totp_required_for_amount"
+	case errors.Is(err, Errors.ErrInvalidOTP):
+		return "error.invalid_otp"
+	default:
+		return "error.step_up_required"
+	}
+}
+
 func GetIDFromRequest(r *http.Request, key string) (uint64, error) {
 	param := chi.URLParam(r, key)
 	if param == "" {
-		return 0, fmt.Errorf("empty parameter %s", key)
+		return 0, fmt.Errorf("%w: empty parameter %s", Errors.ErrIncorrectID, key)
 	}
 
 	value, err := strconv.ParseUint(param, 10, 64)
 	if err != nil {
-		return 0, fmt.Errorf("invalid parameter %s", key)
+		return 0, fmt.Errorf("%w: invalid parameter %s", Errors.ErrIncorrectID, key)
 	}
 
 	return value, nil
@@ -1,40 +1,67 @@
 package request
 
 import (
-	"github.com/go-chi/render"
 	"net/http"
 	"task/internal/api/response"
 	"task/internal/domain/account_dto/dto"
 	"task/internal/domain/transaction/entity"
 )
 
+type RequestDeposit struct {
+	ID        uint64  `json:"id" validate:"required"`
+	AccountID uint64  `json:"account_id" validate:"required"`
+	Amount    float64 `json:"amount" validate:"gt=0,lte=1000000000"`
+	Currency  string  `json:"currency" validate:"required,currency"`
+}
+
+type RequestWithdraw struct {
+	ID        uint64  `json:"id" validate:"required"`
+	AccountID uint64  `json:"account_id" validate:"required"`
+	Amount    float64 `json:"amount" validate:"gt=0,lte=1000000000"`
+	Currency  string  `json:"currency" validate:"required,currency"`
+	ToAccount uint64  `json:"to_account" validate:"required"`
+}
+
+// ResponseTransaction is the legacy body: the transaction fields at the top
+// level. It used to embed response.Response as well, whose "status" clashed
+// with the transaction's, so that encoding/json wrote neither; now the
+// transaction status is written.
 type ResponseTransaction struct {
-	response.Response
 	entity.Transaction
 }
 
+type ResponseFrozenBalance struct {
+	response.Response
+	dto.RegistrationCommand
+}
+
+// FrozenBalance is the amount of pending withdrawals of an account.
+type FrozenBalance struct {
+	AccountID uint64  `json:"account_id"`
+	Currency  string  `json:"currency"`
+	Amount    float64 `json:"amount"`
+}
+
 func ResponseTransactionOK(w http.ResponseWriter, r *http.Request, transaction entity.Transaction) {
-	render.JSON(w, r, ResponseTransaction{
-		Response: response.Response{
-			Status: response.StatusSuccess,
-		},
+	response.Render(w, r, transaction, ResponseTransaction{
 		Transaction: transaction,
 	})
 }
 
 func ResponseOK(w http.ResponseWriter, r *http.Request) {
-	render.JSON(w, r, response.Response{
+	response.Render(w, r, nil, response.Response{
 		Status: response.StatusSuccess,
 	})
 }
 
-type ResponseFrozenBalance struct {
-	response.Response
-	dto.RegistrationCommand
-}
-
 func ResponseFrozenBalanceOK(w http.ResponseWriter, r *http.Request, dto *dto.RegistrationCommand) {
-	render.JSON(w, r, ResponseFrozenBalance{
+	frozen := FrozenBalance{
+		AccountID: dto.ID,
+		Currency:  dto.Currency,
+		Amount:    dto.Balance,
+	}
+
+	response.Render(w, r, frozen, ResponseFrozenBalance{
 		Response: response.Response{
 			Status: response.StatusSuccess,
 		},
@@ -0,0 +1,24 @@
+package request
+
+import (
+	"net/http/httptest"
+	"task/internal/domain/transaction/entity"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseTransactionOK_Legacy(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "/transactions/deposit", nil)
+
+	ResponseTransactionOK(w, r, entity.Transaction{
+		ID:        7,
+		Status:    "created",
+		AccountID: 1,
+		Amount:    100,
+		Currency:  "USD",
+	})
+
+	require.JSONEq(t, `{"id":7,"status":"created","account_id":1,"amount":100,"currency":"USD"}`, w.Body.String())
+}
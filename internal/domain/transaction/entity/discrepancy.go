@@ -0,0 +1,17 @@
+package entity
+
+const (
+	DiscrepancyNegativeBalance = "negative_balance"
+	DiscrepancyUnknownAccount  = "unknown_account"
+	DiscrepancyMissingEvent    = "missing_event"
+	DiscrepancyBalanceMismatch = "balance_mismatch"
+)
+
+// Discrepancy is a disagreement between accounts, transactions and the
+// events recorded about them, found by reconciliation.
+type Discrepancy struct {
+	Kind          string `json:"kind"`
+	AccountID     uint64 `json:"account_id"`
+	TransactionID uint64 `json:"transaction_id,omitempty"`
+	Detail        string `json:"detail"`
+}
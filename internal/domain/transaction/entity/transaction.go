@@ -1,5 +1,10 @@
 package entity
 
+const (
+	KindDeposit  = "deposit"
+	KindWithdraw = "withdraw"
+)
+
 type Transaction struct {
 	ID        uint64  `json:"id"`
 	Status    string  `json:"status,omitempty"`
@@ -8,3 +13,12 @@ type Transaction struct {
 	Currency  string  `json:"currency,omitempty"`
 	ToAccount uint64  `json:"to_account,omitempty"`
 }
+
+// Kind tells deposits, which have no receiving account, from withdrawals.
+func (t *Transaction) Kind() string {
+	if t.ToAccount == 0 {
+		return KindDeposit
+	}
+
+	return KindWithdraw
+}
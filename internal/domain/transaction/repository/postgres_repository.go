@@ -7,6 +7,7 @@ import (
 	"github.com/georgysavva/scany/v2/pgxscan"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"task/common"
 	"task/internal/api/response"
 	"task/internal/domain/Errors"
 	"task/internal/domain/transaction/entity"
@@ -40,7 +41,8 @@ func (r *PostgresRepository) CreateDepositTransaction(ctx context.Context, trans
 			@amount,
 			@currency,
 			@to_account
-		)`
+		)
+		ON CONFLICT (id) DO NOTHING`
 
 	args := pgx.NamedArgs{
 		"id":         transaction.ID,
@@ -51,15 +53,7 @@ func (r *PostgresRepository) CreateDepositTransaction(ctx context.Context, trans
 		"to_account": 0,
 	}
 
-	if _, err := r.GetTransactionByID(ctx, transaction.ID); err == nil {
-		return fmt.Errorf("%s: %w", op, Errors.ErrTransactionExists)
-	}
-
-	if _, err := r.db.Exec(ctx, query, args); err != nil {
-		return fmt.Errorf("%s: %w", op, err)
-	}
-
-	return nil
+	return r.insert(ctx, op, query, args)
 }
 
 func (r *PostgresRepository) CreateWithdrawTransaction(ctx context.Context, transaction *entity.Transaction) error {
@@ -81,7 +75,8 @@ func (r *PostgresRepository) CreateWithdrawTransaction(ctx context.Context, tran
 			@amount,
 			@currency,
 			@to_account
-		)`
+		)
+		ON CONFLICT (id) DO NOTHING`
 
 	args := pgx.NamedArgs{
 		"id":         transaction.ID,
@@ -92,10 +87,22 @@ func (r *PostgresRepository) CreateWithdrawTransaction(ctx context.Context, tran
 		"to_account": transaction.ToAccount,
 	}
 
-	if _, err := r.db.Exec(ctx, query, args); err != nil {
+	return r.insert(ctx, op, query, args)
+}
+
+// insert runs an insert that skips an existing id, and reports that as
+// ErrTransactionExists. Unlike looking the id up first, it holds when two
+// requests create the same transaction at once.
+func (r *PostgresRepository) insert(ctx context.Context, op string, query string, args pgx.NamedArgs) error {
+	tag, err := common.Conn(ctx, r.db).Exec(ctx, query, args)
+	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, Errors.ErrTransactionExists)
+	}
+
 	return nil
 }
 
@@ -113,7 +120,7 @@ func (r *PostgresRepository) GetTransactionByID(ctx context.Context, id uint64)
 
 	var transaction entity.Transaction
 
-	if err := pgxscan.Get(ctx, r.db, &transaction, query, args); err != nil {
+	if err := pgxscan.Get(ctx, common.Conn(ctx, r.db), &transaction, query, args); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, Errors.ErrTransactionNotFound
 		}
@@ -123,29 +130,31 @@ func (r *PostgresRepository) GetTransactionByID(ctx context.Context, id uint64)
 
 }
 
-func (r *PostgresRepository) UpdateTransactionStatus(ctx context.Context, id uint64, status string) error {
-	const op = "PostgresRepository.UpdateWithSuccess"
+// UpdateTransactionStatus moves the transaction from status from to status
+// to. It fails with ErrTransactionNotFound unless the transaction is in from,
+// so that concurrent updates cannot both apply.
+func (r *PostgresRepository) UpdateTransactionStatus(ctx context.Context, id uint64, from string, to string) error {
+	const op = "PostgresRepository.UpdateTransactionStatus"
 
 	query := `
 		UPDATE transaction
-		SET status = @status
-		WHERE id = @id
+		SET status = @to
+		WHERE id = @id AND status = @from
 	`
 
 	args := pgx.NamedArgs{
-		"id":     id,
-		"status": status,
+		"id":   id,
+		"from": from,
+		"to":   to,
 	}
 
-	if _, err := r.GetTransactionByID(ctx, id); err != nil {
-		if errors.Is(err, Errors.ErrTransactionNotFound) {
-			return fmt.Errorf("%s: %w", op, Errors.ErrTransactionNotFound)
-		}
+	tag, err := common.Conn(ctx, r.db).Exec(ctx, query, args)
+	if err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
-	if _, err := r.db.Exec(ctx, query, args); err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, Errors.ErrTransactionNotFound)
 	}
 
 	return nil
@@ -167,7 +176,7 @@ func (r *PostgresRepository) DeleteTransactionByID(ctx context.Context, id uint6
 		return fmt.Errorf("%s: %w", op, Errors.ErrTransactionNotFound)
 	}
 
-	if _, err := r.db.Exec(ctx, query, args); err != nil {
+	if _, err := common.Conn(ctx, r.db).Exec(ctx, query, args); err != nil {
 		return fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -188,7 +197,7 @@ func (r *PostgresRepository) GetTransactionsByAccountID(ctx context.Context, acc
 
 	var transactions []*entity.Transaction
 
-	if err := pgxscan.Select(ctx, r.db, &transactions, query, args); err != nil {
+	if err := pgxscan.Select(ctx, common.Conn(ctx, r.db), &transactions, query, args); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, fmt.Errorf("%s: %w", op, Errors.ErrTransactionNotFound)
 		}
@@ -197,3 +206,97 @@ func (r *PostgresRepository) GetTransactionsByAccountID(ctx context.Context, acc
 
 	return transactions, nil
 }
+
+// ListTransactions lists the transactions by id; an empty status and a zero
+// account match any.
+func (r *PostgresRepository) ListTransactions(ctx context.Context, status string, accountID uint64) ([]*entity.Transaction, error) {
+	const op = "PostgresRepository.ListTransactions"
+
+	query := `
+		SELECT id, status, account_id, amount, currency, to_account FROM transaction
+		WHERE (@status = '' OR status = @status)
+			AND (@account_id = 0 OR account_id = @account_id)
+		ORDER BY id
+	`
+
+	args := pgx.NamedArgs{
+		"status":     status,
+		"account_id": accountID,
+	}
+
+	var transactions []*entity.Transaction
+
+	if err := pgxscan.Select(ctx, common.Conn(ctx, r.db), &transactions, query, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return transactions, nil
+}
+
+// Reconcile looks for negative balances, transactions of unknown accounts,
+// finished transactions without the event announcing it and balances that
+// differ from the one the latest balance event left. Transactions and
+// balances from before the reconciliation baseline are taken as they are.
+// The kinds are those of entity.Discrepancy.
+func (r *PostgresRepository) Reconcile(ctx context.Context) ([]*entity.Discrepancy, error) {
+	const op = "PostgresRepository.Reconcile"
+
+	query := `
+		WITH ledger AS (
+			SELECT DISTINCT ON (o.account_id) o.account_id,
+				CASE WHEN o.aggregate_type = 'transaction' THEN o.aggregate_id ELSE 0 END AS transaction_id,
+				(o.payload->>'balance')::float8 AS balance
+			FROM outbox o
+			LEFT JOIN reconciliation_baseline b ON b.account_id = o.account_id
+			WHERE o.id > COALESCE(b.outbox_id, 0)
+				AND o.event_type IN ('transaction.settled', 'transaction.reversed', 'account.balance_set')
+			ORDER BY o.account_id, o.id DESC
+		), expected AS (
+			SELECT account_id, transaction_id, balance FROM ledger
+			UNION ALL
+			SELECT b.account_id, 0, b.balance FROM reconciliation_baseline b
+			WHERE NOT EXISTS (SELECT 1 FROM ledger l WHERE l.account_id = b.account_id)
+		)
+		SELECT 'negative_balance' AS kind, a.id AS account_id, 0 AS transaction_id,
+			'balance is ' || a.balance AS detail
+		FROM account a
+		WHERE a.balance < 0
+		UNION ALL
+		SELECT 'unknown_account', COALESCE(t.account_id, 0), t.id,
+			'account ' || COALESCE(t.account_id::text, 'null') || ' does not exist'
+		FROM transaction t
+		LEFT JOIN account a ON a.id = t.account_id
+		WHERE a.id IS NULL
+		UNION ALL
+		SELECT 'missing_event', COALESCE(t.account_id, 0), t.id,
+			'status is ' || t.status || ' but there is no ' || e.event_type || ' event'
+		FROM transaction t
+		JOIN (VALUES
+			('success', 'transaction.settled'),
+			('error', 'transaction.failed'),
+			('reversed', 'transaction.reversed')
+		) AS e (status, event_type) ON e.status = t.status
+		WHERE t.created_at IS NOT NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM outbox o
+				WHERE o.aggregate_type = 'transaction'
+					AND o.aggregate_id = t.id
+					AND o.event_type = e.event_type
+			)
+		UNION ALL
+		SELECT 'balance_mismatch', a.id, e.transaction_id,
+			'balance is ' || a.balance || ' but the events leave ' || e.balance
+		FROM account a
+		JOIN expected e ON e.account_id = a.id
+		WHERE a.balance <> e.balance
+		ORDER BY kind, account_id, transaction_id
+	`
+
+	var discrepancies []*entity.Discrepancy
+
+	if err := pgxscan.Select(ctx, common.Conn(ctx, r.db), &discrepancies, query); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return discrepancies, nil
+}
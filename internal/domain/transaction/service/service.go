@@ -9,8 +9,12 @@ import (
 	"task/internal/domain/Errors"
 	"task/internal/domain/account_dto/dto"
 	rep "task/internal/domain/account_dto/repository"
+	outbox "task/internal/domain/outbox/entity"
+	outboxRep "task/internal/domain/outbox/repository"
 	"task/internal/domain/transaction/entity"
 	"task/internal/domain/transaction/repository"
+	"task/internal/metrics"
+	"time"
 )
 
 //go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Repository_transaction
@@ -18,71 +22,118 @@ type Repository_transaction interface {
 	CreateDepositTransaction(ctx context.Context, transaction *entity.Transaction) error
 	CreateWithdrawTransaction(ctx context.Context, transaction *entity.Transaction) error
 	GetTransactionByID(ctx context.Context, id uint64) (*entity.Transaction, error)
-	UpdateTransactionStatus(ctx context.Context, id uint64, status string) error
+	UpdateTransactionStatus(ctx context.Context, id uint64, from string, to string) error
 	DeleteTransactionByID(ctx context.Context, id uint64) error
 	GetTransactionsByAccountID(ctx context.Context, accountID uint64) ([]*entity.Transaction, error)
+	ListTransactions(ctx context.Context, status string, accountID uint64) ([]*entity.Transaction, error)
+	Reconcile(ctx context.Context) ([]*entity.Discrepancy, error)
 }
 
 //go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Repository_acc_dto
 type Repository_acc_dto interface {
 	UpdateBalance(ctx context.Context, account_id uint64, balance float64) error
 	CheckExistsAccount(ctx context.Context, account_id uint64) (*dto.RegistrationCommand, error)
+	GetAccountForUpdate(ctx context.Context, account_id uint64) (*dto.RegistrationCommand, error)
 }
 
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Repository_outbox
+type Repository_outbox interface {
+	Save(ctx context.Context, event *outbox.Event) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Transactor
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=StepUp
+type StepUp interface {
+	CheckStepUp(ctx context.Context, amount float64, currency string) error
+}
+
+// maxAmount bounds the amount of a single transaction.
+const maxAmount = 1_000_000_000
+
 type Service struct {
 	repTransaction Repository_transaction
 	repAccDto      Repository_acc_dto
+	repOutbox      Repository_outbox
+	transactor     Transactor
+	stepUp         StepUp
 }
 
-func NewService(di *common.DependencyContainer) *Service {
+func NewService(di *common.DependencyContainer, stepUp StepUp) *Service {
 	return &Service{
 		repTransaction: repository.NewPostgresRepository(di.Pool),
 		repAccDto:      rep.NewPostgresRepository(di.Pool),
+		repOutbox:      outboxRep.NewPostgresRepository(di.Pool),
+		transactor:     common.NewTransactor(di.Pool),
+		stepUp:         stepUp,
 	}
 }
 
 func (s *Service) CreateDepositTransaction(ctx context.Context, transaction *entity.Transaction) (*entity.Transaction, error) {
 	const op = "domain/transaction.Service.CreateDepositTransaction"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
 
-	_, err := s.repTransaction.GetTransactionByID(ctx, transaction.ID)
-
-	switch err {
-	case nil:
-		return nil, fmt.Errorf("%s: %w", op, Errors.ErrTransactionExists)
+	if err := check(transaction); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
-	case Errors.ErrTransactionNotFound:
-		err = s.repTransaction.CreateDepositTransaction(ctx, transaction)
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repTransaction.CreateDepositTransaction(ctx, transaction); err != nil {
+			return err
 		}
-		return transaction, nil
-	default:
+		transaction.Status = response.StatusCreated
+		return s.saveEvent(ctx, outbox.EventTransactionCreated, transaction, nil, "")
+	})
+	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+
+	metrics.TransactionCreated(transaction.Kind(), transaction.Currency)
+
+	return transaction, nil
 }
 
 func (s *Service) CreateWithdrawTransaction(ctx context.Context, transaction *entity.Transaction) (*entity.Transaction, error) {
 	const op = "domain/transaction.Service.CreateWithdrawTransaction"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
 
-	_, err := s.repTransaction.GetTransactionByID(ctx, transaction.ID)
+	if err := check(transaction); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if transaction.ToAccount == 0 {
+		return nil, fmt.Errorf("%s: %w", op, Errors.ErrIncorrectID)
+	}
 
-	switch err {
-	case nil:
-		return nil, fmt.Errorf("%s: %w", op, Errors.ErrAccountExists)
+	// Large payouts need a fresh one-time code of the caller.
+	if err := s.stepUp.CheckStepUp(ctx, transaction.Amount, transaction.Currency); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
-	case Errors.ErrTransactionNotFound:
-		err = s.repTransaction.CreateWithdrawTransaction(ctx, transaction)
-		if err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repTransaction.CreateWithdrawTransaction(ctx, transaction); err != nil {
+			return err
 		}
-		return transaction, nil
-	default:
+		transaction.Status = response.StatusCreated
+		return s.saveEvent(ctx, outbox.EventTransactionCreated, transaction, nil, "")
+	})
+	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
+
+	metrics.TransactionCreated(transaction.Kind(), transaction.Currency)
+
+	return transaction, nil
 }
 
 func (s *Service) GetTransactionByID(ctx context.Context, id uint64) (*entity.Transaction, error) {
 	const op = "domain/transaction.Service.GetTransactionByID"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
 
 	transaction, err := s.repTransaction.GetTransactionByID(ctx, id)
 	if errors.Is(err, Errors.ErrTransactionNotFound) {
@@ -96,6 +147,8 @@ func (s *Service) GetTransactionByID(ctx context.Context, id uint64) (*entity.Tr
 
 func (s *Service) GetFrozenBalanceByAccountID(ctx context.Context, accountID uint64) (*dto.RegistrationCommand, error) {
 	const op = "domain/transaction.Service.GetTransactionsByAccountID"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
 
 	transactions, err := s.repTransaction.GetTransactionsByAccountID(ctx, accountID)
 	if errors.Is(err, Errors.ErrTransactionNotFound) {
@@ -135,63 +188,186 @@ func (s *Service) GetFrozenBalanceByAccountID(ctx context.Context, accountID uin
 	return accountDto, nil
 }
 
+// UpdateTransactionStatus settles a pending transaction. The account row is
+// locked for the duration, so that settlements and reversals on it apply one
+// after the other, and the status only moves from created, so that a
+// transaction is never settled twice.
 func (s *Service) UpdateTransactionStatus(ctx context.Context, id uint64) error {
 	const op = "domain/transaction.Service.UpdateTransactionStatus"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+	start := time.Now()
+
+	var (
+		transaction *entity.Transaction
+		settleErr   error
+	)
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		transaction, err = s.repTransaction.GetTransactionByID(ctx, id)
+		if err != nil {
+			return err
+		}
 
-	transaction, err := s.repTransaction.GetTransactionByID(ctx, id)
-	if errors.Is(err, Errors.ErrTransactionNotFound) {
-		return fmt.Errorf("%s: %w", op, Errors.ErrTransactionNotFound)
-	}
-	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
-	}
+		if transaction.Status != response.StatusCreated {
+			return Errors.ErrNotPending
+		}
 
-	accountDto, err := s.repAccDto.CheckExistsAccount(ctx, transaction.AccountID)
-	if errors.Is(err, Errors.ErrAccountNotFound) {
-		return fmt.Errorf("%s: %w", op, Errors.ErrAccountNotFound)
-	}
-	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
-	}
+		accountDto, err := s.repAccDto.GetAccountForUpdate(ctx, transaction.AccountID)
+		if err != nil {
+			return err
+		}
 
-	amount, err := common.ValidateCurrency(transaction.Currency, accountDto.Currency, transaction.Amount)
+		amount, err := common.ValidateCurrency(transaction.Currency, accountDto.Currency, transaction.Amount)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case transaction.ToAccount == 0:
+			return s.settle(ctx, transaction, accountDto.Balance+amount)
+		case amount > accountDto.Balance:
+			settleErr = Errors.ErrNegativeBalance
+			return s.fail(ctx, transaction, settleErr)
+		default:
+			return s.settle(ctx, transaction, accountDto.Balance-amount)
+		}
+	})
 	if err != nil {
+		metrics.ObserveSettlement(metrics.ResultError, time.Since(start))
 		return fmt.Errorf("%s: %w", op, err)
 	}
+	if settleErr != nil {
+		metrics.ObserveSettlement(metrics.ResultFailed, time.Since(start))
+		metrics.TransactionFailed(transaction.Kind(), transaction.Currency)
+		return fmt.Errorf("%s: %w", op, settleErr)
+	}
 
-	switch {
-	case transaction.ToAccount == 0:
-		if err = s.repAccDto.UpdateBalance(ctx, transaction.AccountID, accountDto.Balance+amount); err != nil {
-			return fmt.Errorf("%s: %w", op, err)
+	metrics.ObserveSettlement(metrics.ResultSettled, time.Since(start))
+	metrics.TransactionSettled(transaction.Kind(), transaction.Currency, transaction.Amount)
+
+	return nil
+}
+
+// ReverseTransaction undoes the effect of a settled transaction on the
+// balance of its account and marks it reversed, under the same lock and
+// status rules as a settlement.
+func (s *Service) ReverseTransaction(ctx context.Context, id uint64) (*entity.Transaction, error) {
+	const op = "domain/transaction.Service.ReverseTransaction"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	var transaction *entity.Transaction
+
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		transaction, err = s.repTransaction.GetTransactionByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if transaction.Status != response.StatusSuccess {
+			return Errors.ErrNotSettled
 		}
-		if err = s.repTransaction.UpdateTransactionStatus(ctx, transaction.ID, response.StatusSuccess); err != nil {
-			return fmt.Errorf("%s: %w", op, err)
+
+		accountDto, err := s.repAccDto.GetAccountForUpdate(ctx, transaction.AccountID)
+		if err != nil {
+			return err
 		}
-	case transaction.ToAccount > 0:
-		if amount > accountDto.Balance {
-			if err = s.repTransaction.UpdateTransactionStatus(ctx, transaction.ID, response.StatusError); err != nil {
-				return fmt.Errorf("%s: %w", op, err)
-			}
-			return fmt.Errorf("%s: %w", op, Errors.ErrNegativeBalance)
+
+		amount, err := common.ValidateCurrency(transaction.Currency, accountDto.Currency, transaction.Amount)
+		if err != nil {
+			return err
+		}
+
+		balance := accountDto.Balance + amount
+		if transaction.ToAccount == 0 {
+			balance = accountDto.Balance - amount
 		}
-		if err = s.repAccDto.UpdateBalance(ctx, transaction.AccountID, accountDto.Balance-amount); err != nil {
-			return fmt.Errorf("%s: %w", op, err)
+		if balance < 0 {
+			return Errors.ErrNegativeBalance
 		}
-		if err = s.repTransaction.UpdateTransactionStatus(ctx, transaction.ID, response.StatusSuccess); err != nil {
-			return fmt.Errorf("%s: %w", op, err)
+
+		if err := s.transition(ctx, transaction, response.StatusSuccess, response.StatusReversed, Errors.ErrNotSettled); err != nil {
+			return err
 		}
-	default:
-		if err = s.repTransaction.UpdateTransactionStatus(ctx, transaction.ID, response.StatusError); err != nil {
-			return fmt.Errorf("%s: %w", op, err)
+		if err := s.repAccDto.UpdateBalance(ctx, transaction.AccountID, balance); err != nil {
+			return err
 		}
-		return fmt.Errorf("%s: %w", op, Errors.ErrIncorrectID)
+
+		return s.saveEvent(ctx, outbox.EventTransactionReversed, transaction, &balance, "")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return transaction, nil
+}
+
+// check rejects what no transport may create, whatever it validates
+// itself. A negative amount would turn a withdrawal into a credit.
+func check(transaction *entity.Transaction) error {
+	switch {
+	case transaction.ID == 0 || transaction.AccountID == 0:
+		return Errors.ErrIncorrectID
+	case !(transaction.Amount > 0) || transaction.Amount > maxAmount:
+		return Errors.ErrInvalidAmount
+	case !common.SupportedCurrency(transaction.Currency):
+		return Errors.ErrInvalidCurrency
 	}
 
 	return nil
 }
 
+func (s *Service) settle(ctx context.Context, transaction *entity.Transaction, balance float64) error {
+	if err := s.transition(ctx, transaction, response.StatusCreated, response.StatusSuccess, Errors.ErrNotPending); err != nil {
+		return err
+	}
+	if err := s.repAccDto.UpdateBalance(ctx, transaction.AccountID, balance); err != nil {
+		return err
+	}
+
+	return s.saveEvent(ctx, outbox.EventTransactionSettled, transaction, &balance, "")
+}
+
+func (s *Service) fail(ctx context.Context, transaction *entity.Transaction, reason error) error {
+	if err := s.transition(ctx, transaction, response.StatusCreated, response.StatusError, Errors.ErrNotPending); err != nil {
+		return err
+	}
+
+	return s.saveEvent(ctx, outbox.EventTransactionFailed, transaction, nil, reason.Error())
+}
+
+// transition moves the transaction from one status to another, failing with
+// stale when another update got there first.
+func (s *Service) transition(ctx context.Context, transaction *entity.Transaction, from string, to string, stale error) error {
+	err := s.repTransaction.UpdateTransactionStatus(ctx, transaction.ID, from, to)
+	if errors.Is(err, Errors.ErrTransactionNotFound) {
+		return stale
+	}
+	if err != nil {
+		return err
+	}
+
+	transaction.Status = to
+
+	return nil
+}
+
+func (s *Service) saveEvent(ctx context.Context, eventType string, transaction *entity.Transaction, balance *float64, reason string) error {
+	event, err := outbox.NewTransactionEvent(eventType, transaction, balance, reason)
+	if err != nil {
+		return err
+	}
+
+	return s.repOutbox.Save(ctx, event)
+}
+
 func (s *Service) DeleteTransactionByID(ctx context.Context, id uint64) error {
 	const op = "domain/transaction.Service.DeleteTransactionByID"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
 
 	err := s.repTransaction.DeleteTransactionByID(ctx, id)
 	if errors.Is(err, Errors.ErrTransactionNotFound) {
@@ -203,3 +379,31 @@ func (s *Service) DeleteTransactionByID(ctx context.Context, id uint64) error {
 
 	return nil
 }
+
+// ListTransactions lists the transactions by id; an empty status and a zero
+// account match any.
+func (s *Service) ListTransactions(ctx context.Context, status string, accountID uint64) ([]*entity.Transaction, error) {
+	const op = "domain/transaction.Service.ListTransactions"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	transactions, err := s.repTransaction.ListTransactions(ctx, status, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return transactions, nil
+}
+
+func (s *Service) Reconcile(ctx context.Context) ([]*entity.Discrepancy, error) {
+	const op = "domain/transaction.Service.Reconcile"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	discrepancies, err := s.repTransaction.Reconcile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return discrepancies, nil
+}
@@ -2,10 +2,18 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"task/internal/api/response"
+	"task/internal/domain/Errors"
+	"task/internal/domain/account_dto/dto"
+	outbox "task/internal/domain/outbox/entity"
 	"task/internal/domain/transaction/entity"
 	"task/internal/domain/transaction/service/mocks"
 	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 //type transaction struct {
@@ -94,3 +102,294 @@ func TestService_CreateDepositTransaction(t *testing.T) {
 	}
 
 }
+
+func TestService_CreateTransaction(t *testing.T) {
+	cases := []struct {
+		name        string
+		transaction entity.Transaction
+		exists      bool
+	}{
+		{
+			name:        "Deposit",
+			transaction: entity.Transaction{ID: 1, AccountID: 1, Amount: 30, Currency: "USD"},
+		},
+		{
+			name:        "Existing deposit",
+			transaction: entity.Transaction{ID: 2, AccountID: 1, Amount: 30, Currency: "USD"},
+			exists:      true,
+		},
+		{
+			name:        "Withdrawal",
+			transaction: entity.Transaction{ID: 3, AccountID: 1, Amount: 30, Currency: "USD", ToAccount: 2},
+		},
+		{
+			name:        "Existing withdrawal",
+			transaction: entity.Transaction{ID: 4, AccountID: 1, Amount: 30, Currency: "USD", ToAccount: 2},
+			exists:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			repTr := mocks.NewRepository_transaction(t)
+			repOutbox := mocks.NewRepository_outbox(t)
+			stepUp := mocks.NewStepUp(t)
+
+			transaction := tc.transaction
+
+			var createErr error
+			if tc.exists {
+				createErr = Errors.ErrTransactionExists
+			} else {
+				repOutbox.On("Save", ctx, mock.AnythingOfType("*entity.Event")).Run(func(args mock.Arguments) {
+					require.Equal(t, outbox.EventTransactionCreated, args.Get(1).(*outbox.Event).Type)
+				}).Return(nil)
+			}
+
+			s := &Service{
+				repTransaction: repTr,
+				repOutbox:      repOutbox,
+				transactor:     inlineTransactor{},
+				stepUp:         stepUp,
+			}
+
+			var err error
+			if transaction.Kind() == entity.KindDeposit {
+				repTr.On("CreateDepositTransaction", ctx, &transaction).Return(createErr)
+				_, err = s.CreateDepositTransaction(ctx, &transaction)
+			} else {
+				stepUp.On("CheckStepUp", ctx, transaction.Amount, transaction.Currency).Return(nil)
+				repTr.On("CreateWithdrawTransaction", ctx, &transaction).Return(createErr)
+				_, err = s.CreateWithdrawTransaction(ctx, &transaction)
+			}
+
+			if tc.exists {
+				require.ErrorIs(t, err, Errors.ErrTransactionExists)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, response.StatusCreated, transaction.Status)
+		})
+	}
+}
+
+type inlineTransactor struct{}
+
+func (inlineTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestService_UpdateTransactionStatus(t *testing.T) {
+	cases := []struct {
+		name        string
+		transaction entity.Transaction
+		balance     float64
+		stale       bool
+		wantStatus  string
+		wantEvent   string
+		wantBalance float64
+		wantErr     error
+	}{
+		{
+			name:        "Deposit",
+			transaction: entity.Transaction{ID: 1, Status: response.StatusCreated, AccountID: 1, Amount: 30, Currency: "USD"},
+			balance:     100,
+			wantStatus:  response.StatusSuccess,
+			wantEvent:   outbox.EventTransactionSettled,
+			wantBalance: 130,
+		},
+		{
+			name:        "Withdrawal",
+			transaction: entity.Transaction{ID: 2, Status: response.StatusCreated, AccountID: 1, Amount: 30, Currency: "USD", ToAccount: 2},
+			balance:     100,
+			wantStatus:  response.StatusSuccess,
+			wantEvent:   outbox.EventTransactionSettled,
+			wantBalance: 70,
+		},
+		{
+			name:        "Withdrawal over the balance",
+			transaction: entity.Transaction{ID: 3, Status: response.StatusCreated, AccountID: 1, Amount: 300, Currency: "USD", ToAccount: 2},
+			balance:     100,
+			wantStatus:  response.StatusError,
+			wantEvent:   outbox.EventTransactionFailed,
+			wantErr:     Errors.ErrNegativeBalance,
+		},
+		{
+			name:        "Settled concurrently",
+			transaction: entity.Transaction{ID: 4, Status: response.StatusCreated, AccountID: 1, Amount: 30, Currency: "USD"},
+			balance:     100,
+			stale:       true,
+			wantStatus:  response.StatusSuccess,
+			wantErr:     Errors.ErrNotPending,
+		},
+		{
+			name:        "Already settled",
+			transaction: entity.Transaction{ID: 5, Status: response.StatusSuccess, AccountID: 1, Amount: 30, Currency: "USD"},
+			wantErr:     Errors.ErrNotPending,
+		},
+		{
+			name:        "Reversed",
+			transaction: entity.Transaction{ID: 6, Status: response.StatusReversed, AccountID: 1, Amount: 30, Currency: "USD"},
+			wantErr:     Errors.ErrNotPending,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			repTr := mocks.NewRepository_transaction(t)
+			repAcc := mocks.NewRepository_acc_dto(t)
+			repOutbox := mocks.NewRepository_outbox(t)
+
+			transaction := tc.transaction
+			repTr.On("GetTransactionByID", ctx, transaction.ID).Return(&transaction, nil)
+
+			if tc.wantStatus != "" {
+				repAcc.On("GetAccountForUpdate", ctx, transaction.AccountID).
+					Return(&dto.RegistrationCommand{ID: transaction.AccountID, Currency: "USD", Balance: tc.balance}, nil)
+
+				var updateErr error
+				if tc.stale {
+					updateErr = Errors.ErrTransactionNotFound
+				}
+				repTr.On("UpdateTransactionStatus", ctx, transaction.ID, response.StatusCreated, tc.wantStatus).Return(updateErr)
+			}
+			if tc.wantEvent == outbox.EventTransactionSettled {
+				repAcc.On("UpdateBalance", ctx, transaction.AccountID, tc.wantBalance).Return(nil)
+			}
+			if tc.wantEvent != "" {
+				repOutbox.On("Save", ctx, mock.AnythingOfType("*entity.Event")).Run(func(args mock.Arguments) {
+					require.Equal(t, tc.wantEvent, args.Get(1).(*outbox.Event).Type)
+				}).Return(nil)
+			}
+
+			s := &Service{
+				repTransaction: repTr,
+				repAccDto:      repAcc,
+				repOutbox:      repOutbox,
+				transactor:     inlineTransactor{},
+			}
+
+			err := s.UpdateTransactionStatus(ctx, transaction.ID)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.wantStatus, transaction.Status)
+		})
+	}
+}
+
+func TestService_ReverseTransaction(t *testing.T) {
+	cases := []struct {
+		name        string
+		transaction entity.Transaction
+		balance     float64
+		stale       bool
+		wantBalance float64
+		wantErr     error
+	}{
+		{
+			name:        "Deposit",
+			transaction: entity.Transaction{ID: 1, Status: response.StatusSuccess, AccountID: 1, Amount: 30, Currency: "USD"},
+			balance:     100,
+			wantBalance: 70,
+		},
+		{
+			name:        "Withdrawal",
+			transaction: entity.Transaction{ID: 2, Status: response.StatusSuccess, AccountID: 1, Amount: 30, Currency: "USD", ToAccount: 2},
+			balance:     100,
+			wantBalance: 130,
+		},
+		{
+			name:        "Deposit already spent",
+			transaction: entity.Transaction{ID: 3, Status: response.StatusSuccess, AccountID: 1, Amount: 30, Currency: "USD"},
+			balance:     10,
+			wantErr:     Errors.ErrNegativeBalance,
+		},
+		{
+			name:        "Reversed concurrently",
+			transaction: entity.Transaction{ID: 4, Status: response.StatusSuccess, AccountID: 1, Amount: 30, Currency: "USD"},
+			balance:     100,
+			stale:       true,
+			wantErr:     Errors.ErrNotSettled,
+		},
+		{
+			name:        "Pending",
+			transaction: entity.Transaction{ID: 5, Status: response.StatusCreated, AccountID: 1, Amount: 30, Currency: "USD"},
+			wantErr:     Errors.ErrNotSettled,
+		},
+		{
+			name:        "Already reversed",
+			transaction: entity.Transaction{ID: 6, Status: response.StatusReversed, AccountID: 1, Amount: 30, Currency: "USD"},
+			wantErr:     Errors.ErrNotSettled,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			repTr := mocks.NewRepository_transaction(t)
+			repAcc := mocks.NewRepository_acc_dto(t)
+			repOutbox := mocks.NewRepository_outbox(t)
+
+			transaction := tc.transaction
+			repTr.On("GetTransactionByID", ctx, transaction.ID).Return(&transaction, nil)
+
+			if tc.transaction.Status == response.StatusSuccess {
+				repAcc.On("GetAccountForUpdate", ctx, transaction.AccountID).
+					Return(&dto.RegistrationCommand{ID: transaction.AccountID, Currency: "USD", Balance: tc.balance}, nil)
+			}
+
+			if tc.stale {
+				repTr.On("UpdateTransactionStatus", ctx, transaction.ID, response.StatusSuccess, response.StatusReversed).
+					Return(Errors.ErrTransactionNotFound)
+			}
+
+			if tc.wantErr == nil {
+				repTr.On("UpdateTransactionStatus", ctx, transaction.ID, response.StatusSuccess, response.StatusReversed).Return(nil)
+				repAcc.On("UpdateBalance", ctx, transaction.AccountID, tc.wantBalance).Return(nil)
+				repOutbox.On("Save", ctx, mock.AnythingOfType("*entity.Event")).Run(func(args mock.Arguments) {
+					event := args.Get(1).(*outbox.Event)
+					require.Equal(t, outbox.EventTransactionReversed, event.Type)
+
+					var payload outbox.TransactionPayload
+					require.NoError(t, json.Unmarshal(event.Payload, &payload))
+					require.Equal(t, tc.wantBalance, *payload.Balance)
+				}).Return(nil)
+			}
+
+			s := &Service{
+				repTransaction: repTr,
+				repAccDto:      repAcc,
+				repOutbox:      repOutbox,
+				transactor:     inlineTransactor{},
+			}
+
+			reversed, err := s.ReverseTransaction(ctx, transaction.ID)
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, response.StatusReversed, reversed.Status)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"task/internal/api/validate"
+	"task/internal/domain/Errors"
+	"task/internal/domain/webhook/controller/request"
+	"task/internal/domain/webhook/entity"
+	"task/internal/domain/webhook/service"
+)
+
+type Handlers struct {
+	service *service.Service
+}
+
+func NewHandlers(service *service.Service) *Handlers {
+	return &Handlers{
+		service: service,
+	}
+}
+
+func (h *Handlers) Register(w http.ResponseWriter, r *http.Request) error {
+	const op = "webhook.Handlers.Register"
+	ctx := r.Context()
+
+	var req request.Request
+
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	subscription, err := h.service.CreateSubscription(ctx, &entity.Subscription{
+		AccountID:  req.AccountID,
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseRegisterOK(w, r, subscription)
+
+	return nil
+}
+
+func (h *Handlers) Get(w http.ResponseWriter, r *http.Request) error {
+	const op = "webhook.Handlers.Get"
+	ctx := r.Context()
+
+	id, err := GetIDFromRequest(r, "webhook_id")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	subscription, err := h.service.GetSubscription(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseWebhookOK(w, r, subscription)
+
+	return nil
+}
+
+func (h *Handlers) ListByAccount(w http.ResponseWriter, r *http.Request) error {
+	const op = "webhook.Handlers.ListByAccount"
+	ctx := r.Context()
+
+	id, err := GetIDFromRequest(r, "account_id")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	subscriptions, err := h.service.ListSubscriptions(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseWebhooksOK(w, r, subscriptions)
+
+	return nil
+}
+
+func (h *Handlers) Delete(w http.ResponseWriter, r *http.Request) error {
+	const op = "webhook.Handlers.Delete"
+	ctx := r.Context()
+
+	id, err := GetIDFromRequest(r, "webhook_id")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := h.service.DeleteSubscription(ctx, id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseOK(w, r)
+
+	return nil
+}
+
+func (h *Handlers) ListDeliveries(w http.ResponseWriter, r *http.Request) error {
+	const op = "webhook.Handlers.ListDeliveries"
+	ctx := r.Context()
+
+	id, err := GetIDFromRequest(r, "webhook_id")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	deliveries, err := h.service.ListDeliveries(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseDeliveriesOK(w, r, deliveries)
+
+	return nil
+}
+
+func (h *Handlers) ListAttempts(w http.ResponseWriter, r *http.Request) error {
+	const op = "webhook.Handlers.ListAttempts"
+	ctx := r.Context()
+
+	webhookID, err := GetIDFromRequest(r, "webhook_id")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	deliveryID, err := GetIDFromRequest(r, "delivery_id")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	attempts, err := h.service.ListAttempts(ctx, webhookID, deliveryID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseAttemptsOK(w, r, attempts)
+
+	return nil
+}
+
+func (h *Handlers) Redeliver(w http.ResponseWriter, r *http.Request) error {
+	const op = "webhook.Handlers.Redeliver"
+	ctx := r.Context()
+
+	webhookID, err := GetIDFromRequest(r, "webhook_id")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	deliveryID, err := GetIDFromRequest(r, "delivery_id")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	delivery, err := h.service.Redeliver(ctx, webhookID, deliveryID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	request.ResponseDeliveryOK(w, r, delivery)
+
+	return nil
+}
+
+func GetIDFromRequest(r *http.Request, key string) (uint64, error) {
+	param := chi.URLParam(r, key)
+	if param == "" {
+		return 0, fmt.Errorf("%w: empty parameter %s", Errors.ErrIncorrectID, key)
+	}
+
+	value, err := strconv.ParseUint(param, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid parameter %s", Errors.ErrIncorrectID, key)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,113 @@
+package request
+
+import (
+	"net/http"
+	"task/internal/api/response"
+	"task/internal/domain/webhook/entity"
+)
+
+type Request struct {
+	AccountID  uint64   `json:"account_id" validate:"required"`
+	URL        string   `json:"url" validate:"required,url,max=2048"`
+	EventTypes []string `json:"event_types" validate:"dive,required"`
+}
+
+type ResponseWebhook struct {
+	response.Response
+	Webhook *entity.Subscription `json:"webhook"`
+	Secret  string               `json:"secret,omitempty"`
+}
+
+type ResponseWebhooks struct {
+	response.Response
+	Webhooks []*entity.Subscription `json:"webhooks"`
+}
+
+type ResponseDelivery struct {
+	response.Response
+	Delivery *entity.Delivery `json:"delivery"`
+}
+
+type ResponseDeliveries struct {
+	response.Response
+	Deliveries []*entity.Delivery `json:"deliveries"`
+}
+
+type ResponseAttempts struct {
+	response.Response
+	Attempts []*entity.Attempt `json:"attempts"`
+}
+
+// CreatedWebhook is a subscription together with its signing secret, as
+// returned once on registration.
+type CreatedWebhook struct {
+	*entity.Subscription
+	Secret string `json:"secret"`
+}
+
+// ResponseRegisterOK is the only response that carries the signing secret.
+func ResponseRegisterOK(w http.ResponseWriter, r *http.Request, subscription *entity.Subscription) {
+	created := CreatedWebhook{
+		Subscription: subscription,
+		Secret:       subscription.Secret,
+	}
+
+	response.Render(w, r, created, ResponseWebhook{
+		Response: response.Response{
+			Status: response.StatusSuccess,
+		},
+		Webhook: subscription,
+		Secret:  subscription.Secret,
+	})
+}
+
+func ResponseWebhookOK(w http.ResponseWriter, r *http.Request, subscription *entity.Subscription) {
+	response.Render(w, r, subscription, ResponseWebhook{
+		Response: response.Response{
+			Status: response.StatusSuccess,
+		},
+		Webhook: subscription,
+	})
+}
+
+func ResponseWebhooksOK(w http.ResponseWriter, r *http.Request, subscriptions []*entity.Subscription) {
+	response.Render(w, r, subscriptions, ResponseWebhooks{
+		Response: response.Response{
+			Status: response.StatusSuccess,
+		},
+		Webhooks: subscriptions,
+	})
+}
+
+func ResponseDeliveryOK(w http.ResponseWriter, r *http.Request, delivery *entity.Delivery) {
+	response.Render(w, r, delivery, ResponseDelivery{
+		Response: response.Response{
+			Status: response.StatusSuccess,
+		},
+		Delivery: delivery,
+	})
+}
+
+func ResponseDeliveriesOK(w http.ResponseWriter, r *http.Request, deliveries []*entity.Delivery) {
+	response.Render(w, r, deliveries, ResponseDeliveries{
+		Response: response.Response{
+			Status: response.StatusSuccess,
+		},
+		Deliveries: deliveries,
+	})
+}
+
+func ResponseAttemptsOK(w http.ResponseWriter, r *http.Request, attempts []*entity.Attempt) {
+	response.Render(w, r, attempts, ResponseAttempts{
+		Response: response.Response{
+			Status: response.StatusSuccess,
+		},
+		Attempts: attempts,
+	})
+}
+
+func ResponseOK(w http.ResponseWriter, r *http.Request) {
+	response.Render(w, r, nil, response.Response{
+		Status: response.StatusSuccess,
+	})
+}
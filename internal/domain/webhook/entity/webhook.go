@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+type Subscription struct {
+	ID         uint64    `json:"id"`
+	AccountID  uint64    `json:"account_id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"-"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type Delivery struct {
+	ID             uint64          `json:"id"`
+	SubscriptionID uint64          `json:"subscription_id"`
+	EventID        uint64          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+type Attempt struct {
+	ID         uint64    `json:"id"`
+	DeliveryID uint64    `json:"delivery_id"`
+	StatusCode *int      `json:"status_code,omitempty"`
+	Error      *string   `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms" db:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Body is the JSON document POSTed to subscribers.
+type Body struct {
+	ID        uint64          `json:"id"`
+	Type      string          `json:"type"`
+	AccountID uint64          `json:"account_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"task/common"
+	"task/internal/domain/Errors"
+	"task/internal/domain/webhook/entity"
+	"time"
+)
+
+type PostgresRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
+	return &PostgresRepository{
+		db: pool,
+	}
+}
+
+func (r *PostgresRepository) SaveSubscription(ctx context.Context, subscription *entity.Subscription) error {
+	const op = "domain/webhook.PostgresRepository.SaveSubscription"
+
+	query := `
+		INSERT INTO webhook_subscription (account_id, url, secret, event_types, active)
+		VALUES (@account_id, @url, @secret, @event_types, @active)
+		RETURNING id, created_at
+	`
+
+	args := pgx.NamedArgs{
+		"account_id":  subscription.AccountID,
+		"url":         subscription.URL,
+		"secret":      subscription.Secret,
+		"event_types": subscription.EventTypes,
+		"active":      subscription.Active,
+	}
+
+	if err := common.Conn(ctx, r.db).QueryRow(ctx, query, args).Scan(&subscription.ID, &subscription.CreatedAt); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) GetSubscription(ctx context.Context, id uint64) (*entity.Subscription, error) {
+	const op = "domain/webhook.PostgresRepository.GetSubscription"
+
+	query := `
+		SELECT id, account_id, url, secret, event_types, active, created_at FROM webhook_subscription
+		WHERE id = @id
+	`
+
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	var subscription entity.Subscription
+
+	if err := pgxscan.Get(ctx, common.Conn(ctx, r.db), &subscription, query, args); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, Errors.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &subscription, nil
+}
+
+func (r *PostgresRepository) ListSubscriptions(ctx context.Context, accountID uint64) ([]*entity.Subscription, error) {
+	const op = "domain/webhook.PostgresRepository.ListSubscriptions"
+
+	query := `
+		SELECT id, account_id, url, secret, event_types, active, created_at FROM webhook_subscription
+		WHERE account_id = @account_id
+		ORDER BY id
+	`
+
+	args := pgx.NamedArgs{
+		"account_id": accountID,
+	}
+
+	var subscriptions []*entity.Subscription
+
+	if err := pgxscan.Select(ctx, common.Conn(ctx, r.db), &subscriptions, query, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *PostgresRepository) FindSubscriptionsForEvent(ctx context.Context, accountID uint64, eventType string) ([]*entity.Subscription, error) {
+	const op = "domain/webhook.PostgresRepository.FindSubscriptionsForEvent"
+
+	query := `
+		SELECT id, account_id, url, secret, event_types, active, created_at FROM webhook_subscription
+		WHERE account_id = @account_id
+			AND active
+			AND (cardinality(event_types) = 0 OR @event_type = ANY(event_types))
+		ORDER BY id
+	`
+
+	args := pgx.NamedArgs{
+		"account_id": accountID,
+		"event_type": eventType,
+	}
+
+	var subscriptions []*entity.Subscription
+
+	if err := pgxscan.Select(ctx, common.Conn(ctx, r.db), &subscriptions, query, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *PostgresRepository) DeleteSubscription(ctx context.Context, id uint64) error {
+	const op = "domain/webhook.PostgresRepository.DeleteSubscription"
+
+	query := `
+		DELETE FROM webhook_subscription
+		WHERE id = @id
+	`
+
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	tag, err := common.Conn(ctx, r.db).Exec(ctx, query, args)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return Errors.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// CreateDelivery is idempotent per subscription and event, so an event
+// relayed twice is still delivered once.
+func (r *PostgresRepository) CreateDelivery(ctx context.Context, delivery *entity.Delivery) error {
+	const op = "domain/webhook.PostgresRepository.CreateDelivery"
+
+	query := `
+		INSERT INTO webhook_delivery (subscription_id, event_id, event_type, payload, status, next_attempt_at)
+		VALUES (@subscription_id, @event_id, @event_type, @payload, @status, now())
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`
+
+	args := pgx.NamedArgs{
+		"subscription_id": delivery.SubscriptionID,
+		"event_id":        delivery.EventID,
+		"event_type":      delivery.EventType,
+		"payload":         delivery.Payload,
+		"status":          entity.DeliveryPending,
+	}
+
+	if _, err := common.Conn(ctx, r.db).Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ClaimDueDeliveries leases due deliveries for lease, so that other
+// dispatcher instances skip them while they are being sent.
+func (r *PostgresRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*entity.Delivery, error) {
+	const op = "domain/webhook.PostgresRepository.ClaimDueDeliveries"
+
+	query := `
+		UPDATE webhook_delivery
+		SET next_attempt_at = now() + @lease::interval
+		WHERE id IN (
+			SELECT id FROM webhook_delivery
+			WHERE status = @status AND next_attempt_at <= now()
+			ORDER BY next_attempt_at, id
+			LIMIT @limit
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, subscription_id, event_id, event_type, payload, status, attempts,
+			next_attempt_at, last_status_code, last_error, created_at, delivered_at
+	`
+
+	args := pgx.NamedArgs{
+		"status": entity.DeliveryPending,
+		"limit":  limit,
+		"lease":  lease,
+	}
+
+	var deliveries []*entity.Delivery
+
+	if err := pgxscan.Select(ctx, common.Conn(ctx, r.db), &deliveries, query, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *PostgresRepository) GetDelivery(ctx context.Context, id uint64) (*entity.Delivery, error) {
+	const op = "domain/webhook.PostgresRepository.GetDelivery"
+
+	query := `
+		SELECT id, subscription_id, event_id, event_type, payload, status, attempts,
+			next_attempt_at, last_status_code, last_error, created_at, delivered_at
+		FROM webhook_delivery
+		WHERE id = @id
+	`
+
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	var delivery entity.Delivery
+
+	if err := pgxscan.Get(ctx, common.Conn(ctx, r.db), &delivery, query, args); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, Errors.ErrDeliveryNotFound
+		}
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &delivery, nil
+}
+
+func (r *PostgresRepository) ListDeliveries(ctx context.Context, subscriptionID uint64, limit int) ([]*entity.Delivery, error) {
+	const op = "domain/webhook.PostgresRepository.ListDeliveries"
+
+	query := `
+		SELECT id, subscription_id, event_id, event_type, payload, status, attempts,
+			next_attempt_at, last_status_code, last_error, created_at, delivered_at
+		FROM webhook_delivery
+		WHERE subscription_id = @subscription_id
+		ORDER BY id DESC
+		LIMIT @limit
+	`
+
+	args := pgx.NamedArgs{
+		"subscription_id": subscriptionID,
+		"limit":           limit,
+	}
+
+	var deliveries []*entity.Delivery
+
+	if err := pgxscan.Select(ctx, common.Conn(ctx, r.db), &deliveries, query, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *PostgresRepository) UpdateDelivery(ctx context.Context, delivery *entity.Delivery) error {
+	const op = "domain/webhook.PostgresRepository.UpdateDelivery"
+
+	query := `
+		UPDATE webhook_delivery
+		SET status = @status,
+			attempts = @attempts,
+			next_attempt_at = @next_attempt_at,
+			last_status_code = @last_status_code,
+			last_error = @last_error,
+			delivered_at = @delivered_at
+		WHERE id = @id
+	`
+
+	args := pgx.NamedArgs{
+		"id":               delivery.ID,
+		"status":           delivery.Status,
+		"attempts":         delivery.Attempts,
+		"next_attempt_at":  delivery.NextAttemptAt,
+		"last_status_code": delivery.LastStatusCode,
+		"last_error":       delivery.LastError,
+		"delivered_at":     delivery.DeliveredAt,
+	}
+
+	if _, err := common.Conn(ctx, r.db).Exec(ctx, query, args); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) SaveAttempt(ctx context.Context, attempt *entity.Attempt) error {
+	const op = "domain/webhook.PostgresRepository.SaveAttempt"
+
+	query := `
+		INSERT INTO webhook_delivery_attempt (delivery_id, status_code, error, duration_ms)
+		VALUES (@delivery_id, @status_code, @error, @duration_ms)
+		RETURNING id, created_at
+	`
+
+	args := pgx.NamedArgs{
+		"delivery_id": attempt.DeliveryID,
+		"status_code": attempt.StatusCode,
+		"error":       attempt.Error,
+		"duration_ms": attempt.DurationMS,
+	}
+
+	if err := common.Conn(ctx, r.db).QueryRow(ctx, query, args).Scan(&attempt.ID, &attempt.CreatedAt); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) ListAttempts(ctx context.Context, deliveryID uint64) ([]*entity.Attempt, error) {
+	const op = "domain/webhook.PostgresRepository.ListAttempts"
+
+	query := `
+		SELECT id, delivery_id, status_code, error, duration_ms, created_at FROM webhook_delivery_attempt
+		WHERE delivery_id = @delivery_id
+		ORDER BY id
+	`
+
+	args := pgx.NamedArgs{
+		"delivery_id": deliveryID,
+	}
+
+	var attempts []*entity.Attempt
+
+	if err := pgxscan.Select(ctx, common.Conn(ctx, r.db), &attempts, query, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golang.org/x/exp/slog"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"task/common"
+	outbox "task/internal/domain/outbox/entity"
+	"task/internal/domain/webhook/entity"
+	"task/internal/domain/webhook/repository"
+	"time"
+)
+
+var errPrivateTarget = errors.New("webhook target is not a public address")
+
+const (
+	HeaderID        = "Webhook-Id"
+	HeaderEvent     = "Webhook-Event"
+	HeaderTimestamp = "Webhook-Timestamp"
+	HeaderSignature = "Webhook-Signature"
+)
+
+// Dispatcher turns outbox events into webhook deliveries and sends them.
+// It is registered as an outbox publisher, so deliveries are created when
+// the relay publishes an event; publishing it again creates none twice.
+type Dispatcher struct {
+	repository  Repository
+	client      *http.Client
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	now         func() time.Time
+}
+
+func NewDispatcher(di *common.DependencyContainer) *Dispatcher {
+	cfg := di.Config.Webhook
+
+	return &Dispatcher{
+		repository:  repository.NewPostgresRepository(di.Pool),
+		client:      newClient(cfg.Timeout, cfg.AllowPrivateTargets),
+		interval:    cfg.PollInterval,
+		batchSize:   cfg.BatchSize,
+		maxAttempts: cfg.MaxAttempts,
+		backoffBase: cfg.BackoffBase,
+		backoffMax:  cfg.BackoffMax,
+		now:         time.Now,
+	}
+}
+
+func (d *Dispatcher) Publish(ctx context.Context, event *outbox.Event) error {
+	const op = "domain/webhook.Dispatcher.Publish"
+
+	subscriptions, err := d.repository.FindSubscriptionsForEvent(ctx, event.AccountID, event.Type)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(entity.Body{
+		ID:        event.ID,
+		Type:      event.Type,
+		AccountID: event.AccountID,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Payload,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, subscription := range subscriptions {
+		delivery := &entity.Delivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        body,
+		}
+		if err := d.repository.CreateDelivery(ctx, delivery); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) Close() error {
+	return nil
+}
+
+// Run sends due deliveries until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	const op = "domain/webhook.Dispatcher.Run"
+
+	logger := common.FromContext(ctx)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.ProcessBatch(ctx); err != nil {
+			logger.Error("failed to dispatch webhooks", slog.String("op", op), slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch makes an attempt at every due delivery and returns how many
+// were recorded. A delivery that cannot be recorded is logged and left to
+// be claimed again once its lease runs out.
+func (d *Dispatcher) ProcessBatch(ctx context.Context) (int, error) {
+	const op = "domain/webhook.Dispatcher.ProcessBatch"
+
+	deliveries, err := d.repository.ClaimDueDeliveries(ctx, d.batchSize, 2*d.client.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	logger := common.FromContext(ctx)
+
+	var processed int
+	for _, delivery := range deliveries {
+		if err := d.Deliver(ctx, delivery); err != nil {
+			logger.Error("failed to deliver webhook",
+				slog.String("op", op),
+				slog.Uint64("delivery_id", delivery.ID),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		processed++
+	}
+
+	return processed, nil
+}
+
+// Deliver makes one attempt to send delivery, records it in the delivery log
+// and schedules the next attempt with exponential backoff on failure.
+func (d *Dispatcher) Deliver(ctx context.Context, delivery *entity.Delivery) error {
+	const op = "domain/webhook.Dispatcher.Deliver"
+
+	subscription, err := d.repository.GetSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	start := d.now()
+	statusCode, sendErr := d.send(ctx, subscription, delivery)
+
+	attempt := &entity.Attempt{
+		DeliveryID: delivery.ID,
+		DurationMS: d.now().Sub(start).Milliseconds(),
+	}
+	if statusCode != 0 {
+		attempt.StatusCode = &statusCode
+	}
+	if sendErr != nil {
+		msg := sendErr.Error()
+		attempt.Error = &msg
+	}
+
+	if err := d.repository.SaveAttempt(ctx, attempt); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	delivery.Attempts++
+	delivery.LastStatusCode = attempt.StatusCode
+	delivery.LastError = attempt.Error
+
+	switch {
+	case sendErr == nil:
+		deliveredAt := d.now()
+		delivery.Status = entity.DeliverySucceeded
+		delivery.DeliveredAt = &deliveredAt
+	case delivery.Attempts >= d.maxAttempts:
+		delivery.Status = entity.DeliveryFailed
+	default:
+		delivery.NextAttemptAt = d.now().Add(d.backoff(delivery.Attempts))
+	}
+
+	if err := d.repository.UpdateDelivery(ctx, delivery); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) send(ctx context.Context, subscription *entity.Subscription, delivery *entity.Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := d.now().Unix()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderID, strconv.FormatUint(delivery.ID, 10))
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// newClient returns the client webhooks are sent with. Unless private
+// targets are allowed, it refuses to connect to an address that is not
+// public, whatever the host resolved to and wherever a redirect leads.
+func newClient(timeout time.Duration, allowPrivateTargets bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if !allowPrivateTargets {
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control:   dialPublic,
+		}
+		// A proxy would make the connection on our behalf, unchecked.
+		transport.Proxy = nil
+		transport.DialContext = dialer.DialContext
+	}
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+func dialPublic(_ string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !publicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", errPrivateTarget, addrPort.Addr())
+	}
+
+	return nil
+}
+
+// publicAddress reports whether addr is neither loopback, private nor
+// link-local, nor otherwise unfit to send a webhook to.
+func publicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsGlobalUnicast() && !addr.IsPrivate()
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.backoffBase
+	for i := 1; i < attempts && delay < d.backoffMax; i++ {
+		delay *= 2
+	}
+	if delay > d.backoffMax {
+		delay = d.backoffMax
+	}
+
+	return delay
+}
+
+// Sign returns the Webhook-Signature header value: "t=<unix>,v1=<hex>",
+// where v1 is HMAC-SHA256 of "<unix>.<body>" keyed with the subscription
+// secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"task/internal/domain/webhook/entity"
+	"task/internal/domain/webhook/service/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcher_Deliver(t *testing.T) {
+	now := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name         string
+		statusCode   int
+		attempts     int
+		wantStatus   string
+		wantAttempts int
+		wantNext     time.Time
+	}{
+		{
+			name:         "Success",
+			statusCode:   http.StatusNoContent,
+			wantStatus:   entity.DeliverySucceeded,
+			wantAttempts: 1,
+		},
+		{
+			name:         "Retry with backoff",
+			statusCode:   http.StatusInternalServerError,
+			attempts:     2,
+			wantStatus:   entity.DeliveryPending,
+			wantAttempts: 3,
+			wantNext:     now.Add(4 * time.Second),
+		},
+		{
+			name:         "Give up after max attempts",
+			statusCode:   http.StatusBadGateway,
+			attempts:     4,
+			wantStatus:   entity.DeliveryFailed,
+			wantAttempts: 5,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			payload := []byte(`{"id":7,"type":"transaction.settled"}`)
+
+			stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+
+				require.Equal(t, payload, body)
+				require.Equal(t, "transaction.settled", r.Header.Get(HeaderEvent))
+				require.Equal(t, Sign("whsec_test", timestamp, body), r.Header.Get(HeaderSignature))
+
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer stub.Close()
+
+			ctx := context.Background()
+			rep := mocks.NewRepository(t)
+
+			rep.On("GetSubscription", ctx, uint64(1)).Return(&entity.Subscription{
+				ID:     1,
+				URL:    stub.URL,
+				Secret: "whsec_test",
+			}, nil)
+			rep.On("SaveAttempt", ctx, mock.MatchedBy(func(a *entity.Attempt) bool {
+				return a.DeliveryID == 10 && *a.StatusCode == tc.statusCode
+			})).Return(nil)
+			rep.On("UpdateDelivery", ctx, mock.MatchedBy(func(d *entity.Delivery) bool {
+				return d.Status == tc.wantStatus &&
+					d.Attempts == tc.wantAttempts &&
+					(tc.wantNext.IsZero() || d.NextAttemptAt.Equal(tc.wantNext))
+			})).Return(nil)
+
+			dispatcher := &Dispatcher{
+				repository:  rep,
+				client:      stub.Client(),
+				maxAttempts: 5,
+				backoffBase: time.Second,
+				backoffMax:  time.Minute,
+				now:         func() time.Time { return now },
+			}
+
+			err := dispatcher.Deliver(ctx, &entity.Delivery{
+				ID:             10,
+				SubscriptionID: 1,
+				EventType:      "transaction.settled",
+				Payload:        payload,
+				Status:         entity.DeliveryPending,
+				Attempts:       tc.attempts,
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestDispatcher_ProcessBatch_ContinuesPastFailures(t *testing.T) {
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer stub.Close()
+
+	ctx := context.Background()
+	rep := mocks.NewRepository(t)
+
+	rep.On("ClaimDueDeliveries", ctx, 10, 2*time.Second).Return([]*entity.Delivery{
+		{ID: 10, SubscriptionID: 1},
+		{ID: 11, SubscriptionID: 2},
+	}, nil)
+	rep.On("GetSubscription", ctx, uint64(1)).Return(nil, errors.New("connection reset"))
+	rep.On("GetSubscription", ctx, uint64(2)).Return(&entity.Subscription{ID: 2, URL: stub.URL}, nil)
+	rep.On("SaveAttempt", ctx, mock.AnythingOfType("*entity.Attempt")).Return(nil)
+	rep.On("UpdateDelivery", ctx, mock.MatchedBy(func(d *entity.Delivery) bool {
+		return d.ID == 11 && d.Status == entity.DeliverySucceeded
+	})).Return(nil)
+
+	client := stub.Client()
+	client.Timeout = time.Second
+
+	dispatcher := &Dispatcher{
+		repository:  rep,
+		client:      client,
+		batchSize:   10,
+		maxAttempts: 5,
+		now:         time.Now,
+	}
+
+	processed, err := dispatcher.ProcessBatch(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, processed)
+}
+
+func TestNewClient_RefusesPrivateTargets(t *testing.T) {
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer stub.Close()
+
+	// A host that passed the check when the subscription was created may
+	// resolve to a private address by the time the webhook is sent.
+	_, err := newClient(time.Second, false).Post(stub.URL, "application/json", nil)
+	require.ErrorIs(t, err, errPrivateTarget)
+
+	resp, err := newClient(time.Second, true).Post(stub.URL, "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"task/common"
+	"task/internal/domain/Errors"
+	"task/internal/domain/webhook/entity"
+	"task/internal/domain/webhook/repository"
+	"time"
+)
+
+//go:generate go run github.com/vektra/mockery/v2@v2.32.4 --name=Repository
+type Repository interface {
+	SaveSubscription(ctx context.Context, subscription *entity.Subscription) error
+	GetSubscription(ctx context.Context, id uint64) (*entity.Subscription, error)
+	ListSubscriptions(ctx context.Context, accountID uint64) ([]*entity.Subscription, error)
+	FindSubscriptionsForEvent(ctx context.Context, accountID uint64, eventType string) ([]*entity.Subscription, error)
+	DeleteSubscription(ctx context.Context, id uint64) error
+	CreateDelivery(ctx context.Context, delivery *entity.Delivery) error
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*entity.Delivery, error)
+	GetDelivery(ctx context.Context, id uint64) (*entity.Delivery, error)
+	ListDeliveries(ctx context.Context, subscriptionID uint64, limit int) ([]*entity.Delivery, error)
+	UpdateDelivery(ctx context.Context, delivery *entity.Delivery) error
+	SaveAttempt(ctx context.Context, attempt *entity.Attempt) error
+	ListAttempts(ctx context.Context, deliveryID uint64) ([]*entity.Attempt, error)
+}
+
+const deliveriesPageSize = 100
+
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network string, host string) ([]netip.Addr, error)
+}
+
+type Service struct {
+	repository          Repository
+	resolver            Resolver
+	allowPrivateTargets bool
+}
+
+func NewService(di *common.DependencyContainer) *Service {
+	return &Service{
+		repository:          repository.NewPostgresRepository(di.Pool),
+		resolver:            net.DefaultResolver,
+		allowPrivateTargets: di.Config.Webhook.AllowPrivateTargets,
+	}
+}
+
+func (s *Service) CreateSubscription(ctx context.Context, subscription *entity.Subscription) (*entity.Subscription, error) {
+	const op = "domain/webhook.Service.CreateSubscription"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	if err := s.checkTarget(ctx, subscription.URL); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	subscription.Secret = secret
+	subscription.Active = true
+	if subscription.EventTypes == nil {
+		subscription.EventTypes = []string{}
+	}
+
+	if err := s.repository.SaveSubscription(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return subscription, nil
+}
+
+// checkTarget rejects a URL whose host resolves to an address that is not
+// public. The dispatcher checks again when it connects, since the host may
+// resolve elsewhere by then.
+func (s *Service) checkTarget(ctx context.Context, rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return Errors.ErrInvalidWebhookURL
+	}
+
+	if s.allowPrivateTargets {
+		return nil
+	}
+
+	addrs, err := s.resolver.LookupNetIP(ctx, "ip", target.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%w: cannot resolve %s", Errors.ErrInvalidWebhookURL, target.Hostname())
+	}
+
+	for _, addr := range addrs {
+		if !publicAddress(addr) {
+			return fmt.Errorf("%w: %s is not a public address", Errors.ErrInvalidWebhookURL, addr)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) GetSubscription(ctx context.Context, id uint64) (*entity.Subscription, error) {
+	const op = "domain/webhook.Service.GetSubscription"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	subscription, err := s.repository.GetSubscription(ctx, id)
+	if errors.Is(err, Errors.ErrWebhookNotFound) {
+		return nil, fmt.Errorf("%s: %w", op, Errors.ErrWebhookNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return subscription, nil
+}
+
+func (s *Service) ListSubscriptions(ctx context.Context, accountID uint64) ([]*entity.Subscription, error) {
+	const op = "domain/webhook.Service.ListSubscriptions"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	subscriptions, err := s.repository.ListSubscriptions(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return subscriptions, nil
+}
+
+func (s *Service) DeleteSubscription(ctx context.Context, id uint64) error {
+	const op = "domain/webhook.Service.DeleteSubscription"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	err := s.repository.DeleteSubscription(ctx, id)
+	if errors.Is(err, Errors.ErrWebhookNotFound) {
+		return fmt.Errorf("%s: %w", op, Errors.ErrWebhookNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Service) ListDeliveries(ctx context.Context, subscriptionID uint64) ([]*entity.Delivery, error) {
+	const op = "domain/webhook.Service.ListDeliveries"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	if _, err := s.GetSubscription(ctx, subscriptionID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	deliveries, err := s.repository.ListDeliveries(ctx, subscriptionID, deliveriesPageSize)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deliveries, nil
+}
+
+func (s *Service) ListAttempts(ctx context.Context, subscriptionID uint64, deliveryID uint64) ([]*entity.Attempt, error) {
+	const op = "domain/webhook.Service.ListAttempts"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	if _, err := s.getDelivery(ctx, subscriptionID, deliveryID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	attempts, err := s.repository.ListAttempts(ctx, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return attempts, nil
+}
+
+// Redeliver schedules a delivery to be sent again right away with a fresh
+// retry budget, whatever its current status.
+func (s *Service) Redeliver(ctx context.Context, subscriptionID uint64, deliveryID uint64) (*entity.Delivery, error) {
+	const op = "domain/webhook.Service.Redeliver"
+	ctx, span := common.StartSpan(ctx, op)
+	defer span.End()
+
+	delivery, err := s.getDelivery(ctx, subscriptionID, deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	delivery.Status = entity.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+
+	if err := s.repository.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return delivery, nil
+}
+
+func (s *Service) getDelivery(ctx context.Context, subscriptionID uint64, deliveryID uint64) (*entity.Delivery, error) {
+	delivery, err := s.repository.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.SubscriptionID != subscriptionID {
+		return nil, Errors.ErrDeliveryNotFound
+	}
+
+	return delivery, nil
+}
+
+func newSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
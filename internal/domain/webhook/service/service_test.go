@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"net/netip"
+	"task/internal/domain/Errors"
+	"task/internal/domain/webhook/entity"
+	"task/internal/domain/webhook/service/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// hosts resolves names from a table, and IP literals to themselves as the
+// net resolver does.
+type hosts map[string][]netip.Addr
+
+func (h hosts) LookupNetIP(_ context.Context, _ string, host string) ([]netip.Addr, error) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr}, nil
+	}
+	addrs, ok := h[host]
+	if !ok {
+		return nil, Errors.ErrInvalidWebhookURL
+	}
+
+	return addrs, nil
+}
+
+func TestService_CreateSubscription(t *testing.T) {
+	resolver := hosts{
+		"hooks.example.com":    {netip.MustParseAddr("93.184.216.34")},
+		"localhost":            {netip.MustParseAddr("127.0.0.1"), netip.MustParseAddr("::1")},
+		"internal.example.com": {netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("10.0.0.7")},
+	}
+
+	cases := []struct {
+		name    string
+		url     string
+		allow   bool
+		wantErr error
+	}{
+		{name: "Public host", url: "https://hooks.example.com/events"},
+		{name: "Public address", url: "http://93.184.216.34:8080/events"},
+		{name: "Not http", url: "ftp://hooks.example.com/events", wantErr: Errors.ErrInvalidWebhookURL},
+		{name: "Unresolvable host", url: "https://nowhere.example.com/events", wantErr: Errors.ErrInvalidWebhookURL},
+		{name: "Loopback host", url: "http://localhost:8080/events", wantErr: Errors.ErrInvalidWebhookURL},
+		{name: "Loopback address", url: "http://127.0.0.1/events", wantErr: Errors.ErrInvalidWebhookURL},
+		{name: "IPv6 loopback", url: "http://[::1]/events", wantErr: Errors.ErrInvalidWebhookURL},
+		{name: "Mapped loopback", url: "http://[::ffff:127.0.0.1]/events", wantErr: Errors.ErrInvalidWebhookURL},
+		{name: "Private address", url: "http://192.168.1.10/events", wantErr: Errors.ErrInvalidWebhookURL},
+		{name: "Host with a private address", url: "https://internal.example.com/events", wantErr: Errors.ErrInvalidWebhookURL},
+		{name: "Link-local address", url: "http://169.254.169.254/latest/meta-data", wantErr: Errors.ErrInvalidWebhookURL},
+		{name: "Unspecified address", url: "http://0.0.0.0/events", wantErr: Errors.ErrInvalidWebhookURL},
+		{name: "Private targets allowed", url: "http://localhost:8080/events", allow: true},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			rep := mocks.NewRepository(t)
+
+			if tc.wantErr == nil {
+				rep.On("SaveSubscription", ctx, mock.AnythingOfType("*entity.Subscription")).Return(nil)
+			}
+
+			s := &Service{repository: rep, resolver: resolver, allowPrivateTargets: tc.allow}
+
+			subscription, err := s.CreateSubscription(ctx, &entity.Subscription{AccountID: 1, URL: tc.url})
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NotEmpty(t, subscription.Secret)
+		})
+	}
+}
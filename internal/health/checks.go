@@ -0,0 +1,20 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Ping checks that the pool can reach the database.
+func Ping(pool *pgxpool.Pool) Check {
+	return func(ctx context.Context) error {
+		const op = "health.Ping"
+
+		if err := pool.Ping(ctx); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		return nil
+	}
+}
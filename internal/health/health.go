@@ -0,0 +1,118 @@
+package health
+
+import (
+	"context"
+	"github.com/go-chi/render"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusFailing  = "failing"
+	StatusDraining = "draining"
+)
+
+// Check reports whether a dependency of the process is usable.
+type Check func(ctx context.Context) error
+
+type Result struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Health answers the liveness and readiness probes of the orchestrator.
+// Readiness runs every registered check and fails as soon as Drain is
+// called, so that traffic moves away before the servers shut down.
+type Health struct {
+	timeout  time.Duration
+	checks   []namedCheck
+	draining atomic.Bool
+}
+
+func New(timeout time.Duration) *Health {
+	return &Health{timeout: timeout}
+}
+
+// Register adds a readiness check. It is not safe to call once the probes
+// are served.
+func (h *Health) Register(name string, check Check) {
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+// Drain fails readiness from now on.
+func (h *Health) Drain() {
+	h.draining.Store(true)
+}
+
+// Ready runs the checks concurrently, each bounded by the timeout.
+func (h *Health) Ready(ctx context.Context) Report {
+	if h.draining.Load() {
+		return Report{Status: StatusDraining}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(h.checks))}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for _, c := range h.checks {
+		wg.Add(1)
+		go func(c namedCheck) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := c.check(ctx)
+
+			result := Result{Status: StatusOK, Duration: time.Since(start).String()}
+			if err != nil {
+				result.Status = StatusFailing
+				result.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[c.name] = result
+			if err != nil {
+				report.Status = StatusFailing
+			}
+		}(c)
+	}
+
+	wg.Wait()
+
+	return report
+}
+
+// Liveness only tells that the process serves requests.
+func (h *Health) Liveness(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, Report{Status: StatusOK})
+}
+
+func (h *Health) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := h.Ready(r.Context())
+	if report.Status != StatusOK {
+		render.Status(r, http.StatusServiceUnavailable)
+	}
+
+	render.JSON(w, r, report)
+}
@@ -0,0 +1,133 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth_Readiness(t *testing.T) {
+	t.Parallel()
+
+	ok := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name       string
+		checks     map[string]Check
+		drain      bool
+		wantStatus int
+		wantReport Report
+	}{
+		{
+			name:       "ready",
+			checks:     map[string]Check{"database": ok, "workers": ok},
+			wantStatus: http.StatusOK,
+			wantReport: Report{Status: StatusOK, Checks: map[string]Result{
+				"database": {Status: StatusOK},
+				"workers":  {Status: StatusOK},
+			}},
+		},
+		{
+			name:       "failing check",
+			checks:     map[string]Check{"database": down, "workers": ok},
+			wantStatus: http.StatusServiceUnavailable,
+			wantReport: Report{Status: StatusFailing, Checks: map[string]Result{
+				"database": {Status: StatusFailing, Error: "connection refused"},
+				"workers":  {Status: StatusOK},
+			}},
+		},
+		{
+			name:       "timed out check",
+			checks:     map[string]Check{"database": slow},
+			wantStatus: http.StatusServiceUnavailable,
+			wantReport: Report{Status: StatusFailing, Checks: map[string]Result{
+				"database": {Status: StatusFailing, Error: context.DeadlineExceeded.Error()},
+			}},
+		},
+		{
+			name:       "draining",
+			checks:     map[string]Check{"database": ok},
+			drain:      true,
+			wantStatus: http.StatusServiceUnavailable,
+			wantReport: Report{Status: StatusDraining},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := New(10 * time.Millisecond)
+			for name, check := range tc.checks {
+				h.Register(name, check)
+			}
+			if tc.drain {
+				h.Drain()
+			}
+
+			rec := httptest.NewRecorder()
+			h.Readiness(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			require.Equal(t, tc.wantStatus, rec.Code)
+
+			report := h.Ready(context.Background())
+			for name, result := range report.Checks {
+				require.NotEmpty(t, result.Duration)
+				result.Duration = ""
+				report.Checks[name] = result
+			}
+			require.Equal(t, tc.wantReport, report)
+		})
+	}
+}
+
+func TestHealth_Liveness(t *testing.T) {
+	t.Parallel()
+
+	h := New(time.Second)
+	h.Register("database", func(context.Context) error { return errors.New("connection refused") })
+	h.Drain()
+
+	rec := httptest.NewRecorder()
+	h.Liveness(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+}
+
+func TestWorkers_Check(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	workers := NewWorkers()
+
+	relay := workers.Track("relay", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	dispatcher := workers.Track("dispatcher", func(context.Context) error {
+		return errors.New("boom")
+	})
+
+	require.NoError(t, workers.Check(ctx))
+
+	require.EqualError(t, dispatcher(ctx), "boom")
+	require.EqualError(t, workers.Check(ctx), "health.Workers.Check: stopped dispatcher")
+
+	cancel()
+	require.NoError(t, relay(ctx))
+	require.EqualError(t, workers.Check(ctx), "health.Workers.Check: stopped dispatcher, relay")
+}
@@ -0,0 +1,61 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Workers keeps track of the background workers of the process, so that
+// readiness fails when one of them has stopped.
+type Workers struct {
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+func NewWorkers() *Workers {
+	return &Workers{running: make(map[string]bool)}
+}
+
+// Track wraps the run function of a worker, which counts as running from
+// now until run returns.
+func (w *Workers) Track(name string, run func(ctx context.Context) error) func(ctx context.Context) error {
+	w.set(name, true)
+
+	return func(ctx context.Context) error {
+		defer w.set(name, false)
+
+		return run(ctx)
+	}
+}
+
+func (w *Workers) set(name string, running bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.running[name] = running
+}
+
+// Check fails when any worker started by Go has returned.
+func (w *Workers) Check(_ context.Context) error {
+	const op = "health.Workers.Check"
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var stopped []string
+	for name, running := range w.running {
+		if !running {
+			stopped = append(stopped, name)
+		}
+	}
+
+	if len(stopped) > 0 {
+		sort.Strings(stopped)
+		return fmt.Errorf("%s: stopped %s", op, strings.Join(stopped, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+	"path"
+	"strings"
+)
+
+// Default is the language of requests that do not ask for a supported one,
+// and the fallback of messages missing from a catalog.
+const Default = "en"
+
+//go:embed locales/*.yaml
+var locales embed.FS
+
+// Args fills the {name} placeholders of a message.
+type Args map[string]any
+
+type contextKey struct{}
+
+// Catalog holds the messages of every language, keyed by message key,
+// e.g. "error.account_not_found".
+type Catalog struct {
+	languages []string
+	matcher   language.Matcher
+	messages  map[string]map[string]string
+}
+
+var catalog = mustLoad()
+
+// Load parses the embedded catalogs, one locales/<language>.yaml per
+// language.
+func Load() (*Catalog, error) {
+	const op = "i18n.Load"
+
+	files, err := locales.ReadDir("locales")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	c := &Catalog{messages: make(map[string]map[string]string, len(files))}
+
+	tags := []language.Tag{language.MustParse(Default)}
+	c.languages = []string{Default}
+
+	for _, file := range files {
+		lang := strings.TrimSuffix(file.Name(), path.Ext(file.Name()))
+
+		raw, err := locales.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		var messages map[string]string
+		if err := yaml.Unmarshal(raw, &messages); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", op, file.Name(), err)
+		}
+		c.messages[lang] = messages
+
+		if lang == Default {
+			continue
+		}
+
+		tag, err := language.Parse(lang)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", op, file.Name(), err)
+		}
+		tags = append(tags, tag)
+		c.languages = append(c.languages, lang)
+	}
+
+	if _, ok := c.messages[Default]; !ok {
+		return nil, fmt.Errorf("%s: catalog of %s is missing", op, Default)
+	}
+
+	c.matcher = language.NewMatcher(tags)
+
+	return c, nil
+}
+
+func mustLoad() *Catalog {
+	c, err := Load()
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// Negotiate picks the supported language that suits an Accept-Language
+// header best, e.g. "ru" for "ru-RU,ru;q=0.9,en;q=0.8".
+func (c *Catalog) Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+
+	_, index, confidence := c.matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+
+	return c.languages[index]
+}
+
+// Translate returns the message of key in lang. Messages missing from the
+// catalog of lang fall back to Default, then to the key itself.
+func (c *Catalog) Translate(lang string, key string, args Args) string {
+	message, ok := c.messages[lang][key]
+	if !ok {
+		message, ok = c.messages[Default][key]
+	}
+	if !ok {
+		return key
+	}
+
+	for name, value := range args {
+		message = strings.ReplaceAll(message, "{"+name+"}", fmt.Sprint(value))
+	}
+
+	return message
+}
+
+// Languages lists the supported languages, Default first.
+func (c *Catalog) Languages() []string {
+	return c.languages
+}
+
+// Keys lists the message keys of lang.
+func (c *Catalog) Keys(lang string) []string {
+	keys := make([]string, 0, len(c.messages[lang]))
+	for key := range c.messages[lang] {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func Negotiate(acceptLanguage string) string {
+	return catalog.Negotiate(acceptLanguage)
+}
+
+func Translate(lang string, key string, args Args) string {
+	return catalog.Translate(lang, key, args)
+}
+
+// T translates key into the language of the request behind ctx.
+func T(ctx context.Context, key string, args Args) string {
+	return catalog.Translate(FromContext(ctx), key, args)
+}
+
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// FromContext returns the language of the request behind ctx, or Default.
+func FromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(contextKey{}).(string); ok {
+		return lang
+	}
+
+	return Default
+}
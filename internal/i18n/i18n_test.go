@@ -0,0 +1,65 @@
+package i18n
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{name: "empty", header: "", want: "en"},
+		{name: "russian", header: "ru", want: "ru"},
+		{name: "russian region", header: "ru-RU,ru;q=0.9,en-US;q=0.8,en;q=0.7", want: "ru"},
+		{name: "english preferred", header: "en-GB,ru;q=0.5", want: "en"},
+		{name: "unsupported first", header: "de-DE,ru;q=0.8", want: "ru"},
+		{name: "unsupported only", header: "fr-CH, fr;q=0.9", want: "en"},
+		{name: "malformed", header: ";;;q=x", want: "en"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tc.want, Negotiate(tc.header))
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "account not found", Translate("en", "error.account_not_found", nil))
+	require.Equal(t, "счёт не найден", Translate("ru", "error.account_not_found", nil))
+	require.Equal(t, "account not found", Translate("de", "error.account_not_found", nil))
+	require.Equal(t, "error.unknown_key", Translate("ru", "error.unknown_key", nil))
+	require.Equal(t, "password must be 8 to 256 characters", Translate("en", "error.password_policy", Args{"min": 8, "max": 256}))
+
+	ctx := WithLanguage(context.Background(), "ru")
+	require.Equal(t, "ru", FromContext(ctx))
+	require.Equal(t, "обязательное поле", T(ctx, "validation.required", nil))
+	require.Equal(t, Default, FromContext(context.Background()))
+}
+
+func TestCatalogsAreComplete(t *testing.T) {
+	t.Parallel()
+
+	want := catalog.Keys(Default)
+	sort.Strings(want)
+	require.NotEmpty(t, want)
+
+	for _, lang := range catalog.Languages() {
+		got := catalog.Keys(lang)
+		sort.Strings(got)
+		require.Equal(t, want, got, "keys of %s", lang)
+	}
+}
@@ -0,0 +1,206 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/exp/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type component struct {
+	name string
+	run  func(ctx context.Context) error
+	// stop is nil for workers, which stop when the context of run is
+	// cancelled.
+	stop func(ctx context.Context) error
+}
+
+type closer struct {
+	name  string
+	close func(ctx context.Context) error
+}
+
+// Manager runs the servers and background workers of the application
+// until a signal arrives or one of them fails, then shuts everything down
+// in order: shutdown hooks, servers, workers and finally the resources,
+// last registered first.
+type Manager struct {
+	logger  *slog.Logger
+	timeout time.Duration
+	signals []os.Signal
+
+	components []component
+	hooks      []func(ctx context.Context)
+	closers    []closer
+}
+
+// New returns a manager that gives the servers and workers timeout to
+// drain once shutdown begins.
+func New(logger *slog.Logger, timeout time.Duration) *Manager {
+	return &Manager{
+		logger:  logger,
+		timeout: timeout,
+		signals: []os.Signal{os.Interrupt, syscall.SIGTERM},
+	}
+}
+
+// Serve registers a server. serve blocks until stop is called and must
+// return nil then.
+func (m *Manager) Serve(name string, serve func() error, stop func(ctx context.Context) error) {
+	m.components = append(m.components, component{
+		name: name,
+		run:  func(context.Context) error { return serve() },
+		stop: stop,
+	})
+}
+
+// Go registers a background worker. run returns once ctx is cancelled,
+// which happens after the servers have drained.
+func (m *Manager) Go(name string, run func(ctx context.Context) error) {
+	m.components = append(m.components, component{name: name, run: run})
+}
+
+// OnShutdown registers a hook run as soon as shutdown begins, before the
+// servers stop, e.g. to fail readiness.
+func (m *Manager) OnShutdown(hook func(ctx context.Context)) {
+	m.hooks = append(m.hooks, hook)
+}
+
+// Close registers a resource to release once every server and worker has
+// stopped. Resources are closed in the reverse order of registration.
+func (m *Manager) Close(name string, close func(ctx context.Context) error) {
+	m.closers = append(m.closers, closer{name: name, close: close})
+}
+
+// Run starts every component and blocks until the application has shut
+// down. It returns the failure that stopped the application, if any, along
+// with the errors of the shutdown.
+func (m *Manager) Run(ctx context.Context) error {
+	const op = "lifecycle.Manager.Run"
+
+	signalCtx, stopSignals := signal.NotifyContext(ctx, m.signals...)
+	defer stopSignals()
+
+	// Workers outlive the signal until the servers have drained, since
+	// in-flight requests may still hand them work.
+	workerCtx, stopWorkers := context.WithCancel(ctx)
+	defer stopWorkers()
+
+	exited := make(chan error, len(m.components))
+
+	var wg sync.WaitGroup
+	for _, c := range m.components {
+		wg.Add(1)
+		go func(c component) {
+			defer wg.Done()
+
+			m.logger.Info("starting component", slog.String("component", c.name))
+
+			if err := c.run(workerCtx); err != nil {
+				exited <- fmt.Errorf("%s: %w", c.name, err)
+				return
+			}
+			exited <- nil
+		}(c)
+	}
+
+	var errs []error
+
+	select {
+	case <-signalCtx.Done():
+		m.logger.Info("shutdown signal received")
+	case err := <-exited:
+		if err == nil {
+			err = errors.New("component stopped unexpectedly")
+		}
+		m.logger.Error("component failed", slog.String("error", err.Error()))
+		errs = append(errs, err)
+	}
+
+	// A second signal kills the process.
+	stopSignals()
+
+	for _, hook := range m.hooks {
+		hook(ctx)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	errs = append(errs, m.stopServers(shutdownCtx)...)
+
+	stopWorkers()
+
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		errs = append(errs, fmt.Errorf("components did not stop: %w", shutdownCtx.Err()))
+	}
+
+	for drained := false; !drained; {
+		select {
+		case err := <-exited:
+			if err != nil {
+				errs = append(errs, err)
+			}
+		default:
+			drained = true
+		}
+	}
+
+	for i := len(m.closers) - 1; i >= 0; i-- {
+		c := m.closers[i]
+		if err := c.close(shutdownCtx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// stopServers shuts the servers down concurrently.
+func (m *Manager) stopServers(ctx context.Context) []error {
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+	)
+
+	for _, c := range m.components {
+		if c.stop == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(c component) {
+			defer wg.Done()
+
+			m.logger.Info("stopping component", slog.String("component", c.name))
+
+			if err := c.stop(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+				mu.Unlock()
+			}
+		}(c)
+	}
+
+	wg.Wait()
+
+	return errs
+}
@@ -0,0 +1,208 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"task/common"
+	stream "task/internal/domain/stream/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// events records the order in which the manager drives the components.
+type events struct {
+	mu   sync.Mutex
+	list []string
+}
+
+func (e *events) add(event string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.list = append(e.list, event)
+}
+
+func (e *events) get() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]string(nil), e.list...)
+}
+
+// newTestManager registers a server, a worker, a hook and two resources.
+// The server runs until stopped; fail makes the worker return an error
+// right away.
+func newTestManager(e *events, fail error) *Manager {
+	m := New(slog.New(common.NewDiscardHandler()), time.Second)
+	m.signals = []os.Signal{syscall.SIGUSR1}
+
+	stopped := make(chan struct{})
+	m.Serve("server", func() error {
+		<-stopped
+		return nil
+	}, func(context.Context) error {
+		e.add("stop server")
+		close(stopped)
+		return nil
+	})
+
+	m.Go("worker", func(ctx context.Context) error {
+		if fail != nil {
+			return fail
+		}
+
+		<-ctx.Done()
+		e.add("stop worker")
+		return nil
+	})
+
+	m.OnShutdown(func(context.Context) { e.add("hook") })
+	m.Close("pool", func(context.Context) error {
+		e.add("close pool")
+		return nil
+	})
+	m.Close("mailer", func(context.Context) error {
+		e.add("close mailer")
+		return nil
+	})
+
+	return m
+}
+
+func TestManager_Run(t *testing.T) {
+	e := &events{}
+	m := newTestManager(e, nil)
+
+	// Keeps the signal from killing the test binary before Run has
+	// subscribed to it.
+	ignored := make(chan os.Signal, 1)
+	signal.Notify(ignored, syscall.SIGUSR1)
+	defer signal.Stop(ignored)
+
+	done := make(chan error)
+	go func() { done <- m.Run(context.Background()) }()
+
+	require.NoError(t, shutdown(t, done))
+
+	require.Equal(t, []string{"hook", "stop server", "stop worker", "close mailer", "close pool"}, e.get())
+}
+
+// shutdown sends the signal the test manager stops on until Run returns.
+func shutdown(t *testing.T, done <-chan error) error {
+	t.Helper()
+
+	var err error
+	require.Eventually(t, func() bool {
+		if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+			return false
+		}
+
+		select {
+		case err = <-done:
+			return true
+		case <-time.After(10 * time.Millisecond):
+			return false
+		}
+	}, time.Second, time.Millisecond)
+
+	return err
+}
+
+func TestManager_Run_EndsOpenStreams(t *testing.T) {
+	broadcaster := stream.NewBroadcaster()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	srv := &http.Server{
+		Addr: addr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sub := broadcaster.Subscribe(1)
+			defer broadcaster.Unsubscribe(sub)
+
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+
+			for range sub.Events() {
+			}
+		}),
+	}
+	srv.RegisterOnShutdown(func() { broadcaster.Close() }) //nolint:errcheck
+
+	// A stream left open would hold Shutdown until the timeout.
+	m := New(slog.New(common.NewDiscardHandler()), 5*time.Second)
+	m.signals = []os.Signal{syscall.SIGUSR1}
+	m.HTTP("http server", srv)
+
+	ignored := make(chan os.Signal, 1)
+	signal.Notify(ignored, syscall.SIGUSR1)
+	defer signal.Stop(ignored)
+
+	done := make(chan error)
+	go func() { done <- m.Run(context.Background()) }()
+
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		resp, err = http.Get("http://" + addr)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	defer resp.Body.Close()
+
+	start := time.Now()
+	require.NoError(t, shutdown(t, done))
+	require.Less(t, time.Since(start), time.Second)
+
+	_, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+}
+
+func TestManager_Run_ComponentFails(t *testing.T) {
+	e := &events{}
+	m := newTestManager(e, errors.New("connection refused"))
+
+	err := m.Run(context.Background())
+
+	require.EqualError(t, err, "lifecycle.Manager.Run: worker: connection refused")
+	require.Equal(t, []string{"hook", "stop server", "close mailer", "close pool"}, e.get())
+}
+
+func TestManager_Run_Timeout(t *testing.T) {
+	m := New(slog.New(common.NewDiscardHandler()), 10*time.Millisecond)
+
+	m.Serve("server", func() error {
+		return errors.New("address already in use")
+	}, func(context.Context) error {
+		return nil
+	})
+
+	stuck := make(chan struct{})
+	defer close(stuck)
+	m.Go("worker", func(context.Context) error {
+		<-stuck
+		return nil
+	})
+
+	closed := false
+	m.Close("pool", func(context.Context) error {
+		closed = true
+		return nil
+	})
+
+	err := m.Run(context.Background())
+
+	require.ErrorContains(t, err, "server: address already in use")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.True(t, closed)
+}
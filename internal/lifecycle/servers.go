@@ -0,0 +1,43 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"net"
+	"net/http"
+)
+
+// HTTP registers an http.Server, which drains in-flight requests on
+// shutdown.
+func (m *Manager) HTTP(name string, srv *http.Server) {
+	m.Serve(name, func() error {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+
+		return nil
+	}, srv.Shutdown)
+}
+
+// GRPC registers a gRPC server on lis. Calls still running when ctx
+// expires are cancelled.
+func (m *Manager) GRPC(name string, srv *grpc.Server, lis net.Listener) {
+	m.Serve(name, func() error {
+		return srv.Serve(lis)
+	}, func(ctx context.Context) error {
+		stopped := make(chan struct{})
+		go func() {
+			srv.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			srv.Stop()
+			return ctx.Err()
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const namespace = "task"
+
+// Settlement results used as label values. Transactions are labelled with
+// their kind as well, see entity.Transaction.Kind.
+const (
+	ResultSettled = "settled"
+	ResultFailed  = "failed"
+	ResultError   = "error"
+)
+
+// Registry holds every metric of the service. It is separate from the
+// global registry of the client library so that /metrics shows exactly
+// what is registered here.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	httpDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of HTTP requests by method, route pattern and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	transactions = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "transactions",
+		Name:      "total",
+		Help:      "Transactions by event (created, settled, failed), kind and currency.",
+	}, []string{"event", "kind", "currency"})
+
+	settledVolume = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "transactions",
+		Name:      "settled_amount_total",
+		Help:      "Sum of the amounts of settled transactions by kind and currency.",
+	}, []string{"kind", "currency"})
+
+	settlementDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "transactions",
+		Name:      "settlement_duration_seconds",
+		Help:      "Time to settle a transaction by result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"result"})
+
+	parkedEvents = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "outbox",
+		Name:      "events_parked_total",
+		Help:      "Outbox events parked after running out of attempts, by event type.",
+	}, []string{"event_type"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveRequest records an HTTP request. route is the pattern of the
+// matched route rather than the path, so that ids do not blow up the
+// number of series.
+func ObserveRequest(method string, route string, status int, duration time.Duration) {
+	httpDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+func TransactionCreated(kind string, currency string) {
+	transactions.WithLabelValues("created", kind, currency).Inc()
+}
+
+func TransactionSettled(kind string, currency string, amount float64) {
+	transactions.WithLabelValues(ResultSettled, kind, currency).Inc()
+	settledVolume.WithLabelValues(kind, currency).Add(amount)
+}
+
+func TransactionFailed(kind string, currency string) {
+	transactions.WithLabelValues(ResultFailed, kind, currency).Inc()
+}
+
+// ObserveSettlement records how long a settlement took; result is one of
+// ResultSettled, ResultFailed or ResultError when it did not complete.
+func ObserveSettlement(result string, duration time.Duration) {
+	settlementDuration.WithLabelValues(result).Observe(duration.Seconds())
+}
+
+// OutboxEventParked records an event the relay gave up on. Any increase
+// needs an operator.
+func OutboxEventParked(eventType string) {
+	parkedEvents.WithLabelValues(eventType).Inc()
+}
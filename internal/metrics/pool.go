@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector reads the statistics of a connection pool on every scrape.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquireCount         *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	acquiredConns        *prometheus.Desc
+	canceledAcquireCount *prometheus.Desc
+	constructingConns    *prometheus.Desc
+	emptyAcquireCount    *prometheus.Desc
+	idleConns            *prometheus.Desc
+	maxConns             *prometheus.Desc
+	totalConns           *prometheus.Desc
+	newConnsCount        *prometheus.Desc
+	lifetimeDestroyCount *prometheus.Desc
+	idleDestroyCount     *prometheus.Desc
+}
+
+// RegisterPool exposes the statistics of pool, see pgxpool.Stat.
+func RegisterPool(pool *pgxpool.Pool) error {
+	const op = "metrics.RegisterPool"
+
+	if err := Registry.Register(newPoolCollector(pool)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	desc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+
+	return &poolCollector{
+		pool:                 pool,
+		acquireCount:         desc("acquires_total", "Successful acquires of a connection."),
+		acquireDuration:      desc("acquire_duration_seconds_total", "Time spent on successful acquires."),
+		acquiredConns:        desc("acquired_connections", "Connections in use."),
+		canceledAcquireCount: desc("canceled_acquires_total", "Acquires canceled by their context."),
+		constructingConns:    desc("constructing_connections", "Connections being opened."),
+		emptyAcquireCount:    desc("empty_acquires_total", "Acquires that had to wait for a connection."),
+		idleConns:            desc("idle_connections", "Idle connections."),
+		maxConns:             desc("max_connections", "Maximum size of the pool."),
+		totalConns:           desc("connections", "Connections in the pool."),
+		newConnsCount:        desc("new_connections_total", "Connections opened."),
+		lifetimeDestroyCount: desc("max_lifetime_closed_total", "Connections closed for exceeding their lifetime."),
+		idleDestroyCount:     desc("max_idle_closed_total", "Connections closed for being idle too long."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquireCount, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.constructingConns, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireCount, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.newConnsCount, prometheus.CounterValue, float64(stat.NewConnsCount()))
+	ch <- prometheus.MustNewConstMetric(c.lifetimeDestroyCount, prometheus.CounterValue, float64(stat.MaxLifetimeDestroyCount()))
+	ch <- prometheus.MustNewConstMetric(c.idleDestroyCount, prometheus.CounterValue, float64(stat.MaxIdleDestroyCount()))
+}
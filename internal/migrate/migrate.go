@@ -0,0 +1,348 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// lockID is the key of the advisory lock held while migrating, so that
+// instances starting together apply every migration once.
+const lockID = 7_302_154_911
+
+const createTable = `CREATE TABLE IF NOT EXISTS public.schema_migrations (
+    version BIGINT PRIMARY KEY NOT NULL,
+    name TEXT NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration known to the binary or recorded in the database.
+// AppliedAt is nil for pending migrations; Up and Down are empty for
+// applied ones the binary does not know.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Parse reads the NNNN_name.up.sql and NNNN_name.down.sql pairs in the root
+// of fsys, ordered by version.
+func Parse(fsys fs.FS) ([]Migration, error) {
+	const op = "migrate.Parse"
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%s: %s is not named NNNN_name.up.sql or NNNN_name.down.sql", op, entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", op, entry.Name(), err)
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("%s: version %d is used by both %s and %s", op, version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("%s: %d_%s has no up migration", op, m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+func New(pool *pgxpool.Pool, fsys fs.FS) (*Migrator, error) {
+	const op = "migrate.New"
+
+	migrations, err := Parse(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Migrator{pool: pool, migrations: migrations}, nil
+}
+
+// Latest is the version the binary expects the database at.
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies the pending migrations in order, each in a transaction of its
+// own, and returns those it applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	const op = "migrate.Migrator.Up"
+
+	var applied []Migration
+
+	err := m.locked(ctx, func(conn *pgx.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, migration.Up); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, `INSERT INTO public.schema_migrations (version, name) VALUES ($1, $2)`, migration.Version, migration.Name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("%d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return applied, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return applied, nil
+}
+
+// Down reverts the latest steps applied migrations and returns those it
+// reverted, latest first.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	const op = "migrate.Migrator.Down"
+
+	var reverted []Migration
+
+	err := m.locked(ctx, func(conn *pgx.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := versions[migration.Version]; !ok {
+				continue
+			}
+
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, migration.Down); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, `DELETE FROM public.schema_migrations WHERE version = $1`, migration.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("%d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return reverted, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return reverted, nil
+}
+
+// Status lists every migration of the binary and every one recorded in the
+// database, by version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	const op = "migrate.Migrator.Status"
+
+	var statuses []Status
+
+	err := m.locked(ctx, func(conn *pgx.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			status := Status{Migration: migration}
+			if a, ok := versions[migration.Version]; ok {
+				status.AppliedAt = &a.AppliedAt
+				delete(versions, migration.Version)
+			}
+			statuses = append(statuses, status)
+		}
+
+		for _, a := range versions {
+			a := a
+			statuses = append(statuses, Status{
+				Migration: Migration{Version: a.Version, Name: a.Name},
+				AppliedAt: &a.AppliedAt,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+
+	return statuses, nil
+}
+
+// Check fails unless every migration of the binary has been applied. It is
+// the readiness check of the schema.
+func (m *Migrator) Check(ctx context.Context) error {
+	const op = "migrate.Migrator.Check"
+
+	var version int64
+	err := m.pool.QueryRow(ctx, `SELECT COALESCE(max(version), 0) FROM public.schema_migrations`).Scan(&version)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if version != m.Latest() {
+		return fmt.Errorf("%s: schema at version %d, want %d", op, version, m.Latest())
+	}
+
+	return nil
+}
+
+// locked runs fn on a connection holding the migration lock, once the
+// version table exists.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *pgx.Conn) error) (err error) {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return err
+	}
+	defer func() {
+		_, unlockErr := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID)
+		err = errors.Join(err, unlockErr)
+	}()
+
+	if _, err := conn.Exec(ctx, createTable); err != nil {
+		return err
+	}
+
+	return fn(conn.Conn())
+}
+
+type applied struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time
+}
+
+func appliedVersions(ctx context.Context, conn *pgx.Conn) (map[int64]applied, error) {
+	rows, err := conn.Query(ctx, `SELECT version, name, applied_at FROM public.schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := pgx.CollectRows(rows, pgx.RowToStructByPos[applied])
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[int64]applied, len(list))
+	for _, a := range list {
+		versions[a.Version] = a
+	}
+
+	return versions, nil
+}
+
+// Seed runs every .sql file in the root of fsys, in name order, each in a
+// transaction of its own. Seeds are expected to be safe to run again.
+func Seed(ctx context.Context, pool *pgxpool.Pool, fsys fs.FS) ([]string, error) {
+	const op = "migrate.Seed"
+
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return names[:i], fmt.Errorf("%s: %w", op, err)
+		}
+
+		err = pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+			_, err := tx.Exec(ctx, string(content))
+			return err
+		})
+		if err != nil {
+			return names[:i], fmt.Errorf("%s: %s: %w", op, path.Base(name), err)
+		}
+	}
+
+	return names, nil
+}
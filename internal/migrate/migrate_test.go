@@ -0,0 +1,94 @@
+package migrate
+
+import (
+	"io/fs"
+	"task/migrations"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	file := func(content string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(content)}
+	}
+
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		want    []Migration
+		wantErr string
+	}{
+		{
+			name: "ordered by version",
+			fsys: fstest.MapFS{
+				"0010_sessions.up.sql":   file("CREATE TABLE session ();"),
+				"0010_sessions.down.sql": file("DROP TABLE session;"),
+				"0002_outbox.up.sql":     file("CREATE TABLE outbox ();"),
+			},
+			want: []Migration{
+				{Version: 2, Name: "outbox", Up: "CREATE TABLE outbox ();"},
+				{Version: 10, Name: "sessions", Up: "CREATE TABLE session ();", Down: "DROP TABLE session;"},
+			},
+		},
+		{
+			name: "bad name",
+			fsys: fstest.MapFS{
+				"create_tables.sql": file("CREATE TABLE account ();"),
+			},
+			wantErr: "migrate.Parse: create_tables.sql is not named NNNN_name.up.sql or NNNN_name.down.sql",
+		},
+		{
+			name: "version used twice",
+			fsys: fstest.MapFS{
+				"0001_accounts.up.sql": file("CREATE TABLE account ();"),
+				"0001_outbox.up.sql":   file("CREATE TABLE outbox ();"),
+			},
+			wantErr: "migrate.Parse: version 1 is used by both accounts and outbox",
+		},
+		{
+			name: "down only",
+			fsys: fstest.MapFS{
+				"0001_accounts.down.sql": file("DROP TABLE account;"),
+			},
+			wantErr: "migrate.Parse: 1_accounts has no up migration",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Parse(tc.fsys)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestParse_Embedded(t *testing.T) {
+	t.Parallel()
+
+	schema, err := Parse(migrations.Schema)
+	require.NoError(t, err)
+	require.NotEmpty(t, schema)
+
+	for i, migration := range schema {
+		require.Equal(t, int64(i+1), migration.Version, "versions have no gaps")
+		require.NotEmpty(t, migration.Down, "%d_%s has a down migration", migration.Version, migration.Name)
+		require.NotContains(t, migration.Up, "BEGIN;", "migrations run in a transaction of the migrator")
+	}
+
+	seeds, err := fs.Glob(migrations.Seed, "*.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, seeds)
+}
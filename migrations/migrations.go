@@ -0,0 +1,26 @@
+// Package migrations embeds the versioned schema migrations and the seed
+// data, which is kept apart so that no environment gets sample rows by
+// migrating.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed schema/*.sql seed/*.sql
+var files embed.FS
+
+// Schema holds NNNN_name.up.sql and NNNN_name.down.sql pairs.
+var Schema = sub("schema")
+
+var Seed = sub("seed")
+
+func sub(dir string) fs.FS {
+	fsys, err := fs.Sub(files, dir)
+	if err != nil {
+		panic(err)
+	}
+
+	return fsys
+}
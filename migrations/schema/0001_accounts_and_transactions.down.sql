@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.transaction;
+DROP TABLE IF EXISTS public.account;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS public.account (
+    id SERIAL PRIMARY KEY NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    balance FLOAT NOT NULL DEFAULT 0,
+    password VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL
+);
+
+-- Transactions with to_account = 0 are deposits.
+CREATE TABLE IF NOT EXISTS public.transaction (
+    id SERIAL PRIMARY KEY NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    account_id INT,
+    amount INT NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL,
+    to_account INT
+);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS public.outbox (
+    id BIGSERIAL PRIMARY KEY NOT NULL,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id BIGINT NOT NULL,
+    account_id BIGINT NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    published_at TIMESTAMPTZ,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS outbox_unpublished_idx ON public.outbox (id) WHERE published_at IS NULL;
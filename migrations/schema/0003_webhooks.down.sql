@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS public.webhook_delivery_attempt;
+DROP TABLE IF EXISTS public.webhook_delivery;
+DROP TABLE IF EXISTS public.webhook_subscription;
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS public.webhook_subscription (
+    id BIGSERIAL PRIMARY KEY NOT NULL,
+    account_id INT NOT NULL REFERENCES public.account (id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret VARCHAR(100) NOT NULL,
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS public.webhook_delivery (
+    id BIGSERIAL PRIMARY KEY NOT NULL,
+    subscription_id BIGINT NOT NULL REFERENCES public.webhook_subscription (id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_status_code INT,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    delivered_at TIMESTAMPTZ,
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_due_idx ON public.webhook_delivery (next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS public.webhook_delivery_attempt (
+    id BIGSERIAL PRIMARY KEY NOT NULL,
+    delivery_id BIGINT NOT NULL REFERENCES public.webhook_delivery (id) ON DELETE CASCADE,
+    status_code INT,
+    error TEXT,
+    duration_ms BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
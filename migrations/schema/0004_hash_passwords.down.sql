@@ -0,0 +1 @@
+-- Hashed passwords cannot be turned back into plain text; the hashes stay.
@@ -0,0 +1,8 @@
+-- Hashes passwords that are still stored in plain text. pgcrypto produces
+-- bcrypt hashes, which the service accepts and upgrades to argon2id on the
+-- next successful login.
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+UPDATE public.account
+SET password = crypt(password, gen_salt('bf', 10))
+WHERE password !~ '^\$(argon2id|2[aby])\$';
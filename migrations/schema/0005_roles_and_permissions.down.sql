@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS public.account_role;
+DROP TABLE IF EXISTS public.role_permission;
+DROP TABLE IF EXISTS public.role;
+DROP TABLE IF EXISTS public.permission;
@@ -0,0 +1,63 @@
+CREATE TABLE IF NOT EXISTS public.permission (
+    name VARCHAR(50) PRIMARY KEY NOT NULL,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS public.role (
+    name VARCHAR(50) PRIMARY KEY NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    builtin BOOLEAN NOT NULL DEFAULT false
+);
+
+CREATE TABLE IF NOT EXISTS public.role_permission (
+    role VARCHAR(50) NOT NULL REFERENCES public.role (name) ON DELETE CASCADE,
+    permission VARCHAR(50) NOT NULL REFERENCES public.permission (name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+-- Accounts without a row here act as customers.
+CREATE TABLE IF NOT EXISTS public.account_role (
+    account_id INT NOT NULL REFERENCES public.account (id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL REFERENCES public.role (name) ON DELETE CASCADE,
+    PRIMARY KEY (account_id, role)
+);
+
+INSERT INTO permission (name, description) VALUES
+    ('accounts:read', 'Read account details, balances and streams'),
+    ('accounts:write', 'Override account balance and currency'),
+    ('accounts:delete', 'Delete accounts'),
+    ('accounts:any', 'Act on accounts of other customers'),
+    ('transactions:read', 'Read transactions and frozen balances'),
+    ('transactions:write', 'Create deposits and withdrawals'),
+    ('transactions:settle', 'Settle pending transactions'),
+    ('transactions:delete', 'Delete transactions'),
+    ('webhooks:read', 'Read webhooks and their deliveries'),
+    ('webhooks:write', 'Register, delete and redeliver webhooks'),
+    ('roles:manage', 'Manage roles, permissions and role assignments'),
+    ('apikeys:manage', 'Create, rotate and revoke API keys')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role (name, description, builtin) VALUES
+    ('customer', 'Acts on its own account only', true),
+    ('operator', 'Settles and deletes transactions of any account', true),
+    ('admin', 'Everything, including role management', true)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permission (role, permission) VALUES
+    ('customer', 'accounts:read'),
+    ('customer', 'transactions:read'),
+    ('customer', 'transactions:write'),
+    ('customer', 'webhooks:read'),
+    ('customer', 'webhooks:write'),
+    ('operator', 'accounts:read'),
+    ('operator', 'accounts:write'),
+    ('operator', 'accounts:any'),
+    ('operator', 'transactions:read'),
+    ('operator', 'transactions:settle'),
+    ('operator', 'transactions:delete'),
+    ('operator', 'webhooks:read')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permission (role, permission)
+SELECT 'admin', name FROM permission
+ON CONFLICT DO NOTHING;
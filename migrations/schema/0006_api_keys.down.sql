@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.request_nonce;
+DROP TABLE IF EXISTS public.api_key;
@@ -0,0 +1,25 @@
+-- API keys act with their scopes (permission names) instead of roles.
+CREATE TABLE IF NOT EXISTS public.api_key (
+    id BIGSERIAL PRIMARY KEY NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(32) NOT NULL UNIQUE,
+    hash VARCHAR(64) NOT NULL,
+    account_id INT REFERENCES public.account (id) ON DELETE CASCADE,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    allowed_ips TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMPTZ,
+    require_signature BOOLEAN NOT NULL DEFAULT false,
+    signing_secret VARCHAR(64) NOT NULL DEFAULT '',
+    revoked_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    last_used_ip TEXT,
+    replaced_by BIGINT REFERENCES public.api_key (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS public.request_nonce (
+    api_key_id BIGINT NOT NULL REFERENCES public.api_key (id) ON DELETE CASCADE,
+    nonce VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (api_key_id, nonce)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.account_token;
+ALTER TABLE public.account DROP COLUMN IF EXISTS email_verified_at;
@@ -0,0 +1,16 @@
+ALTER TABLE public.account ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
+
+-- Single-use tokens sent by email: address verification and password
+-- reset. Only SHA-256 hashes are stored.
+CREATE TABLE IF NOT EXISTS public.account_token (
+    id BIGSERIAL PRIMARY KEY NOT NULL,
+    account_id INT NOT NULL REFERENCES public.account (id) ON DELETE CASCADE,
+    purpose VARCHAR(20) NOT NULL,
+    hash VARCHAR(64) NOT NULL UNIQUE,
+    email VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS account_token_account_idx ON public.account_token (account_id, purpose) WHERE used_at IS NULL;
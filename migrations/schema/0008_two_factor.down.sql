@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.account_recovery_code;
+DROP TABLE IF EXISTS public.account_totp;
@@ -0,0 +1,16 @@
+-- TOTP authenticators and their recovery codes (SHA-256 hashes). The TOTP
+-- secret has to be kept to verify codes.
+CREATE TABLE IF NOT EXISTS public.account_totp (
+    account_id INT PRIMARY KEY NOT NULL REFERENCES public.account (id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    confirmed_at TIMESTAMPTZ,
+    last_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS public.account_recovery_code (
+    account_id INT NOT NULL REFERENCES public.account (id) ON DELETE CASCADE,
+    hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    PRIMARY KEY (account_id, hash)
+);
@@ -0,0 +1,15 @@
+-- Login sessions. refresh_id is the id (jti) of the latest refresh token;
+-- presenting an older one revokes the session.
+CREATE TABLE IF NOT EXISTS public.session (
+    id BIGSERIAL PRIMARY KEY NOT NULL,
+    account_id INT NOT NULL REFERENCES public.account (id) ON DELETE CASCADE,
+    refresh_id VARCHAR(64) NOT NULL,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_used_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS session_account_idx ON public.session (account_id) WHERE revoked_at IS NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE public.transaction DROP COLUMN IF EXISTS created_at;
+
+DROP TABLE IF EXISTS public.reconciliation_baseline;
@@ -0,0 +1,18 @@
+-- Reconciliation vouches only for what happens after this migration.
+-- Balances are taken as they are now, together with the last event they
+-- already account for, and transactions without a creation time predate
+-- the check.
+CREATE TABLE IF NOT EXISTS public.reconciliation_baseline (
+    account_id BIGINT PRIMARY KEY NOT NULL,
+    balance FLOAT NOT NULL,
+    outbox_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO public.reconciliation_baseline (account_id, balance, outbox_id)
+SELECT id, balance, (SELECT COALESCE(max(id), 0) FROM public.outbox)
+FROM public.account
+ON CONFLICT (account_id) DO NOTHING;
+
+ALTER TABLE public.transaction ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ;
+ALTER TABLE public.transaction ALTER COLUMN created_at SET DEFAULT now();
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS outbox_notify ON public.outbox;
+
+DROP FUNCTION IF EXISTS public.outbox_notify();
@@ -0,0 +1,15 @@
+-- Every instance serves event streams from its own broadcaster, so every
+-- instance hears about new events, not only the one relaying the outbox.
+-- Notifications are sent on commit, in commit order.
+CREATE OR REPLACE FUNCTION public.outbox_notify() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('outbox', NEW.id::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS outbox_notify ON public.outbox;
+
+CREATE TRIGGER outbox_notify
+    AFTER INSERT ON public.outbox
+    FOR EACH ROW EXECUTE FUNCTION public.outbox_notify();
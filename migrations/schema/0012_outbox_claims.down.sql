@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS public.outbox_unpublished_account_idx;
+
+ALTER TABLE public.outbox DROP COLUMN IF EXISTS claimed_until;
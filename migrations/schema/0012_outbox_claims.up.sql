@@ -0,0 +1,6 @@
+-- Relays claim a batch of events for a lease rather than holding a
+-- transaction open while they publish it. An account's events are claimed
+-- by one relay at a time, which keeps them in order.
+ALTER TABLE public.outbox ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS outbox_unpublished_account_idx ON public.outbox (account_id, id) WHERE published_at IS NULL;
@@ -0,0 +1,2 @@
+-- Fractional amounts are rounded to whole units.
+ALTER TABLE public.transaction ALTER COLUMN amount TYPE INT USING round(amount)::int;
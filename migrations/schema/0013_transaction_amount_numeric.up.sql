@@ -0,0 +1,3 @@
+-- Amounts are decimals like the balances they move; an integer column
+-- rounded them, or refused them, at the database.
+ALTER TABLE public.transaction ALTER COLUMN amount TYPE NUMERIC USING amount::numeric;
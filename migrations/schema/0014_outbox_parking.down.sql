@@ -0,0 +1,2 @@
+ALTER TABLE public.outbox DROP COLUMN IF EXISTS parked_at;
+ALTER TABLE public.outbox DROP COLUMN IF EXISTS next_attempt_at;
@@ -0,0 +1,5 @@
+-- A failed event is retried with backoff, holding up the later events of
+-- its account, until it runs out of attempts and is parked. Parked events
+-- are no longer relayed; clearing parked_at puts one back in line.
+ALTER TABLE public.outbox ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ;
+ALTER TABLE public.outbox ADD COLUMN IF NOT EXISTS parked_at TIMESTAMPTZ;
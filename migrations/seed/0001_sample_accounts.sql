@@ -0,0 +1,19 @@
+-- Sample accounts and transactions for local development. Never applied by
+-- migrate up; run `task migrate seed` on a migrated database. Safe to run
+-- more than once.
+INSERT INTO account (id, currency, balance, password, email) VALUES
+    (1, 'USD', 100, crypt('qwerty1', gen_salt('bf', 10)), '1@ya.ru'),
+    (2, 'EUR', 200, crypt('qwerty2', gen_salt('bf', 10)), '2@ya.ru'),
+    (3, 'RUB', 1000, crypt('qwerty3', gen_salt('bf', 10)), '3@ya.ru')
+ON CONFLICT DO NOTHING;
+
+-- Transactions with to_account = 0 are deposits.
+INSERT INTO transaction (id, status, account_id, amount, currency, to_account) VALUES
+    (1, 'created', 1, 10, 'RUB', 0),
+    (2, 'created', 2, 10, 'USD', 0),
+    (3, 'created', 3, 5, 'USD', 1)
+ON CONFLICT DO NOTHING;
+
+-- The rows above take their ids explicitly, so move the sequences past them.
+SELECT setval(pg_get_serial_sequence('account', 'id'), (SELECT max(id) FROM account));
+SELECT setval(pg_get_serial_sequence('transaction', 'id'), (SELECT max(id) FROM transaction));
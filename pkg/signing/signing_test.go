@@ -0,0 +1,48 @@
+package signing
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransport(t *testing.T) {
+	now := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+	payload := `{"account_id":7,"amount":100}`
+
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		nonce := r.Header.Get(HeaderNonce)
+
+		require.Equal(t, payload, string(body))
+		require.Equal(t, "tk_key", r.Header.Get(HeaderAPIKey))
+		require.Equal(t, now.Unix(), timestamp)
+		require.Len(t, nonce, 32)
+
+		expected := Sign("sig_secret", r.Method, r.RequestURI, timestamp, nonce, body)
+		require.True(t, Equal(r.Header.Get(HeaderSignature), expected))
+		require.False(t, Equal(r.Header.Get(HeaderSignature), Sign("sig_secret", r.Method, r.RequestURI, timestamp, nonce, []byte("{}"))))
+		require.False(t, Equal(expected, expected))
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer stub.Close()
+
+	client := &http.Client{Transport: &Transport{
+		Signer: &Signer{APIKey: "tk_key", Secret: "sig_secret", Now: func() time.Time { return now }},
+		Base:   stub.Client().Transport,
+	}}
+
+	resp, err := client.Post(stub.URL+"/transaction/deposit?dry_run=1", "application/json", strings.NewReader(payload))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+}